@@ -12,8 +12,11 @@ import (
 	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
 	"github.com/ThreeDotsLabs/watermill/message/router/plugin"
 	config "github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/internal/moderation"
 	"github.com/gearpoint/filepoint/internal/sender_handlers"
-	"github.com/gearpoint/filepoint/pkg/aws_repository"
+	"github.com/gearpoint/filepoint/internal/setup"
+	"github.com/gearpoint/filepoint/internal/tenants"
+	"github.com/gearpoint/filepoint/pkg/labeler"
 	"github.com/gearpoint/filepoint/pkg/logger"
 	"github.com/gearpoint/filepoint/pkg/redis"
 	"github.com/gearpoint/filepoint/pkg/utils"
@@ -77,17 +80,15 @@ func getCfg(configFile string) *config.Config {
 func setupRouter(cfg *config.Config) {
 	context := context.Background()
 
-	awsRepository, err := aws_repository.NewAWSRepository(&cfg.AWSConfig, context)
-	if err != nil {
-		logger.Fatal("cannot initialize AWS repository",
-			zap.Error(err),
-		)
-	}
+	awsRepository := setup.AWSRepository(cfg)
 
 	redisRepository := redis.NewRedisRepository(&cfg.RedisConfig)
 	defer redisRepository.Client.Close()
 	logger.Info("Redis connected")
 
+	blobStore := setup.BlobStore(cfg, awsRepository)
+	keyWrapper := setup.KeyWrapper(cfg)
+
 	publisher, subscriber := setUpPubSub(cfg)
 
 	router, err := watermill.NewRouter()
@@ -114,7 +115,39 @@ func setupRouter(cfg *config.Config) {
 		switch routeName {
 		case config.Upload:
 			routeCfg := cfg.Routes[routeName]
-			upload_sender := sender_handlers.NewUploadHandler(awsRepository, redisRepository, routeCfg)
+			metadataStore := setup.MetadataStore(cfg, routeCfg, awsRepository)
+			tenantsRegistry := setup.Tenants(cfg, routeCfg, awsRepository, blobStore, metadataStore)
+			moderationPolicy := moderation.NewPolicy(&cfg.ModerationConfig)
+
+			newUploadHandler := func(tenant *tenants.Tenant) *sender_handlers.UploadHandler {
+				return sender_handlers.NewUploadHandler(&sender_handlers.UploadHandlerConfig{
+					RouteConfig:       tenant.RouteConfig(routeCfg),
+					AWSRepository:     tenant.AWSRepository,
+					BlobStore:         tenant.BlobStore,
+					MetadataStore:     tenant.MetadataStore,
+					RedisRepository:   tenant.Redis(redisRepository),
+					Labeler:           setUpLabeler(cfg, tenant),
+					ModerationPolicy:  moderationPolicy,
+					KeyWrapper:        keyWrapper,
+					AllowedStrategies: tenant.AllowedStrategies,
+					MaxFileSize:       tenant.MaxFileSize,
+				})
+			}
+
+			upload_sender := sender_handlers.NewTenantUploadHandlers(newUploadHandler(tenantsRegistry.Default()), publisher)
+			for _, tenant := range tenantsRegistry.Tenants() {
+				if !tenant.IsDefault() {
+					upload_sender.Add(tenant.Id, newUploadHandler(tenant))
+				}
+
+				trashPurger := sender_handlers.NewTrashPurger(&sender_handlers.TrashPurgerConfig{
+					TrashConfig:   cfg.TrashConfig,
+					BlobStore:     tenant.BlobStore,
+					MetadataStore: tenant.MetadataStore,
+				})
+				go trashPurger.Run(context)
+			}
+
 			uploadHandler := router.AddHandler(
 				string(routeName),
 				routeConfig.Topic,
@@ -177,3 +210,24 @@ func setUpPubSub(cfg *config.Config) (message.Publisher, message.Subscriber) {
 
 	return publisher, subscriber
 }
+
+// setUpLabeler returns the tenant labeler configured in the LabelerConfig.
+// The local labeler downloads the images from the tenant blob store.
+func setUpLabeler(cfg *config.Config, tenant *tenants.Tenant) labeler.Labeler {
+	labelerCfg := cfg.LabelerConfig
+
+	switch labeler.Backend(labelerCfg.Backend) {
+	case labeler.Rekognition:
+		tenant.AWSRepository.SetLabelerConfig(labelerCfg.MinConfidence, labelerCfg.MaxLabels)
+		if tenant.KeyPrefix != "" {
+			return labeler.NewPrefixedLabeler(tenant.AWSRepository, tenant.KeyPrefix)
+		}
+		return tenant.AWSRepository
+	case labeler.Local:
+		return labeler.NewLocalLabeler(tenant.BlobStore.DownloadFile, labelerCfg.MinConfidence, labelerCfg.MaxLabels)
+	default:
+		log.Fatal("error initializing the labeler - unrecognized backend")
+	}
+
+	return nil
+}
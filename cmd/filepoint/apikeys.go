@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	config "github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/internal/apikeys"
+	"github.com/gearpoint/filepoint/internal/tenants"
+	"github.com/gearpoint/filepoint/internal/views"
+)
+
+// apiKeysCommand is the API keys management subcommand.
+const apiKeysCommand = "apikeys"
+
+const apiKeysUsage = `usage: filepoint [-config file] apikeys <command> [flags]
+
+commands:
+  create -name <name> -scopes <upload,read,delete,admin> [-tenant <id>] [-expires <duration>]
+  list [-tenant <id>]
+  rotate -id <keyId> [-grace <duration>]
+  revoke -id <keyId>`
+
+// runApiKeysCommand creates, lists, rotates and revokes the API keys.
+// The created keys are only printed once.
+func runApiKeysCommand(cfg *config.Config, manager *apikeys.Manager, args []string) error {
+	if len(args) == 0 {
+		return errors.New(apiKeysUsage)
+	}
+
+	flags := flag.NewFlagSet(apiKeysCommand+" "+args[0], flag.ExitOnError)
+	name := flags.String("name", "", "key name")
+	scopes := flags.String("scopes", "", "comma separated key scopes")
+	tenantId := flags.String("tenant", "", "key tenant, the default tenant when empty")
+	expires := flags.Duration("expires", 0, "key lifetime, the key never expires when zero")
+	keyId := flags.String("id", "", "key id")
+	grace := flags.Duration("grace", 0, "time the rotated key keeps working")
+	flags.Parse(args[1:])
+
+	switch args[0] {
+	case "create":
+		if *name == "" || *scopes == "" {
+			return errors.New("the -name and -scopes flags are required")
+		}
+
+		keyScopes := strings.Split(*scopes, ",")
+		for _, scope := range keyScopes {
+			if !apikeys.IsScope(scope) {
+				return fmt.Errorf("unrecognized scope %s", scope)
+			}
+		}
+
+		if *tenantId == "" {
+			*tenantId = tenants.DefaultTenant
+		}
+		if _, ok := cfg.Tenants[*tenantId]; !ok && *tenantId != tenants.DefaultTenant {
+			return fmt.Errorf("unrecognized tenant %s", *tenantId)
+		}
+
+		var expiresOn time.Time
+		if *expires > 0 {
+			expiresOn = time.Now().Add(*expires)
+		}
+
+		apiKey, key, err := manager.Create(*name, *tenantId, keyScopes, expiresOn)
+		if err != nil {
+			return err
+		}
+		printApiKeys(apiKey)
+		fmt.Printf("\nkey: %s\n", key)
+	case "list":
+		apiKeys, err := manager.List(*tenantId)
+		if err != nil {
+			return err
+		}
+		printApiKeys(apiKeys...)
+	case "rotate":
+		apiKey, key, err := manager.Rotate(*keyId, *grace)
+		if err != nil {
+			return err
+		}
+		printApiKeys(apiKey)
+		fmt.Printf("\nkey: %s\n", key)
+	case "revoke":
+		apiKey, err := manager.Revoke(*keyId)
+		if err != nil {
+			return err
+		}
+		printApiKeys(apiKey)
+	default:
+		return errors.New(apiKeysUsage)
+	}
+
+	return nil
+}
+
+// printApiKeys prints the keys table.
+func printApiKeys(apiKeys ...*views.DynamoDBApiKeySchema) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tNAME\tTENANT\tSCOPES\tEXPIRES\tLAST USED\tACTIVE")
+
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	for _, apiKey := range apiKeys {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%t\n",
+			apiKey.KeyId,
+			apiKey.Name,
+			apiKey.TenantId,
+			strings.Join(apiKey.Scopes, ","),
+			formatTime(apiKey.ExpiresOn),
+			formatTime(apiKey.LastUsedOn),
+			apiKey.IsActive(time.Now()),
+		)
+	}
+
+	writer.Flush()
+}
@@ -2,16 +2,18 @@
 package main
 
 import (
-	"context"
 	"flag"
 	"log"
 	"os"
 
 	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/gearpoint/filepoint/api"
 	config "github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/internal/apikeys"
 	"github.com/gearpoint/filepoint/internal/server"
-	"github.com/gearpoint/filepoint/pkg/aws_repository"
+	"github.com/gearpoint/filepoint/internal/setup"
+	http_utils "github.com/gearpoint/filepoint/pkg/http"
 	"github.com/gearpoint/filepoint/pkg/logger"
 	"github.com/gearpoint/filepoint/pkg/redis"
 	"github.com/gearpoint/filepoint/pkg/utils"
@@ -31,6 +33,14 @@ var (
 // @contact.url https://github.com/luabagg
 // @contact.email luanbaggio0@gmail.com
 // @BasePath /v1
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT, as "Bearer {token}".
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name Authorization
+// @description Tenant or managed API key, as "ApiKey {key}".
 func main() {
 	godotenv.Load()
 
@@ -44,16 +54,24 @@ func main() {
 
 	cfg := getCfg(configFile)
 
+	if flag.Arg(0) == apiKeysCommand {
+		awsRepository := setup.AWSRepository(cfg)
+		metadataStore := setup.MetadataStore(cfg, cfg.Routes[config.Upload], awsRepository)
+		if err := runApiKeysCommand(cfg, apikeys.NewManager(metadataStore), flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	publisher, partitionKey := setUpPublisher(cfg)
 	defer publisher.Close()
 
-	awsRepository, err := aws_repository.NewAWSRepository(&cfg.AWSConfig, context.Background())
-	if err != nil {
-		logger.Fatal("cannot initialize AWS repository",
-			zap.Error(err),
-		)
-	}
-	logger.Info("AWS connected")
+	awsRepository := setup.AWSRepository(cfg)
+	blobStore := setup.BlobStore(cfg, awsRepository)
+	metadataStore := setup.MetadataStore(cfg, cfg.Routes[config.Upload], awsRepository)
+	tenantsRegistry := setup.Tenants(cfg, cfg.Routes[config.Upload], awsRepository, blobStore, metadataStore)
+	keyWrapper := setup.KeyWrapper(cfg)
+	keyFunc := setUpAuth(cfg)
 
 	redisRepository := redis.NewRedisRepository(&cfg.RedisConfig)
 	defer redisRepository.Client.Close()
@@ -77,7 +95,15 @@ func main() {
 		PartitionKey:    partitionKey,
 		Publisher:       publisher,
 		AWSRepository:   awsRepository,
+		BlobStore:       blobStore,
+		MetadataStore:   metadataStore,
 		RedisRepository: redisRepository,
+		KeyWrapper:      keyWrapper,
+		SigningConfig:   cfg.SigningConfig,
+		Tenants:         tenantsRegistry,
+		AuthConfig:      cfg.AuthConfig,
+		KeyFunc:         keyFunc,
+		ApiKeys:         apikeys.NewManager(metadataStore),
 	})
 	if err = s.Run(); err != nil {
 		logger.Fatal("error starting server")
@@ -135,3 +161,40 @@ func setUpPublisher(cfg *config.Config) (message.Publisher, string) {
 
 	return publisher, partitionKey
 }
+
+// setUpAuth returns the JWT key callback configured in the AuthConfig.
+// It returns nil without a backend, which disables the authentication.
+func setUpAuth(cfg *config.Config) jwt.Keyfunc {
+	authCfg := cfg.AuthConfig
+
+	switch authCfg.Backend {
+	case "hs256":
+		if authCfg.Secret == "" {
+			log.Fatal("error initializing the authentication - the hs256 backend requires a secret")
+		}
+		return http_utils.NewHS256KeyFunc([]byte(authCfg.Secret))
+	case "rs256":
+		var jwks *http_utils.JWKS
+		var err error
+		switch {
+		case authCfg.JWKSFile != "":
+			jwks, err = http_utils.NewJWKSFromFile(authCfg.JWKSFile)
+		case authCfg.JWKSURL != "":
+			jwks, err = http_utils.NewJWKSFromURL(authCfg.JWKSURL, authCfg.JWKSRefreshInterval)
+		default:
+			log.Fatal("error initializing the authentication - the rs256 backend requires a JWKSFile or JWKSURL")
+		}
+		if err != nil {
+			logger.Fatal("error initializing the JWKS",
+				zap.Error(err),
+			)
+		}
+		return jwks.KeyFunc
+	case "":
+		logger.Info("authentication disabled")
+	default:
+		log.Fatal("error initializing the authentication - unrecognized backend")
+	}
+
+	return nil
+}
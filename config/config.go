@@ -16,11 +16,21 @@ const (
 
 // Config is the app main config struct.
 type Config struct {
-	Server          ServerConfig
-	Routes          Routes
-	AWSConfig       AWSConfig
-	StreamingConfig StreamingConfig
-	RedisConfig     RedisConfig
+	Server           ServerConfig
+	Routes           Routes
+	AWSConfig        AWSConfig
+	StorageConfig    StorageConfig
+	MetadataConfig   MetadataConfig
+	StreamingConfig  StreamingConfig
+	RedisConfig      RedisConfig
+	LabelerConfig    LabelerConfig
+	ModerationConfig ModerationConfig
+	TrashConfig      TrashConfig
+	EncryptionConfig EncryptionConfig
+	SigningConfig    SigningConfig
+	Tenants          Tenants
+	AuthConfig       AuthConfig
+	QuotaConfig      QuotaConfig
 }
 
 // ServerConfig is the server configuration struct.
@@ -35,11 +45,25 @@ type ServerConfig struct {
 
 // Route config is the routes configuration.
 type RouteConfig struct {
-	TableName   string
-	Topic       string
-	PoisonTopic string
-	WebhookURL  string
-	MaxRetries  int
+	TableName string
+	// IndexTableName is the table of the labels inverted index, used in search.
+	IndexTableName string
+	// FolderTableName is the table of the user folders.
+	FolderTableName string
+	// ApiKeyTableName is the table of the managed API keys.
+	ApiKeyTableName string
+	// ShareTableName is the table of the share links.
+	ShareTableName string
+	// UsageTableName is the table of the users storage usage counters.
+	UsageTableName string
+	Topic          string
+	PoisonTopic    string
+	WebhookURL     string
+	MaxRetries     int
+	// TaggedMetadataKeys are the custom metadata keys mirrored to the objects tags (max 8).
+	TaggedMetadataKeys []string
+	// Encrypted enables the envelope encryption of the route files, with the EncryptionConfig master key.
+	Encrypted bool
 }
 
 // Routes defines the available routes.
@@ -58,6 +82,30 @@ type AWSConfig struct {
 	RekognitionRole    string
 }
 
+// StorageConfig is the blob storage configuration.
+type StorageConfig struct {
+	// Backend defines the blob store implementation. Possible values are "s3" and "filesystem".
+	Backend string
+	// RootDir is the filesystem store root directory.
+	RootDir string
+	// Signer defines the URL signer implementation. Possible values are "cloudfront", "s3" and "hmac".
+	// If empty, the s3 backend uses "cloudfront" and the filesystem backend uses "hmac".
+	Signer string
+	// BaseURL is the download handler public URL, used in the HMAC signed URLs.
+	BaseURL string
+	// SigningKey is the HMAC signed URLs key.
+	SigningKey string
+}
+
+// MetadataConfig is the file records storage configuration.
+type MetadataConfig struct {
+	// Backend defines the metadata store implementation. Possible values are "dynamodb", "postgres" and "memory".
+	// The memory backend is not shared between the API and the webhooks sender, so use it only in tests.
+	Backend string
+	// PostgresDSN is the PostgreSQL connection string.
+	PostgresDSN string
+}
+
 // StreamingConfig contains the app streaming services configuration.
 type StreamingConfig struct {
 	MessagesPerSecond int64
@@ -81,6 +129,140 @@ type RedisConfig struct {
 	Password     string
 }
 
+// LabelerConfig is the file labelling configuration.
+type LabelerConfig struct {
+	// Backend defines the labeler implementation. Possible values are "rekognition" and "local".
+	Backend       string
+	MinConfidence float32
+	MaxLabels     int32
+}
+
+// ModerationConfig is the content moderation configuration.
+type ModerationConfig struct {
+	// Thresholds maps the moderation categories to the min confidence that quarantines a file.
+	Thresholds map[string]float32
+}
+
+// TrashConfig is the deleted files configuration.
+type TrashConfig struct {
+	// RetentionPeriod is the time the files stay in the trash before being purged.
+	RetentionPeriod time.Duration
+	// PurgeInterval is the interval of the webhooks sender purge job.
+	PurgeInterval time.Duration
+}
+
+// EncryptionConfig is the files envelope encryption configuration.
+type EncryptionConfig struct {
+	// Backend defines the master key implementation. The only possible value is "local".
+	// It's required by the encrypted routes.
+	Backend string
+	// KeyFile is the local master key file, with the base64 encoded 32 bytes key.
+	KeyFile string
+}
+
+// SigningConfig is the signed URLs configuration.
+type SigningConfig struct {
+	// MinExpiration and MaxExpiration bound the signed URLs expiration requested by the clients.
+	MinExpiration time.Duration
+	MaxExpiration time.Duration
+	// CookieDomain, CookiePath and CookieSecure are the folder signed cookies attributes.
+	// The domain must include the Cloudfront distribution domain (i.e. a shared parent domain).
+	CookieDomain string
+	CookiePath   string
+	CookieSecure bool
+	// CookieExpiration is the folder signed cookies lifetime.
+	CookieExpiration time.Duration
+	// AllowUncheckedCookies enables the cookies of the signers that don't check the objects tags (i.e. Cloudfront).
+	// The quarantined and trashed objects must be denied in the bucket policy.
+	AllowUncheckedCookies bool
+}
+
+// QuotaConfig is the storage quotas configuration, checked before the uploads are accepted.
+// The zero limits don't limit the usage.
+type QuotaConfig struct {
+	// UserMaxBytes and UserMaxFiles limit the processed files of each user.
+	UserMaxBytes int64
+	UserMaxFiles int64
+	// TenantMaxBytes and TenantMaxFiles limit the processed files of all the tenant users.
+	TenantMaxBytes int64
+	TenantMaxFiles int64
+}
+
+// WithDefaults returns the quotas with the zero limits set from the defaults.
+func (q QuotaConfig) WithDefaults(defaults QuotaConfig) QuotaConfig {
+	if q.UserMaxBytes == 0 {
+		q.UserMaxBytes = defaults.UserMaxBytes
+	}
+	if q.UserMaxFiles == 0 {
+		q.UserMaxFiles = defaults.UserMaxFiles
+	}
+	if q.TenantMaxBytes == 0 {
+		q.TenantMaxBytes = defaults.TenantMaxBytes
+	}
+	if q.TenantMaxFiles == 0 {
+		q.TenantMaxFiles = defaults.TenantMaxFiles
+	}
+
+	return q
+}
+
+// TenantConfig is a tenant configuration. The empty fields use the main configuration.
+type TenantConfig struct {
+	// ApiKeys are the tenant keys, sent in the "Authorization: ApiKey <key>" header.
+	ApiKeys []string
+	// Bucket and CloudfrontDist are the tenant S3 bucket and distribution.
+	Bucket         string
+	CloudfrontDist string
+	// TableName, IndexTableName and FolderTableName are the tenant DynamoDB tables.
+	TableName       string
+	IndexTableName  string
+	FolderTableName string
+	// UsageTableName is the tenant usage counters table. The tenant usage isn't counted without it.
+	UsageTableName string
+	// PostgresDSN is the tenant PostgreSQL database.
+	PostgresDSN string
+	// KeyPrefix is the prefix of the tenant objects keys, required to share a bucket.
+	KeyPrefix  string
+	WebhookURL string
+	// AllowedStrategies are the accepted upload strategies ("image", "video", "file" and "archive").
+	// All the strategies are accepted when empty.
+	AllowedStrategies []string
+	// MaxFileSize is the max uploaded file size, in bytes. Zero uses the strategies limits.
+	MaxFileSize int64
+	// QuotaConfig is the tenant storage quotas. The zero limits use the main QuotaConfig.
+	QuotaConfig QuotaConfig
+}
+
+// Tenants maps the tenants ids to their configuration. The ids are lowercase.
+type Tenants map[string]TenantConfig
+
+// AuthConfig is the JWT authentication configuration.
+type AuthConfig struct {
+	// Backend defines the tokens algorithm. Possible values are "hs256" and "rs256".
+	// The authentication is disabled when empty.
+	Backend string
+	// Secret is the HS256 shared secret.
+	Secret string
+	// JWKSFile or JWKSURL are the RS256 public keys. The URL keys are refreshed every JWKSRefreshInterval.
+	JWKSFile            string
+	JWKSURL             string
+	JWKSRefreshInterval time.Duration
+	// Issuer and Audience are checked when set.
+	Issuer   string
+	Audience string
+	// AdminScope is the scope that allows to access the files of any user.
+	AdminScope string
+	// TenantClaim is the claim with the tenant id, used when the request has no API key.
+	TenantClaim string
+}
+
+// UsesAWS checks if any of the configured backends is an AWS service.
+func (c *Config) UsesAWS() bool {
+	return c.StorageConfig.Backend == "s3" ||
+		c.MetadataConfig.Backend == "dynamodb" ||
+		c.LabelerConfig.Backend == "rekognition"
+}
+
 // LoadConfig loads file from given path.
 func LoadConfig(path string) (*viper.Viper, error) {
 	v := viper.New()
@@ -90,6 +272,24 @@ func LoadConfig(path string) (*viper.Viper, error) {
 
 	v.SetDefault("Server.Addr", utils.GetEnv(utils.AddrKey))
 	v.SetDefault("AWSConfig.CloudfrontKeyId", utils.GetEnv(utils.CloudfrontKeyId))
+	v.SetDefault("StorageConfig.Backend", "s3")
+	v.SetDefault("StorageConfig.RootDir", "./data")
+	v.SetDefault("StorageConfig.SigningKey", utils.GetEnv(utils.StorageSigningKey))
+	v.SetDefault("MetadataConfig.Backend", "dynamodb")
+	v.SetDefault("MetadataConfig.PostgresDSN", utils.GetEnv(utils.PostgresDSN))
+	v.SetDefault("LabelerConfig.Backend", "rekognition")
+	v.SetDefault("LabelerConfig.MinConfidence", 97)
+	v.SetDefault("LabelerConfig.MaxLabels", 10)
+	v.SetDefault("TrashConfig.RetentionPeriod", 30*24*time.Hour)
+	v.SetDefault("TrashConfig.PurgeInterval", time.Hour)
+	v.SetDefault("SigningConfig.MinExpiration", time.Minute)
+	v.SetDefault("SigningConfig.MaxExpiration", 7*24*time.Hour)
+	v.SetDefault("SigningConfig.CookiePath", "/")
+	v.SetDefault("SigningConfig.CookieExpiration", time.Hour)
+	v.SetDefault("AuthConfig.Secret", utils.GetEnv(utils.JWTSecret))
+	v.SetDefault("AuthConfig.JWKSRefreshInterval", time.Hour)
+	v.SetDefault("AuthConfig.AdminScope", "admin")
+	v.SetDefault("AuthConfig.TenantClaim", "tenant")
 
 	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
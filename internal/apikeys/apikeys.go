@@ -0,0 +1,229 @@
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/logger"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"go.uber.org/zap"
+)
+
+// The API key scopes.
+const (
+	// ScopeUpload allows to upload and change files.
+	ScopeUpload = "upload"
+	// ScopeRead allows to list and download files.
+	ScopeRead = "read"
+	// ScopeDelete allows to delete files.
+	ScopeDelete = "delete"
+	// ScopeAdmin allows all the other scopes, the admin routes and the API keys management.
+	ScopeAdmin = "admin"
+)
+
+const (
+	// The API keys prefix, followed by the key id and the secret.
+	keyPrefix = "fp_"
+
+	// The min interval between the key last used updates.
+	lastUsedInterval = time.Minute
+)
+
+var (
+	// ErrInvalidKey is returned when the key doesn't exist, is revoked or expired.
+	ErrInvalidKey = errors.New("invalid API key")
+	// ErrInactiveKey is returned when a revoked or expired key is rotated.
+	ErrInactiveKey = errors.New("the API key is revoked or expired")
+)
+
+// Manager creates and resolves the API keys, stored in the metadata store.
+type Manager struct {
+	store metadata.MetadataStore
+
+	mu       sync.Mutex
+	lastUsed map[string]time.Time
+}
+
+// NewManager returns a Manager instance.
+func NewManager(store metadata.MetadataStore) *Manager {
+	return &Manager{
+		store:    store,
+		lastUsed: map[string]time.Time{},
+	}
+}
+
+// Create creates a key. It returns the key record and the key, which is never stored.
+// The zero expiresOn never expires.
+func (m *Manager) Create(name string, tenantId string, scopes []string, expiresOn time.Time) (*views.DynamoDBApiKeySchema, string, error) {
+	keyId, err := randomString(8, hex.EncodeToString)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := randomString(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := keyPrefix + keyId + "." + secret
+	apiKey := &views.DynamoDBApiKeySchema{
+		KeyId:     keyId,
+		KeyHash:   hashKey(key),
+		Name:      name,
+		TenantId:  tenantId,
+		Scopes:    scopes,
+		ExpiresOn: expiresOn.UTC(),
+		CreatedOn: time.Now().UTC(),
+	}
+
+	if err := m.store.PutApiKey(apiKey); err != nil {
+		return nil, "", err
+	}
+
+	return apiKey, key, nil
+}
+
+// Get returns the key record.
+func (m *Manager) Get(keyId string) (*views.DynamoDBApiKeySchema, error) {
+	return m.store.GetApiKey(keyId)
+}
+
+// List returns the tenant keys. The tenantId is optional.
+func (m *Manager) List(tenantId string) ([]*views.DynamoDBApiKeySchema, error) {
+	return m.store.ListApiKeys(tenantId)
+}
+
+// Rotate creates a key with the attributes of the given one, which expires after the grace period.
+func (m *Manager) Rotate(keyId string, gracePeriod time.Duration) (*views.DynamoDBApiKeySchema, string, error) {
+	apiKey, err := m.store.GetApiKey(keyId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now().UTC()
+	if !apiKey.IsActive(now) {
+		return nil, "", ErrInactiveKey
+	}
+
+	rotated, key, err := m.Create(apiKey.Name, apiKey.TenantId, apiKey.Scopes, apiKey.ExpiresOn)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if gracePeriod > 0 {
+		expiresOn := now.Add(gracePeriod)
+		if apiKey.ExpiresOn.IsZero() || expiresOn.Before(apiKey.ExpiresOn) {
+			apiKey.ExpiresOn = expiresOn
+		}
+	} else {
+		apiKey.RevokedOn = now
+	}
+
+	if err := m.store.PutApiKey(apiKey); err != nil {
+		return nil, "", err
+	}
+
+	return rotated, key, nil
+}
+
+// Revoke revokes the key. Revoking a revoked key keeps its revocation time.
+func (m *Manager) Revoke(keyId string) (*views.DynamoDBApiKeySchema, error) {
+	apiKey, err := m.store.GetApiKey(keyId)
+	if err != nil {
+		return nil, err
+	}
+
+	if apiKey.RevokedOn.IsZero() {
+		apiKey.RevokedOn = time.Now().UTC()
+		if err := m.store.PutApiKey(apiKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return apiKey, nil
+}
+
+// Resolve returns the record of the active key. Its last used time is updated in the background.
+func (m *Manager) Resolve(key string) (*views.DynamoDBApiKeySchema, error) {
+	keyId, _, found := strings.Cut(strings.TrimPrefix(key, keyPrefix), ".")
+	if !found || !strings.HasPrefix(key, keyPrefix) {
+		return nil, ErrInvalidKey
+	}
+
+	apiKey, err := m.store.GetApiKey(keyId)
+	if errors.Is(err, metadata.ErrApiKeyNotFound) {
+		return nil, ErrInvalidKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if subtle.ConstantTimeCompare([]byte(apiKey.KeyHash), []byte(hashKey(key))) != 1 || !apiKey.IsActive(now) {
+		return nil, ErrInvalidKey
+	}
+
+	if m.shouldUpdateLastUsed(keyId, now) {
+		go m.updateLastUsed(keyId, now)
+	}
+
+	return apiKey, nil
+}
+
+// shouldUpdateLastUsed checks if the key last used time wasn't updated recently, and records the update.
+func (m *Manager) shouldUpdateLastUsed(keyId string, now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now.Sub(m.lastUsed[keyId]) < lastUsedInterval {
+		return false
+	}
+	m.lastUsed[keyId] = now
+
+	return true
+}
+
+// updateLastUsed updates the key last used time.
+func (m *Manager) updateLastUsed(keyId string, now time.Time) {
+	if err := m.store.SetApiKeyLastUsed(keyId, now); err != nil {
+		logger.Error("error updating the API key last used time",
+			zap.String("keyId", keyId),
+			zap.Error(err),
+		)
+	}
+}
+
+// IsScope checks if the scope is known.
+func IsScope(scope string) bool {
+	switch scope {
+	case ScopeUpload, ScopeRead, ScopeDelete, ScopeAdmin:
+		return true
+	}
+
+	return false
+}
+
+// hashKey returns the key hash, as stored.
+func hashKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// randomString returns the encoded random bytes.
+func randomString(size int, encode func([]byte) string) (string, error) {
+	value := make([]byte, size)
+	if _, err := rand.Read(value); err != nil {
+		return "", err
+	}
+
+	return encode(value), nil
+}
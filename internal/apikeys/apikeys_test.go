@@ -0,0 +1,72 @@
+package apikeys
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager(t *testing.T) {
+	store := metadata.NewMemoryStore()
+	manager := NewManager(store)
+
+	apiKey, key, err := manager.Create("billing", "acme", []string{ScopeRead}, time.Time{})
+	assert.Nil(t, err)
+	assert.NotEqual(t, key, apiKey.KeyHash)
+
+	resolved, err := manager.Resolve(key)
+	assert.Nil(t, err)
+	assert.Equal(t, "acme", resolved.TenantId)
+	assert.Equal(t, []string{ScopeRead}, resolved.Scopes)
+
+	assert.Eventually(t, func() bool {
+		stored, err := store.GetApiKey(apiKey.KeyId)
+		return err == nil && !stored.LastUsedOn.IsZero()
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = manager.Resolve(key + "x")
+	assert.Equal(t, ErrInvalidKey, err)
+	_, err = manager.Resolve("fp_unknown.secret")
+	assert.Equal(t, ErrInvalidKey, err)
+	_, err = manager.Resolve("secret")
+	assert.Equal(t, ErrInvalidKey, err)
+
+	rotated, rotatedKey, err := manager.Rotate(apiKey.KeyId, time.Hour)
+	assert.Nil(t, err)
+	assert.NotEqual(t, apiKey.KeyId, rotated.KeyId)
+	assert.Equal(t, "billing", rotated.Name)
+
+	// The replaced key works during the grace period.
+	_, err = manager.Resolve(key)
+	assert.Nil(t, err)
+	_, err = manager.Resolve(rotatedKey)
+	assert.Nil(t, err)
+
+	_, err = manager.Revoke(apiKey.KeyId)
+	assert.Nil(t, err)
+	_, err = manager.Resolve(key)
+	assert.Equal(t, ErrInvalidKey, err)
+
+	_, _, err = manager.Rotate(apiKey.KeyId, 0)
+	assert.Equal(t, ErrInactiveKey, err)
+
+	_, _, err = manager.Rotate(rotated.KeyId, 0)
+	assert.Nil(t, err)
+	_, err = manager.Resolve(rotatedKey)
+	assert.Equal(t, ErrInvalidKey, err)
+
+	_, expiredKey, err := manager.Create("expired", "acme", []string{ScopeRead}, time.Now().Add(-time.Minute))
+	assert.Nil(t, err)
+	_, err = manager.Resolve(expiredKey)
+	assert.Equal(t, ErrInvalidKey, err)
+
+	apiKeys, err := manager.List("acme")
+	assert.Nil(t, err)
+	assert.Len(t, apiKeys, 4)
+
+	apiKeys, err = manager.List("other")
+	assert.Nil(t, err)
+	assert.Empty(t, apiKeys)
+}
@@ -0,0 +1,2 @@
+// Package apikeys contains the managed API keys of the service clients.
+package apikeys
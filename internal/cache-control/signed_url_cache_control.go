@@ -3,67 +3,103 @@ package cache_control
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/gearpoint/filepoint/internal/views"
-	"github.com/gearpoint/filepoint/pkg/aws_repository"
 	"github.com/gearpoint/filepoint/pkg/logger"
 	"github.com/gearpoint/filepoint/pkg/redis"
+	"github.com/gearpoint/filepoint/pkg/storage"
 	"go.uber.org/zap"
 )
 
 // SignedURLCacheControl is the prefixes cache control type.
+// The responses of an object are cached in the same key, by sign policy, so deleting the object key
+// removes the responses of all the policies.
 type SignedURLCacheControl struct {
-	cacheFormat     *views.GetSignedURLResponse
-	timeToLive      time.Duration
 	redisRepository *redis.RedisRepository
 }
 
+// signedURLCache is the cached response of a sign policy.
+type signedURLCache struct {
+	Response   *views.GetSignedURLResponse `json:"response"`
+	ValidUntil time.Time                   `json:"validUntil"`
+}
+
 // NewSignedURLCacheControl returns a SignedURLCacheControl instance.
 func NewSignedURLCacheControl(redisRepository *redis.RedisRepository) *SignedURLCacheControl {
-	ttl := aws_repository.SignExpiration - (1 * time.Hour)
-
 	return &SignedURLCacheControl{
-		timeToLive:      ttl,
 		redisRepository: redisRepository,
 	}
 }
 
-// Get gets the s3 signed URL response from cache.
-func (c *SignedURLCacheControl) Get(ctx context.Context, prefix string) (*views.GetSignedURLResponse, error) {
+// timeToLive returns the cache time of the policy signed URLs.
+// The URLs are cached for half their lifetime, so the cached URLs have at least half the requested lifetime left.
+func timeToLive(policy storage.SignPolicy) time.Duration {
+	return policy.Expiration() / 2
+}
+
+// getCaches gets the object cached responses, by policy key.
+func (c *SignedURLCacheControl) getCaches(ctx context.Context, prefix string) (map[string]*signedURLCache, error) {
 	cached, err := c.redisRepository.GetAny(ctx, prefix)
 	if err != nil {
 		return nil, err
 	}
 
-	cacheFormat := c.cacheFormat
-	err = json.Unmarshal(cached, &cacheFormat)
+	caches := map[string]*signedURLCache{}
+	err = json.Unmarshal(cached, &caches)
 	if err != nil {
 		return nil, err
 	}
 
-	return cacheFormat, nil
+	return caches, nil
 }
 
-// Add adds the s3 signed URL response to cache.
-func (c *SignedURLCacheControl) Add(ctx context.Context, prefix string, cache *views.GetSignedURLResponse) {
-	cacheBytes, err := json.Marshal(cache)
-	if err == nil {
-		c.redisRepository.SetAny(ctx, prefix, cacheBytes, c.timeToLive)
-		return
+// Get gets the s3 signed URL response of the policy from cache.
+func (c *SignedURLCacheControl) Get(ctx context.Context, prefix string, policy storage.SignPolicy) (*views.GetSignedURLResponse, error) {
+	caches, err := c.getCaches(ctx, prefix)
+	if err != nil {
+		return nil, err
 	}
 
-	logger.Warn("unable to set key in Redis", zap.Any("key", prefix), zap.Error(err))
-}
+	cache, ok := caches[policy.Key()]
+	if !ok || cache.Response == nil || time.Now().After(cache.ValidUntil) {
+		return nil, errors.New("signed URL not cached")
+	}
 
-// Get gets the s3 signed URL response bytes from cache.
-func (c *SignedURLCacheControl) GetBytes(ctx context.Context, prefix string) ([]byte, error) {
-	return c.redisRepository.GetAny(ctx, prefix)
+	return cache.Response, nil
 }
 
-// Add adds the s3 signed URL response bytes to cache.
-func (c *SignedURLCacheControl) AddBytes(ctx context.Context, prefix string, cache []byte) {
-	c.redisRepository.SetAny(ctx, prefix, cache, c.timeToLive)
+// Add adds the s3 signed URL response of the policy to cache.
+// The expired responses of other policies are removed.
+func (c *SignedURLCacheControl) Add(ctx context.Context, prefix string, policy storage.SignPolicy, cache *views.GetSignedURLResponse) {
+	caches, err := c.getCaches(ctx, prefix)
+	if err != nil {
+		caches = map[string]*signedURLCache{}
+	}
+
+	now := time.Now()
+	caches[policy.Key()] = &signedURLCache{
+		Response:   cache,
+		ValidUntil: now.Add(timeToLive(policy)),
+	}
+
+	var ttl time.Duration
+	for key, value := range caches {
+		if now.After(value.ValidUntil) {
+			delete(caches, key)
+			continue
+		}
+		ttl = max(ttl, value.ValidUntil.Sub(now))
+	}
+
+	cacheBytes, err := json.Marshal(caches)
+	if err == nil {
+		c.redisRepository.SetAny(ctx, prefix, cacheBytes, ttl)
+		return
+	}
+
+	logger.Warn("unable to set key in Redis", zap.Any("key", prefix), zap.Error(err))
 }
 
 // Del deletes one signed URL responses from cache.
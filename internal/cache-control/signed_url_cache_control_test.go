@@ -0,0 +1,18 @@
+package cache_control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeToLive(t *testing.T) {
+	assert.Equal(t, storage.SignExpiration/2, timeToLive(storage.SignPolicy{}))
+
+	// The short custom expirations are cached while at least half their lifetime is left.
+	policy := storage.SignPolicy{ExpiresIn: time.Minute}
+	assert.Equal(t, 30*time.Second, timeToLive(policy))
+	assert.GreaterOrEqual(t, policy.Expiration()-timeToLive(policy), policy.Expiration()/2)
+}
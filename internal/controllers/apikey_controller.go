@@ -0,0 +1,250 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gearpoint/filepoint/internal/apikeys"
+	"github.com/gearpoint/filepoint/internal/tenants"
+	"github.com/gearpoint/filepoint/internal/views"
+	http_utils "github.com/gearpoint/filepoint/pkg/http"
+	"github.com/gearpoint/filepoint/pkg/logger"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ApiKeyController is the controller for the API keys management methods.
+// The tenants admins only manage their own keys, the default tenant admins manage all the keys.
+type ApiKeyController struct {
+	apiKeys  *apikeys.Manager
+	registry *tenants.Registry
+}
+
+// NewApiKeyController returns a new ApiKeyController instance.
+func NewApiKeyController(cfg *UploadConfig) *ApiKeyController {
+	return &ApiKeyController{
+		apiKeys:  cfg.ApiKeys,
+		registry: cfg.Tenants,
+	}
+}
+
+// ApiKey godoc
+// @Summary List API keys
+// @Description Returns the API keys, without the keys themselves
+// @Tags ApiKey
+// @Param tenantId query string false "Tenant Identifier"
+// @Produce json
+// @Success 200 {object} []views.ApiKeyResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/apikeys [get]
+func (a *ApiKeyController) List(c *gin.Context) {
+	tenantId := c.Request.URL.Query().Get("tenantId")
+	if tenant := tenants.FromContext(c); !tenant.IsDefault() {
+		tenantId = tenant.Id
+	}
+
+	schemas, err := a.apiKeys.List(tenantId)
+	if err != nil {
+		abortWithApiKeyError(c, "error listing API keys", err)
+		return
+	}
+
+	response := []*views.ApiKeyResponse{}
+	for _, schema := range schemas {
+		response = append(response, schema.ToApiKeyResponse())
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ApiKey godoc
+// @Summary Create API key
+// @Description Creates an API key. The key is only returned in this response.
+// @Tags ApiKey
+// @Accept json
+// @Param CreateApiKeyRequest body views.CreateApiKeyRequest true "API key attributes"
+// @Produce json
+// @Success 200 {object} views.ApiKeyResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/apikeys [post]
+func (a *ApiKeyController) Create(c *gin.Context) {
+	request := &views.CreateApiKeyRequest{}
+	if err := http_utils.ReadRequest(c, request); err != nil {
+		abortWithBadRequest(c, "error reading request", err.Error())
+		return
+	}
+
+	if err := utils.Validate.Struct(request); err != nil {
+		abortWithBadRequest(c, "error validating data", utils.FormatValidatorErrors(err)...)
+		return
+	}
+
+	tenant := tenants.FromContext(c)
+	if request.TenantId == "" {
+		request.TenantId = tenant.Id
+	}
+	if !tenant.IsDefault() && request.TenantId != tenant.Id {
+		abortWithForbidden(c, "access denied", "the keys belong to another tenant")
+		return
+	}
+	if _, ok := a.registry.Get(request.TenantId); !ok {
+		abortWithBadRequest(c, "unknown tenant", "the tenantId must be a configured tenant")
+		return
+	}
+
+	var expiresOn time.Time
+	if request.ExpiresOn != nil {
+		if !request.ExpiresOn.After(time.Now()) {
+			abortWithBadRequest(c, "invalid expiration", "the expiresOn must be in the future")
+			return
+		}
+		expiresOn = *request.ExpiresOn
+	}
+
+	schema, key, err := a.apiKeys.Create(request.Name, request.TenantId, request.Scopes, expiresOn)
+	if err != nil {
+		abortWithApiKeyError(c, "error creating API key", err)
+		return
+	}
+
+	response := schema.ToApiKeyResponse()
+	response.Key = key
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ApiKey godoc
+// @Summary Rotate API key
+// @Description Creates a key with the same attributes. The replaced key is revoked, or expires after the grace period.
+// @Tags ApiKey
+// @Accept json
+// @Param keyId query string true "API key Identifier"
+// @Param RotateApiKeyRequest body views.RotateApiKeyRequest false "Rotation options"
+// @Produce json
+// @Success 200 {object} views.ApiKeyResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 409 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/apikeys/rotate [post]
+func (a *ApiKeyController) Rotate(c *gin.Context) {
+	keyId, ok := a.readKeyId(c)
+	if !ok {
+		return
+	}
+
+	request := &views.RotateApiKeyRequest{}
+	if c.Request.ContentLength != 0 {
+		if err := http_utils.ReadRequest(c, request); err != nil {
+			abortWithBadRequest(c, "error reading request", err.Error())
+			return
+		}
+	}
+
+	if err := utils.Validate.Struct(request); err != nil {
+		abortWithBadRequest(c, "error validating data", utils.FormatValidatorErrors(err)...)
+		return
+	}
+
+	schema, key, err := a.apiKeys.Rotate(keyId, time.Duration(request.GracePeriod)*time.Second)
+	if err != nil {
+		abortWithApiKeyError(c, "error rotating API key", err)
+		return
+	}
+
+	response := schema.ToApiKeyResponse()
+	response.Key = key
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ApiKey godoc
+// @Summary Revoke API key
+// @Description Revokes the API key. The key record is kept.
+// @Tags ApiKey
+// @Param keyId query string true "API key Identifier"
+// @Produce json
+// @Success 200 {object} views.ApiKeyResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/apikeys [delete]
+func (a *ApiKeyController) Revoke(c *gin.Context) {
+	keyId, ok := a.readKeyId(c)
+	if !ok {
+		return
+	}
+
+	schema, err := a.apiKeys.Revoke(keyId)
+	if err != nil {
+		abortWithApiKeyError(c, "error revoking API key", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schema.ToApiKeyResponse())
+}
+
+// readKeyId reads the keyId query param, of a key of the request tenant.
+// The other tenants keys aren't found. It aborts the request when false is returned.
+func (a *ApiKeyController) readKeyId(c *gin.Context) (string, bool) {
+	keyId := c.Request.URL.Query().Get("keyId")
+	if err := utils.Validate.Var(keyId, "required,hexadecimal"); err != nil {
+		abortWithBadRequest(c, "the keyId is required", "you must provide a valid keyId")
+		return "", false
+	}
+
+	schema, err := a.apiKeys.Get(keyId)
+	if err != nil {
+		abortWithApiKeyError(c, "error retrieving API key", err)
+		return "", false
+	}
+
+	if tenant := tenants.FromContext(c); !tenant.IsDefault() && schema.TenantId != tenant.Id {
+		abortWithNotFound(c, "API key not found")
+		return "", false
+	}
+
+	return keyId, true
+}
+
+// abortWithApiKeyError aborts the request with the API keys management error.
+func abortWithApiKeyError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, metadata.ErrApiKeyNotFound):
+		abortWithNotFound(c, "API key not found")
+	case errors.Is(err, apikeys.ErrInactiveKey):
+		abortWithConflict(c, message, err.Error())
+	case errors.Is(err, metadata.ErrApiKeysNotConfigured):
+		abortWithBadRequest(c, message, err.Error())
+	default:
+		logger.Error(message,
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, message)
+	}
+}
@@ -0,0 +1,125 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gearpoint/filepoint/internal/apikeys"
+	"github.com/gearpoint/filepoint/internal/server"
+	"github.com/gearpoint/filepoint/internal/tenants"
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApiKeyRoute(t *testing.T) {
+	store, err := storage.NewFileSystemStore(t.TempDir(), storage.NewHMACSigner("/v1/files", "secret"))
+	assert.Nil(t, err)
+	metadataStore := metadata.NewMemoryStore()
+	acmeMetadataStore := metadata.NewMemoryStore()
+
+	registry := tenants.NewRegistry(&tenants.Tenant{BlobStore: store, MetadataStore: metadataStore})
+	registry.Add(&tenants.Tenant{Id: "acme", BlobStore: store, MetadataStore: acmeMetadataStore}, "acme-key")
+
+	s := server.NewServer(server.ServerConfig{
+		BlobStore:     store,
+		MetadataStore: metadataStore,
+		Tenants:       registry,
+		ApiKeys:       apikeys.NewManager(metadataStore),
+	})
+	s.MapHandlers()
+
+	userId := uuid.NewString()
+	prefix := utils.GetUniquePrefix(userId)
+	objectName := prefix + "/original.txt"
+	assert.Nil(t, store.PutObject(objectName, strings.NewReader("content"), "text/plain", nil, nil))
+	assert.Nil(t, acmeMetadataStore.PutFile(&views.DynamoDBUploadSchema{
+		UserId:         userId,
+		Prefix:         prefix,
+		Status:         views.StatusActive,
+		DefinitionsMap: utils.FileDefinitionsMapping{utils.MediumDef: objectName},
+	}))
+
+	request := func(method string, url string, apiKey string, body any) *httptest.ResponseRecorder {
+		var content []byte
+		if body != nil {
+			content, err = json.Marshal(body)
+			assert.Nil(t, err)
+		}
+
+		req, err := http.NewRequest(method, url, bytes.NewReader(content))
+		assert.Nil(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if apiKey != "" {
+			req.Header.Set("Authorization", "ApiKey "+apiKey)
+		}
+
+		w := httptest.NewRecorder()
+		s.Engine.ServeHTTP(w, req)
+
+		return w
+	}
+
+	w := request("POST", "/v1/admin/apikeys", "", map[string]any{"name": "billing", "tenantId": "acme", "scopes": []string{"admin"}})
+	assert.Equal(t, 401, w.Code)
+
+	w = request("POST", "/v1/admin/quarantine/approve?prefix="+prefix, "", nil)
+	assert.Equal(t, 401, w.Code)
+
+	w = request("POST", "/v1/admin/apikeys", "acme-key", map[string]any{"name": "billing", "scopes": []string{"read"}})
+	assert.Equal(t, 200, w.Code)
+
+	created := &views.ApiKeyResponse{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), created))
+	assert.Equal(t, "acme", created.TenantId)
+	assert.NotEmpty(t, created.Key)
+
+	w = request("POST", "/v1/admin/apikeys", "acme-key", map[string]any{"name": "other", "tenantId": "default", "scopes": []string{"read"}})
+	assert.Equal(t, 403, w.Code)
+
+	w = request("POST", "/v1/admin/apikeys", "acme-key", map[string]any{"name": "other", "scopes": []string{"write"}})
+	assert.Equal(t, 400, w.Code)
+
+	w = request("GET", "/v1/upload/download?prefix="+prefix, created.Key, nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "content", w.Body.String())
+
+	w = request("DELETE", "/v1/upload?prefix="+prefix, created.Key, nil)
+	assert.Equal(t, 403, w.Code)
+
+	w = request("GET", "/v1/admin/apikeys", created.Key, nil)
+	assert.Equal(t, 403, w.Code)
+
+	w = request("GET", "/v1/admin/apikeys", "acme-key", nil)
+	assert.Equal(t, 200, w.Code)
+
+	listed := []*views.ApiKeyResponse{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Len(t, listed, 1)
+	assert.Empty(t, listed[0].Key)
+
+	w = request("POST", "/v1/admin/apikeys/rotate?keyId="+created.KeyId, "acme-key", nil)
+	assert.Equal(t, 200, w.Code)
+
+	rotated := &views.ApiKeyResponse{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), rotated))
+
+	w = request("GET", "/v1/upload/download?prefix="+prefix, created.Key, nil)
+	assert.Equal(t, 401, w.Code)
+
+	w = request("GET", "/v1/upload/download?prefix="+prefix, rotated.Key, nil)
+	assert.Equal(t, 200, w.Code)
+
+	w = request("DELETE", "/v1/admin/apikeys?keyId="+rotated.KeyId, "acme-key", nil)
+	assert.Equal(t, 200, w.Code)
+
+	w = request("GET", "/v1/upload/download?prefix="+prefix, rotated.Key, nil)
+	assert.Equal(t, 401, w.Code)
+}
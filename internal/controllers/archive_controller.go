@@ -0,0 +1,277 @@
+package controllers
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gearpoint/filepoint/internal/sender_handlers"
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/envelope"
+	http_utils "github.com/gearpoint/filepoint/pkg/http"
+	"github.com/gearpoint/filepoint/pkg/logger"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// The max number of files streamed in the request. Larger archives are built asynchronously.
+const maxSyncArchiveFiles = 100
+
+// ArchiveController is the controller for the files archive methods.
+type ArchiveController struct {
+	webhookURL    string
+	blobStore     storage.BlobStore
+	metadataStore metadata.MetadataStore
+	keyWrapper    envelope.KeyWrapper
+}
+
+// NewArchiveController returns a new ArchiveController instance.
+func NewArchiveController(cfg *UploadConfig) *ArchiveController {
+	return &ArchiveController{
+		webhookURL:    cfg.RouteConfig.WebhookURL,
+		blobStore:     cfg.BlobStore,
+		metadataStore: cfg.MetadataStore,
+		keyWrapper:    cfg.KeyWrapper,
+	}
+}
+
+// Archive godoc
+// @Summary Download archive
+// @Description Streams a ZIP archive of the files, selected by prefixes or by folder, in the given definition.
+// @Description Async requests, or requests with more than 100 files, build the archive in the storage and send its signed link in the archive.ready webhook.
+// @Tags Upload
+// @Accept json
+// @Param ArchiveRequest body views.ArchiveRequest true "Files to archive"
+// @Produce application/zip
+// @Success 200 {file} file
+// @Success 202 {object} views.ArchiveResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/archive [post]
+func (a *ArchiveController) Archive(c *gin.Context) {
+	request := &views.ArchiveRequest{}
+	if err := http_utils.ReadRequest(c, request); err != nil {
+		abortWithBadRequest(c, "error reading request", err.Error())
+		return
+	}
+
+	if err := utils.Validate.Struct(request); err != nil {
+		abortWithBadRequest(c, "error validating data", utils.FormatValidatorErrors(err)...)
+		return
+	}
+
+	if !checkOwner(c, request.UserId) {
+		return
+	}
+
+	schemas, ok := a.archiveFiles(c, request)
+	if !ok {
+		return
+	}
+
+	var entries []*views.ArchiveEntry
+	for _, schema := range schemas {
+		if schema.Status != views.StatusActive || len(schema.DefinitionsMap) == 0 {
+			continue
+		}
+
+		entries = append(entries, &views.ArchiveEntry{
+			ObjectName: utils.GetClosestPrefix(schema.DefinitionsMap, request.Definition),
+			Filename:   schema.Filename,
+			Encryption: schema.Encryption,
+		})
+	}
+
+	if len(entries) == 0 {
+		abortWithNotFound(c, "no files to archive", "the selected files are not available")
+		return
+	}
+
+	if request.Async || len(entries) > maxSyncArchiveFiles {
+		// The stored archives are signed, so they can't have the decrypted files.
+		for _, entry := range entries {
+			if entry.Encryption != nil {
+				abortWithBadRequest(c, "encrypted files", fmt.Sprintf("the archives with encrypted files are streamed, with up to %d files", maxSyncArchiveFiles))
+				return
+			}
+		}
+
+		archiveObject := views.ArchiveObjectPrefix(request.UserId)
+
+		go a.buildArchive(http_utils.GetRequestId(c), archiveObject, entries)
+
+		c.JSON(http.StatusAccepted, &views.ArchiveResponse{Location: archiveObject})
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="archive.zip"`)
+	c.Status(http.StatusOK)
+
+	err := writeArchive(c.Request.Context(), c.Writer, a.blobStore, a.keyWrapper, entries)
+	if errors.Is(err, context.Canceled) {
+		logger.Info("archive download canceled by the client")
+		c.Abort()
+		return
+	}
+	if err != nil {
+		// The response was already sent, so the archive is left incomplete.
+		logger.Error("error streaming archive", zap.Error(err))
+		c.Abort()
+	}
+}
+
+// archiveFiles returns the selected files or aborts the request.
+// The prefixes must belong to the request user.
+func (a *ArchiveController) archiveFiles(c *gin.Context, request *views.ArchiveRequest) ([]*views.DynamoDBUploadSchema, bool) {
+	if len(request.Prefixes) == 0 {
+		schemas, err := a.metadataStore.ListFolderFiles(request.UserId, request.FolderId)
+		if err != nil {
+			abortWithBadRequest(c, "error listing folder files", err.Error())
+			return nil, false
+		}
+
+		return schemas, true
+	}
+
+	var schemas []*views.DynamoDBUploadSchema
+	for _, prefix := range request.Prefixes {
+		userId, depth := utils.GetPrefixFolder(prefix)
+		if userId != request.UserId || depth != 1 || !utils.CheckPrefixIsFolder(prefix) {
+			abortWithBadRequest(c, "invalid prefix", fmt.Sprintf("the prefix %s is not a file of the user", prefix))
+			return nil, false
+		}
+
+		schema, err := a.metadataStore.GetFile(userId, prefix)
+		if err != nil {
+			abortWithNotFound(c, "prefix not found", prefix)
+			return nil, false
+		}
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, true
+}
+
+// buildArchive writes the archive in the storage and sends its signed link by webhook.
+func (a *ArchiveController) buildArchive(requestId string, archiveObject string, entries []*views.ArchiveEntry) {
+	payload := &views.EventWebhookPayload{
+		Event:    views.ArchiveReadyEvent,
+		Id:       requestId,
+		Location: archiveObject,
+	}
+
+	data, err := a.uploadArchive(archiveObject, entries)
+	if err != nil {
+		logger.Error("error building archive",
+			zap.String("archiveObject", archiveObject),
+			zap.Error(err),
+		)
+		payload.Event = views.ArchiveFailedEvent
+		data = &views.ArchiveData{Error: "error building archive"}
+	}
+	payload.Data = data
+
+	sender_handlers.SendEventWebhook(context.Background(), payload, a.webhookURL)
+}
+
+// uploadArchive streams the archive to the storage and returns its signed link.
+// The archive is a temporary file, removed by the bucket lifecycle rule.
+func (a *ArchiveController) uploadArchive(archiveObject string, entries []*views.ArchiveEntry) (*views.ArchiveData, error) {
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(writeArchive(context.Background(), writer, a.blobStore, a.keyWrapper, entries))
+	}()
+
+	tagging := storage.TempFileRule
+	err := a.blobStore.UploadChunks(archiveObject, reader, "application/zip", nil, &tagging)
+	reader.CloseWithError(err)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := a.blobStore.GetSignedObject(archiveObject, storage.SignPolicy{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &views.ArchiveData{
+		Url:     response.Url,
+		Expires: response.Expires,
+		Files:   len(entries),
+	}, nil
+}
+
+// writeArchive writes the ZIP archive of the entries, reading one object at a time.
+// It stops when the context is done. The missing objects are skipped, and the encrypted ones are decrypted.
+func writeArchive(
+	ctx context.Context, w io.Writer, blobStore storage.BlobStore, keyWrapper envelope.KeyWrapper, entries []*views.ArchiveEntry,
+) error {
+	archive := zip.NewWriter(w)
+	names := views.ArchiveNames{}
+
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		filename := entry.Filename
+		info, err := blobStore.HeadObject(entry.ObjectName)
+		if storage.CheckIsNotFoundError(err) {
+			logger.Warn("archive object not found", zap.String("objectName", entry.ObjectName))
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if info.Metadata["filename"] != "" {
+			filename = info.Metadata["filename"]
+		}
+
+		reader, err := openObject(blobStore, keyWrapper, entry.ObjectName, info, entry.Encryption)
+		if err != nil {
+			return err
+		}
+
+		err = writeArchiveEntry(archive, reader, &zip.FileHeader{
+			Name:     names.Name(filename, entry.ObjectName),
+			Method:   zip.Deflate,
+			Modified: info.LastModified,
+		})
+		reader.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// writeArchiveEntry copies the object content into a new archive entry.
+func writeArchiveEntry(archive *zip.Writer, reader io.Reader, header *zip.FileHeader) error {
+	if header.Modified.IsZero() {
+		header.Modified = time.Now()
+	}
+
+	writer, err := archive.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(writer, reader)
+
+	return err
+}
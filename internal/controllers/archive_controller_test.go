@@ -0,0 +1,79 @@
+package controllers_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gearpoint/filepoint/internal/server"
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveRoute(t *testing.T) {
+	store, err := storage.NewFileSystemStore(t.TempDir(), storage.NewHMACSigner("/v1/files", "secret"))
+	assert.Nil(t, err)
+	metadataStore := metadata.NewMemoryStore()
+
+	userId := uuid.NewString()
+	var prefixes []string
+	for _, content := range []string{"first", "second"} {
+		prefix := utils.GetUniquePrefix(userId)
+		objectName := prefix + "/medium.txt"
+		prefixes = append(prefixes, prefix)
+
+		err := store.PutObject(objectName, strings.NewReader(content), "text/plain", map[string]string{"filename": "notes.txt"}, nil)
+		assert.Nil(t, err)
+		assert.Nil(t, metadataStore.PutFile(&views.DynamoDBUploadSchema{
+			UserId:         userId,
+			Prefix:         prefix,
+			Status:         views.StatusActive,
+			DefinitionsMap: utils.FileDefinitionsMapping{utils.MediumDef: objectName},
+		}))
+	}
+
+	s := server.NewServer(server.ServerConfig{BlobStore: store, MetadataStore: metadataStore})
+	s.MapHandlers()
+
+	body := `{"userId": "` + userId + `", "prefixes": ["` + strings.Join(prefixes, `", "`) + `"], "definition": 1}`
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/v1/upload/archive", strings.NewReader(body))
+	assert.Nil(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	assert.Nil(t, err)
+	assert.Len(t, archive.File, 2)
+	assert.Equal(t, "notes.txt", archive.File[0].Name)
+	assert.Equal(t, "notes (1).txt", archive.File[1].Name)
+
+	reader, err := archive.File[1].Open()
+	assert.Nil(t, err)
+	content, err := io.ReadAll(reader)
+	reader.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, "second", string(content))
+
+	body = `{"userId": "` + userId + `", "prefixes": ["` + uuid.NewString() + `/file"]}`
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("POST", "/v1/upload/archive", strings.NewReader(body))
+	assert.Nil(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 400, w.Code)
+}
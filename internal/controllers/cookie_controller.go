@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Default signed cookies attributes, used when they aren't configured.
+const (
+	defaultCookiePath       = "/"
+	defaultCookieExpiration = time.Hour
+)
+
+// CookieController is the controller for the signed cookies methods.
+// The cookies grant access to all the objects of a folder prefix, like the HLS segments.
+type CookieController struct {
+	blobStore      storage.BlobStore
+	options        storage.CookieOptions
+	expiration     time.Duration
+	allowUnchecked bool
+}
+
+// NewCookieController returns a new CookieController instance.
+func NewCookieController(cfg *UploadConfig) *CookieController {
+	options := storage.CookieOptions{
+		Domain: cfg.SigningConfig.CookieDomain,
+		Path:   cfg.SigningConfig.CookiePath,
+		Secure: cfg.SigningConfig.CookieSecure,
+	}
+	if options.Path == "" {
+		options.Path = defaultCookiePath
+	}
+
+	expiration := cfg.SigningConfig.CookieExpiration
+	if expiration <= 0 {
+		expiration = defaultCookieExpiration
+	}
+
+	return &CookieController{
+		blobStore:      cfg.BlobStore,
+		options:        options,
+		expiration:     expiration,
+		allowUnchecked: cfg.SigningConfig.AllowUncheckedCookies,
+	}
+}
+
+// Cookie godoc
+// @Summary Get folder signed cookies
+// @Description Sets the signed cookies that grant access to all the objects in the folder prefix (i.e. the user id or a file prefix). The signers that don't check the objects tags need the AllowUncheckedCookies config.
+// @Tags Upload
+// @Param prefix query string true "Folder prefix"
+// @Produce json
+// @Success 200 {object} views.SignedCookiesResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Header 200 {string} Set-Cookie "Signed cookies"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/cookies [get]
+func (ck *CookieController) GetSignedCookies(c *gin.Context) {
+	prefix := strings.Trim(c.Request.URL.Query().Get("prefix"), "/")
+	if prefix == "" || !utils.CheckPrefixIsFolder(prefix) || strings.Contains(prefix, "*") || hasDotSegment(prefix) {
+		abortWithBadRequest(c, "the folder prefix is required", "you must provide a valid folder prefix")
+		return
+	}
+
+	if !checkOwner(c, prefixOwner(prefix)) {
+		return
+	}
+
+	// The cookies would serve the quarantined and trashed objects, which aren't signed.
+	signer := ck.blobStore.Signer()
+	if !signer.ChecksTags() && !ck.allowUnchecked {
+		abortWithBadRequest(c, "unsupported signer", "the configured URL signer cookies don't check the quarantined and trashed files")
+		return
+	}
+
+	expires := time.Now().Add(ck.expiration)
+	cookies, err := signer.SignCookies(prefix, expires, ck.options)
+	if err != nil {
+		if errors.Is(err, storage.ErrUnsupportedPolicy) {
+			abortWithBadRequest(c, "unsupported signer", "the configured URL signer doesn't support cookies")
+			return
+		}
+
+		abortWithBadRequest(c, "error signing cookies")
+		return
+	}
+
+	response := &views.SignedCookiesResponse{
+		Prefix:  prefix,
+		Cookies: map[string]string{},
+		Expires: expires,
+	}
+	for _, cookie := range cookies {
+		http.SetCookie(c.Writer, cookie)
+		response.Cookies[cookie.Name] = cookie.Value
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// hasDotSegment checks if the prefix has relative path segments.
+func hasDotSegment(prefix string) bool {
+	for _, segment := range strings.Split(prefix, "/") {
+		if segment == "." || segment == ".." {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,120 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/internal/server"
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignedCookiesRoute(t *testing.T) {
+	store, err := storage.NewFileSystemStore(t.TempDir(), storage.NewHMACSigner("/v1/files", "secret"))
+	assert.Nil(t, err)
+
+	for _, objectName := range []string{"user/file/original.txt", "user/other/original.txt"} {
+		err = store.PutObject(objectName, strings.NewReader("content"), "text/plain", nil, nil)
+		assert.Nil(t, err)
+	}
+
+	s := server.NewServer(server.ServerConfig{BlobStore: store})
+	s.MapHandlers()
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/v1/upload/cookies?prefix=user/file", nil)
+	assert.Nil(t, err)
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+
+	response := &views.SignedCookiesResponse{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), response))
+	assert.Equal(t, "user/file", response.Prefix)
+
+	cookies := w.Result().Cookies()
+	assert.Len(t, cookies, 3)
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/v1/files/user/file/original.txt", nil)
+	assert.Nil(t, err)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "content", w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/v1/files/user/other/original.txt", nil)
+	assert.Nil(t, err)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 403, w.Code)
+
+	// The relative segments can't leave the cookie folder.
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/v1/files/user/file/../other/original.txt", nil)
+	assert.Nil(t, err)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 403, w.Code)
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/v1/files/user/file/original.txt", nil)
+	assert.Nil(t, err)
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 403, w.Code)
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/v1/upload/cookies?prefix=user/file/original.txt", nil)
+	assert.Nil(t, err)
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 400, w.Code)
+}
+
+// uncheckedSigner signs like a CDN that serves the objects without checking their tags.
+type uncheckedSigner struct {
+	*storage.HMACSigner
+}
+
+func (s uncheckedSigner) ChecksTags() bool {
+	return false
+}
+
+func TestUncheckedSignedCookiesRoute(t *testing.T) {
+	store, err := storage.NewFileSystemStore(t.TempDir(), uncheckedSigner{storage.NewHMACSigner("/v1/files", "secret")})
+	assert.Nil(t, err)
+
+	s := server.NewServer(server.ServerConfig{BlobStore: store})
+	s.MapHandlers()
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/v1/upload/cookies?prefix=user/file", nil)
+	assert.Nil(t, err)
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 400, w.Code)
+
+	s = server.NewServer(server.ServerConfig{BlobStore: store, SigningConfig: config.SigningConfig{AllowUncheckedCookies: true}})
+	s.MapHandlers()
+
+	w = httptest.NewRecorder()
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gearpoint/filepoint/pkg/logger"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ObjectStoreFunc returns the blob store that keeps the object key.
+type ObjectStoreFunc func(objectKey string) storage.BlobStore
+
+// DownloadController serves the HMAC signed URLs, of any blob store.
+type DownloadController struct {
+	objectStore ObjectStoreFunc
+	signer      *storage.HMACSigner
+}
+
+// NewDownloadController returns a new DownloadController instance.
+// The objects are read from the store returned by objectStore, as the tenants may have their own stores.
+func NewDownloadController(objectStore ObjectStoreFunc, signer *storage.HMACSigner) *DownloadController {
+	return &DownloadController{
+		objectStore: objectStore,
+		signer:      signer,
+	}
+}
+
+// Download godoc
+// @Summary Download file
+// @Description Serves the file from a HMAC signed URL, or with the folder signed cookies
+// @Tags Download
+// @Param prefix path string true "File prefix"
+// @Param expires query int false "Signature expiration (unix time)"
+// @Param notBefore query int false "Signature start time (unix time)"
+// @Param ip query string false "Allowed client CIDR"
+// @Param filename query string false "Attachment filename"
+// @Param signature query string false "URL signature. Without it, the folder signed cookies are checked"
+// @Produce octet-stream
+// @Success 200 {file} file
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Router /files/{prefix} [get]
+func (d *DownloadController) Download(c *gin.Context) {
+	prefix := strings.TrimPrefix(c.Param("prefix"), "/")
+	query := c.Request.URL.Query()
+
+	var err error
+	if query.Has("signature") {
+		err = d.signer.Verify(prefix, query, c.ClientIP())
+	} else {
+		err = d.signer.VerifyCookies(prefix, c.Request)
+	}
+	if err != nil {
+		abortWithForbidden(c, "invalid signed URL", err.Error())
+		return
+	}
+
+	blobStore := d.objectStore(prefix)
+
+	info, err := blobStore.HeadObject(prefix)
+	if err != nil {
+		if storage.CheckIsNotFoundError(err) {
+			abortWithNotFound(c, "file not found")
+			return
+		}
+
+		logger.Error("error opening file",
+			zap.String("prefix", prefix),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error opening file")
+		return
+	}
+
+	// The files aren't served when their tags can't be checked.
+	tagging, _, err := blobStore.GetObjectTagging(prefix)
+	if err != nil {
+		logger.Error("error retrieving file tags",
+			zap.String("prefix", prefix),
+			zap.Error(err),
+		)
+		abortWithInternalServerError(c, "error checking file")
+		return
+	}
+
+	if _, quarantined := tagging[storage.QuarantineTag]; quarantined {
+		abortWithForbidden(c, "quarantined file", "the file is under moderation review")
+		return
+	}
+	if _, trashed := tagging[storage.TrashTag]; trashed {
+		abortWithNotFound(c, "file not found")
+		return
+	}
+	if _, encrypted := tagging[storage.EncryptedTag]; encrypted {
+		abortWithForbidden(c, "encrypted file", "the encrypted files can't be signed")
+		return
+	}
+
+	file := storage.NewObjectReader(blobStore, prefix, info.ContentLength)
+	defer file.Close()
+
+	if info.ContentType != "" {
+		c.Header("Content-Type", info.ContentType)
+	}
+
+	if filename := d.signer.Filename(query); filename != "" {
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	}
+
+	http.ServeContent(c.Writer, c.Request, path.Base(prefix), info.LastModified, file)
+}
@@ -0,0 +1,244 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gearpoint/filepoint/internal/server"
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/envelope"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadRoute(t *testing.T) {
+	signer := storage.NewHMACSigner("/v1/files", "secret")
+	store, err := storage.NewFileSystemStore(t.TempDir(), signer)
+	assert.Nil(t, err)
+
+	err = store.PutObject("user/file/original.txt", strings.NewReader("content"), "text/plain", nil, nil)
+	assert.Nil(t, err)
+
+	s := server.NewServer(server.ServerConfig{BlobStore: store})
+	s.MapHandlers()
+
+	signedURL := signer.Sign("user/file/original.txt", time.Now().Add(time.Hour))
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", signedURL, nil)
+	assert.Nil(t, err)
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Equal(t, "content", w.Body.String())
+
+	forgedURL, err := url.Parse(signedURL)
+	assert.Nil(t, err)
+	forgedURL.Path = "/v1/files/user/other/original.txt"
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", forgedURL.String(), nil)
+	assert.Nil(t, err)
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 403, w.Code)
+}
+
+func TestDownloadPolicyRoute(t *testing.T) {
+	signer := storage.NewHMACSigner("/v1/files", "secret")
+	store, err := storage.NewFileSystemStore(t.TempDir(), signer)
+	assert.Nil(t, err)
+
+	err = store.PutObject("user/file/original.txt", strings.NewReader("content"), "text/plain", nil, nil)
+	assert.Nil(t, err)
+
+	s := server.NewServer(server.ServerConfig{BlobStore: store})
+	s.MapHandlers()
+
+	policy := storage.SignPolicy{IPRange: "192.0.2.0/24", Filename: "report.txt"}
+	signedURL := signer.SignWithPolicy("user/file/original.txt", time.Now().Add(time.Hour), policy)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", signedURL, nil)
+	assert.Nil(t, err)
+	req.RemoteAddr = "192.0.2.10:4321"
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `attachment; filename=report.txt`, w.Header().Get("Content-Disposition"))
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", signedURL, nil)
+	assert.Nil(t, err)
+	req.RemoteAddr = "198.51.100.10:4321"
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 403, w.Code)
+
+	policy = storage.SignPolicy{NotBefore: time.Now().Add(time.Minute)}
+	signedURL = signer.SignWithPolicy("user/file/original.txt", time.Now().Add(time.Hour), policy)
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", signedURL, nil)
+	assert.Nil(t, err)
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 403, w.Code)
+}
+
+func TestDownloadProxyRoute(t *testing.T) {
+	store, err := storage.NewFileSystemStore(t.TempDir(), storage.NewHMACSigner("/v1/files", "secret"))
+	assert.Nil(t, err)
+	metadataStore := metadata.NewMemoryStore()
+
+	userId := uuid.NewString()
+	prefix := utils.GetUniquePrefix(userId)
+	objectName := prefix + "/original.txt"
+
+	err = store.PutObject(objectName, strings.NewReader("0123456789"), "text/plain", map[string]string{"filename": "notes.md"}, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, metadataStore.PutFile(&views.DynamoDBUploadSchema{
+		UserId:         userId,
+		Prefix:         prefix,
+		Status:         views.StatusActive,
+		DefinitionsMap: utils.FileDefinitionsMapping{utils.MediumDef: objectName},
+	}))
+
+	s := server.NewServer(server.ServerConfig{BlobStore: store, MetadataStore: metadataStore})
+	s.MapHandlers()
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/v1/upload/download?prefix="+prefix, nil)
+	assert.Nil(t, err)
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=notes.txt`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "0123456789", w.Body.String())
+
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/v1/upload/download?prefix="+prefix, nil)
+	assert.Nil(t, err)
+	req.Header.Set("Range", "bytes=2-5")
+	req.Header.Set("If-Range", etag)
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 206, w.Code)
+	assert.Equal(t, "bytes 2-5/10", w.Header().Get("Content-Range"))
+	assert.Equal(t, "2345", w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/v1/upload/download?prefix="+prefix, nil)
+	assert.Nil(t, err)
+	req.Header.Set("If-None-Match", etag)
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 304, w.Code)
+
+	err = store.AddObjectTags(objectName, map[string]string{storage.QuarantineTag: "true"})
+	assert.Nil(t, err)
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/v1/upload/download?prefix="+prefix, nil)
+	assert.Nil(t, err)
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 403, w.Code)
+}
+
+func TestEncryptedDownloadRoute(t *testing.T) {
+	signer := storage.NewHMACSigner("/v1/files", "secret")
+	store, err := storage.NewFileSystemStore(t.TempDir(), signer)
+	assert.Nil(t, err)
+	metadataStore := metadata.NewMemoryStore()
+
+	keyFile := filepath.Join(t.TempDir(), "kek")
+	assert.Nil(t, os.WriteFile(keyFile, []byte("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="), 0600))
+	keyWrapper, err := envelope.NewLocalKeyWrapper(keyFile)
+	assert.Nil(t, err)
+
+	dataKey, err := envelope.NewDataKey()
+	assert.Nil(t, err)
+	wrappedKey, err := keyWrapper.WrapKey(dataKey)
+	assert.Nil(t, err)
+
+	userId := uuid.NewString()
+	prefix := utils.GetUniquePrefix(userId)
+	objectName := prefix + "/medium.txt"
+
+	encrypted, err := envelope.NewEncryptReader(dataKey, strings.NewReader("confidential"))
+	assert.Nil(t, err)
+	tagging := storage.EncryptedTag
+	err = store.PutObject(objectName, encrypted, "text/plain", nil, &tagging)
+	assert.Nil(t, err)
+	assert.Nil(t, metadataStore.PutFile(&views.DynamoDBUploadSchema{
+		UserId:         userId,
+		Prefix:         prefix,
+		Filename:       "salaries.txt",
+		Status:         views.StatusActive,
+		DefinitionsMap: utils.FileDefinitionsMapping{utils.MediumDef: objectName},
+		Encryption: &views.FileEncryption{
+			Algorithm:  envelope.Algorithm,
+			KeyId:      keyWrapper.KeyId(),
+			WrappedKey: wrappedKey,
+		},
+	}))
+
+	s := server.NewServer(server.ServerConfig{BlobStore: store, MetadataStore: metadataStore, KeyWrapper: keyWrapper})
+	s.MapHandlers()
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/v1/upload/download?prefix="+prefix, nil)
+	assert.Nil(t, err)
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "confidential", w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/v1/upload/download?prefix="+prefix, nil)
+	assert.Nil(t, err)
+	req.Header.Set("Range", "bytes=3-6")
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 206, w.Code)
+	assert.Equal(t, "bytes 3-6/12", w.Header().Get("Content-Range"))
+	assert.Equal(t, "fide", w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/v1/upload/files?userId="+userId, nil)
+	assert.Nil(t, err)
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+
+	list := &views.ListFilesResponse{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), list))
+	assert.Len(t, list.Items, 1)
+	assert.True(t, list.Items[0].Encrypted)
+	assert.Nil(t, list.Items[0].SignedURL)
+
+	signedURL := signer.Sign(objectName, time.Now().Add(time.Hour))
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", signedURL, nil)
+	assert.Nil(t, err)
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 403, w.Code)
+}
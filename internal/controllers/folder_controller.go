@@ -0,0 +1,390 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	cache_control "github.com/gearpoint/filepoint/internal/cache-control"
+	"github.com/gearpoint/filepoint/internal/views"
+	http_utils "github.com/gearpoint/filepoint/pkg/http"
+	"github.com/gearpoint/filepoint/pkg/logger"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// FolderController is the controller for the user folders methods.
+// Folders only group the files records, so the files objects are never moved.
+type FolderController struct {
+	blobStore     storage.BlobStore
+	metadataStore metadata.MetadataStore
+	cacheControl  *cache_control.UploadCacheControl
+}
+
+// NewFolderController returns a new FolderController instance.
+func NewFolderController(cfg *UploadConfig) *FolderController {
+	return &FolderController{
+		blobStore:     cfg.BlobStore,
+		metadataStore: cfg.MetadataStore,
+		cacheControl:  cache_control.NewUploadCacheControl(cfg.RedisRepository),
+	}
+}
+
+// Folder godoc
+// @Summary Create folder
+// @Description Creates a folder in the user root or nested in the parent folder
+// @Tags Folder
+// @Accept json
+// @Param CreateFolderRequest body views.CreateFolderRequest true "Folder attributes"
+// @Produce json
+// @Success 200 {object} views.FolderResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 409 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/folders [post]
+func (f *FolderController) Create(c *gin.Context) {
+	request := &views.CreateFolderRequest{}
+	if err := http_utils.ReadRequest(c, request); err != nil {
+		abortWithBadRequest(c, "error reading request", err.Error())
+		return
+	}
+
+	if err := utils.Validate.Struct(request); err != nil {
+		abortWithBadRequest(c, "error validating data", utils.FormatValidatorErrors(err)...)
+		return
+	}
+
+	if !checkOwner(c, request.UserId) {
+		return
+	}
+
+	folders, ok := f.listFolders(c, request.UserId)
+	if !ok {
+		return
+	}
+
+	if request.ParentId != "" && len(views.FolderDescendants(folders, request.ParentId)) == 0 {
+		abortWithNotFound(c, "parent folder not found")
+		return
+	}
+
+	if views.FolderNameExists(folders, request.ParentId, request.Name, "") {
+		abortWithConflict(c, "folder already exists", "the parent folder already has a folder with this name")
+		return
+	}
+
+	now := time.Now().UTC()
+	folder := &views.DynamoDBFolderSchema{
+		UserId:    request.UserId,
+		FolderId:  uuid.NewString(),
+		ParentId:  request.ParentId,
+		Name:      request.Name,
+		CreatedOn: now,
+		UpdatedOn: now,
+	}
+
+	err := f.metadataStore.PutFolder(folder)
+	if err != nil {
+		logger.Error("error saving folder",
+			zap.String("userId", request.UserId),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error saving folder", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, folder.ToFolderResponse())
+}
+
+// Folder godoc
+// @Summary List folder
+// @Description Returns the folder children folders and files. Without the folderId, returns the user root.
+// @Tags Folder
+// @Param userId query string true "User Identifier"
+// @Param folderId query string false "Folder Identifier"
+// @Produce json
+// @Success 200 {object} views.FolderContentsResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/folders [get]
+func (f *FolderController) List(c *gin.Context) {
+	userId, folderId, ok := readFolderParams(c, false)
+	if !ok {
+		return
+	}
+
+	folders, ok := f.listFolders(c, userId)
+	if !ok {
+		return
+	}
+
+	response := &views.FolderContentsResponse{
+		Folders: []*views.FolderResponse{},
+		Files:   []*views.FileResponse{},
+	}
+
+	if folderId != "" {
+		folder, ok := f.getFolder(c, userId, folderId)
+		if !ok {
+			return
+		}
+		response.Folder = folder.ToFolderResponse()
+	}
+
+	for _, child := range views.FolderChildren(folders, folderId) {
+		response.Folders = append(response.Folders, child.ToFolderResponse())
+	}
+
+	schemas, err := f.metadataStore.ListFolderFiles(userId, folderId)
+	if err != nil {
+		logger.Error("error listing folder files",
+			zap.String("folderId", folderId),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error listing folder files")
+		return
+	}
+
+	for _, schema := range schemas {
+		if schema.Status != views.StatusTrashed {
+			response.Files = append(response.Files, schema.ToFileResponse())
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Folder godoc
+// @Summary Update folder
+// @Description Renames the folder or moves it to another parent. An empty parent moves it to the user root.
+// @Tags Folder
+// @Accept json
+// @Param userId query string true "User Identifier"
+// @Param folderId query string true "Folder Identifier"
+// @Param UpdateFolderRequest body views.UpdateFolderRequest true "Folder changes"
+// @Produce json
+// @Success 200 {object} views.FolderResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 409 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/folders [patch]
+func (f *FolderController) Update(c *gin.Context) {
+	userId, folderId, ok := readFolderParams(c, true)
+	if !ok {
+		return
+	}
+
+	request := &views.UpdateFolderRequest{}
+	if err := http_utils.ReadRequest(c, request); err != nil {
+		abortWithBadRequest(c, "error reading request", err.Error())
+		return
+	}
+
+	if err := utils.Validate.Struct(request); err != nil {
+		abortWithBadRequest(c, "error validating data", utils.FormatValidatorErrors(err)...)
+		return
+	}
+
+	folders, ok := f.listFolders(c, userId)
+	if !ok {
+		return
+	}
+
+	folder, ok := f.getFolder(c, userId, folderId)
+	if !ok {
+		return
+	}
+
+	if request.Name != nil && *request.Name != "" {
+		folder.Name = *request.Name
+	}
+
+	if request.ParentId != nil {
+		parentId := *request.ParentId
+		if parentId != "" && len(views.FolderDescendants(folders, parentId)) == 0 {
+			abortWithNotFound(c, "parent folder not found")
+			return
+		}
+
+		if views.IsFolderDescendant(folders, parentId, folderId) {
+			abortWithBadRequest(c, "invalid parent folder", "the folder can't be moved into itself or its subfolders")
+			return
+		}
+		folder.ParentId = parentId
+	}
+
+	if views.FolderNameExists(folders, folder.ParentId, folder.Name, folderId) {
+		abortWithConflict(c, "folder already exists", "the parent folder already has a folder with this name")
+		return
+	}
+
+	folder.UpdatedOn = time.Now().UTC()
+
+	err := f.metadataStore.PutFolder(folder)
+	if err != nil {
+		logger.Error("error updating folder",
+			zap.String("folderId", folderId),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error updating folder")
+		return
+	}
+
+	c.JSON(http.StatusOK, folder.ToFolderResponse())
+}
+
+// Folder godoc
+// @Summary Delete folder
+// @Description Deletes the folder and its subfolders, moving their files to the trash
+// @Tags Folder
+// @Param userId query string true "User Identifier"
+// @Param folderId query string true "Folder Identifier"
+// @Produce json
+// @Success 200 {string} OK
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/folders [delete]
+func (f *FolderController) Delete(c *gin.Context) {
+	userId, folderId, ok := readFolderParams(c, true)
+	if !ok {
+		return
+	}
+
+	folders, ok := f.listFolders(c, userId)
+	if !ok {
+		return
+	}
+
+	descendants := views.FolderDescendants(folders, folderId)
+	if len(descendants) == 0 {
+		abortWithNotFound(c, "folder not found")
+		return
+	}
+
+	// The nested folders are deleted first, so a failure doesn't leave orphan folders.
+	for i := len(descendants) - 1; i >= 0; i-- {
+		folder := descendants[i]
+
+		schemas, err := f.metadataStore.ListFolderFiles(userId, folder.FolderId)
+		if err != nil {
+			logger.Error("error listing folder files",
+				zap.String("folderId", folder.FolderId),
+				zap.Error(err),
+			)
+			abortWithBadRequest(c, "error deleting folder")
+			return
+		}
+
+		for _, schema := range schemas {
+			if schema.Status == views.StatusTrashed {
+				continue
+			}
+
+			err := trashFile(c, f.blobStore, f.metadataStore, f.cacheControl, schema)
+			if err != nil {
+				logger.Error("error moving file to trash",
+					zap.String("prefix", schema.Prefix),
+					zap.Error(err),
+				)
+				abortWithBadRequest(c, "error deleting folder")
+				return
+			}
+		}
+
+		err = f.metadataStore.DeleteFolder(userId, folder.FolderId)
+		if err != nil {
+			logger.Error("error deleting folder",
+				zap.String("folderId", folder.FolderId),
+				zap.Error(err),
+			)
+			abortWithBadRequest(c, "error deleting folder")
+			return
+		}
+	}
+
+	c.String(http.StatusOK, "OK")
+}
+
+// listFolders returns all the user folders or aborts with bad request.
+func (f *FolderController) listFolders(c *gin.Context, userId string) ([]*views.DynamoDBFolderSchema, bool) {
+	folders, err := f.metadataStore.ListFolders(userId)
+	if err != nil {
+		logger.Error("error listing folders",
+			zap.String("userId", userId),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error listing folders", err.Error())
+		return nil, false
+	}
+
+	return folders, true
+}
+
+// getFolder returns the folder or aborts with not found.
+func (f *FolderController) getFolder(c *gin.Context, userId string, folderId string) (*views.DynamoDBFolderSchema, bool) {
+	folder, err := f.metadataStore.GetFolder(userId, folderId)
+	if errors.Is(err, metadata.ErrFolderNotFound) {
+		abortWithNotFound(c, "folder not found")
+		return nil, false
+	}
+	if err != nil {
+		abortWithBadRequest(c, "error getting folder", err.Error())
+		return nil, false
+	}
+
+	return folder, true
+}
+
+// readFolderParams reads and validates the userId and folderId query params.
+// It returns the user and folder identifiers or aborts with bad request.
+func readFolderParams(c *gin.Context, folderRequired bool) (string, string, bool) {
+	userId := c.Request.URL.Query().Get("userId")
+	if err := utils.Validate.Var(userId, "required,uuid"); err != nil {
+		abortWithBadRequest(c, "the userId is required", "you must provide a valid userId")
+		return "", "", false
+	}
+
+	if !checkOwner(c, userId) {
+		return "", "", false
+	}
+
+	folderId := c.Request.URL.Query().Get("folderId")
+	if folderId == "" && !folderRequired {
+		return userId, "", true
+	}
+
+	if err := utils.Validate.Var(folderId, "required,uuid"); err != nil {
+		abortWithBadRequest(c, "the folderId is required", "you must provide a valid folderId")
+		return "", "", false
+	}
+
+	return userId, folderId, true
+}
@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"net/http"
+
+	cache_control "github.com/gearpoint/filepoint/internal/cache-control"
+	"github.com/gearpoint/filepoint/internal/sender_handlers"
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/logger"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ModerationController is the controller for the moderation review methods.
+type ModerationController struct {
+	blobStore     storage.BlobStore
+	metadataStore metadata.MetadataStore
+	cacheControl  *cache_control.UploadCacheControl
+}
+
+// NewModerationController returns a new ModerationController instance.
+func NewModerationController(cfg *UploadConfig) *ModerationController {
+	return &ModerationController{
+		blobStore:     cfg.BlobStore,
+		metadataStore: cfg.MetadataStore,
+		cacheControl:  cache_control.NewUploadCacheControl(cfg.RedisRepository),
+	}
+}
+
+// Moderation godoc
+// @Summary List quarantined files
+// @Description Returns the files quarantined by the moderation policy
+// @Tags Moderation
+// @Param userId query string false "User Identifier"
+// @Produce json
+// @Success 200 {object} []views.FileResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/quarantine [get]
+func (m *ModerationController) ListQuarantined(c *gin.Context) {
+	userId := c.Request.URL.Query().Get("userId")
+
+	schemas, err := m.metadataStore.ListFilesByStatus(userId, views.StatusQuarantined)
+	if err != nil {
+		logger.Error("error listing quarantined files", zap.Error(err))
+		abortWithBadRequest(c, "error listing quarantined files")
+		return
+	}
+
+	response := []*views.FileResponse{}
+	for _, schema := range schemas {
+		response = append(response, schema.ToFileResponse())
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Moderation godoc
+// @Summary Approve quarantined file
+// @Description Releases the quarantined file
+// @Tags Moderation
+// @Param prefix query string true "File folder prefix"
+// @Produce json
+// @Success 200 {string} OK
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/quarantine/approve [post]
+func (m *ModerationController) Approve(c *gin.Context) {
+	schema, ok := m.getQuarantined(c)
+	if !ok {
+		return
+	}
+
+	var objects []string
+	for _, objectName := range schema.DefinitionsMap {
+		objects = append(objects, objectName)
+		err := m.blobStore.RemoveObjectTags(objectName, storage.QuarantineTag)
+		if err != nil {
+			logger.Error("error removing quarantine tag",
+				zap.String("objectName", objectName),
+				zap.Error(err),
+			)
+			abortWithBadRequest(c, "error releasing file")
+			return
+		}
+	}
+
+	_, err := metadata.UpdateFileFunc(m.metadataStore, schema.UserId, schema.Prefix, func(file *views.DynamoDBUploadSchema) error {
+		if file.Status == views.StatusQuarantined {
+			file.Status = views.StatusActive
+		}
+		file.FlaggedLabels = nil
+
+		return nil
+	})
+	if err != nil {
+		logger.Error("error updating file info in DB",
+			zap.String("prefix", schema.Prefix),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error releasing file")
+		return
+	}
+
+	m.cacheControl.SignedURLCacheControl.DelMany(c, objects)
+
+	c.String(http.StatusOK, "OK")
+}
+
+// Moderation godoc
+// @Summary Reject quarantined file
+// @Description Deletes the quarantined file and all its definitions
+// @Tags Moderation
+// @Param prefix query string true "File folder prefix"
+// @Produce json
+// @Success 200 {string} OK
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/quarantine/reject [post]
+func (m *ModerationController) Reject(c *gin.Context) {
+	schema, ok := m.getQuarantined(c)
+	if !ok {
+		return
+	}
+
+	var objects []string
+	for _, objectName := range schema.DefinitionsMap {
+		objects = append(objects, objectName)
+	}
+
+	err := sender_handlers.PurgeFile(m.metadataStore, m.blobStore, schema)
+	if err != nil {
+		logger.Error("error deleting file",
+			zap.String("prefix", schema.Prefix),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error deleting files", err.Error())
+		return
+	}
+
+	m.cacheControl.RemoveFolderFromCache(c, schema.Prefix, objects)
+	m.cacheControl.RemoveKeyFromCachedPrefixes(c, schema.Prefix)
+
+	c.String(http.StatusOK, "OK")
+}
+
+// getQuarantined returns the quarantined file schema from the prefix query param.
+func (m *ModerationController) getQuarantined(c *gin.Context) (*views.DynamoDBUploadSchema, bool) {
+	prefix, userId, ok := readFilePrefix(c)
+	if !ok {
+		return nil, false
+	}
+
+	schema, err := m.metadataStore.GetFile(userId, prefix)
+	if err != nil {
+		abortWithNotFound(c, "prefix not found")
+		return nil, false
+	}
+
+	if schema.Status != views.StatusQuarantined {
+		abortWithBadRequest(c, "file is not quarantined")
+		return nil, false
+	}
+
+	return schema, true
+}
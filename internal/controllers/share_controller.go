@@ -0,0 +1,379 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gearpoint/filepoint/internal/tenants"
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/envelope"
+	http_utils "github.com/gearpoint/filepoint/pkg/http"
+	"github.com/gearpoint/filepoint/pkg/logger"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// The shared objects signed URLs lifetime, only long enough to follow the redirect.
+	shareURLExpiration = time.Minute
+
+	// The header of the protected shares password.
+	SharePasswordHeader = "X-Share-Password"
+)
+
+// ShareController is the controller for the share links methods.
+// The shares are kept in the main store, and the shared files are read from the share tenant stores.
+type ShareController struct {
+	metadataStore metadata.MetadataStore
+	keyWrapper    envelope.KeyWrapper
+	registry      *tenants.Registry
+}
+
+// NewShareController returns a new ShareController instance.
+func NewShareController(cfg *UploadConfig) *ShareController {
+	return &ShareController{
+		metadataStore: cfg.MetadataStore,
+		keyWrapper:    cfg.KeyWrapper,
+		registry:      cfg.Tenants,
+	}
+}
+
+// Share godoc
+// @Summary Create share link
+// @Description Creates a public link to a file or a folder, with optional expiration, password, downloads limit and definitions. The token is only returned in this response.
+// @Tags Share
+// @Accept json
+// @Param CreateShareRequest body views.CreateShareRequest true "Share attributes"
+// @Produce json
+// @Success 200 {object} views.ShareResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /share [post]
+func (s *ShareController) Create(c *gin.Context) {
+	request := &views.CreateShareRequest{}
+	if err := http_utils.ReadRequest(c, request); err != nil {
+		abortWithBadRequest(c, "error reading request", err.Error())
+		return
+	}
+
+	if err := utils.Validate.Struct(request); err != nil {
+		abortWithBadRequest(c, "error validating data", utils.FormatValidatorErrors(err)...)
+		return
+	}
+
+	if !checkOwner(c, request.UserId) {
+		return
+	}
+
+	tenant := tenants.FromContext(c)
+	if request.FolderId != "" {
+		_, err := tenant.MetadataStore.GetFolder(request.UserId, request.FolderId)
+		if errors.Is(err, metadata.ErrFolderNotFound) {
+			abortWithNotFound(c, "folder not found")
+			return
+		}
+		if err != nil {
+			abortWithBadRequest(c, "error getting folder", err.Error())
+			return
+		}
+	} else {
+		userId, depth := utils.GetPrefixFolder(request.Prefix)
+		if !utils.CheckPrefixIsFolder(request.Prefix) || depth != 1 || userId != request.UserId {
+			abortWithBadRequest(c, "the file prefix is required", "you must provide a valid file prefix of the user")
+			return
+		}
+
+		if _, ok := getSharedFile(c, tenant, request.UserId, request.Prefix); !ok {
+			return
+		}
+	}
+
+	var expiresOn time.Time
+	if request.ExpiresOn != nil {
+		if !request.ExpiresOn.After(time.Now()) {
+			abortWithBadRequest(c, "invalid expiration", "the expiresOn must be in the future")
+			return
+		}
+		expiresOn = *request.ExpiresOn
+	}
+
+	var passwordHash []byte
+	if request.Password != "" {
+		var err error
+		passwordHash, err = bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+		if err != nil {
+			abortWithShareError(c, "error creating share", err)
+			return
+		}
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		abortWithShareError(c, "error creating share", err)
+		return
+	}
+
+	share := &views.DynamoDBShareSchema{
+		TokenHash:    hashShareToken(token),
+		TenantId:     tenant.Id,
+		UserId:       request.UserId,
+		Prefix:       request.Prefix,
+		FolderId:     request.FolderId,
+		Definitions:  request.Definitions,
+		PasswordHash: string(passwordHash),
+		MaxDownloads: request.MaxDownloads,
+		ExpiresOn:    expiresOn,
+		CreatedOn:    time.Now().UTC(),
+	}
+
+	if err := s.metadataStore.PutShare(share); err != nil {
+		abortWithShareError(c, "error creating share", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, share.ToShareResponse(token, "/v1/s/"+token))
+}
+
+// Share godoc
+// @Summary Open share link
+// @Description Redirects to a short-lived signed URL of the shared file, and counts the download. The encrypted files are streamed. The folder shares list the files when the prefix is omitted. The password is sent in the X-Share-Password header, or in the password form field.
+// @Tags Share
+// @Param token path string true "Share token"
+// @Param prefix query string false "File prefix, of the shared folder"
+// @Param definition query utils.FileDefinitions false "File definition config"
+// @Param X-Share-Password header string false "Share password"
+// @Produce json
+// @Success 200 {object} []views.SharedFileResponse
+// @Success 302
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 410 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Header 302 {string} Location "Signed URL"
+// @Router /s/{token} [get]
+// @Router /s/{token} [post]
+func (s *ShareController) Resolve(c *gin.Context) {
+	share, ok := s.getShare(c)
+	if !ok {
+		return
+	}
+
+	tenant, ok := s.registry.Get(share.TenantId)
+	if !ok {
+		abortWithNotFound(c, "share not found")
+		return
+	}
+
+	prefix := share.Prefix
+	if share.FolderId != "" {
+		files, err := tenant.MetadataStore.ListFolderFiles(share.UserId, share.FolderId)
+		if err != nil {
+			abortWithShareError(c, "error listing shared folder", err)
+			return
+		}
+
+		prefix = c.Request.URL.Query().Get("prefix")
+		if prefix == "" {
+			response := []*views.SharedFileResponse{}
+			for _, file := range files {
+				if file.Status == views.StatusActive {
+					response = append(response, &views.SharedFileResponse{
+						Prefix:      file.Prefix,
+						Filename:    file.Filename,
+						ContentType: file.ContentType,
+					})
+				}
+			}
+
+			c.JSON(http.StatusOK, response)
+			return
+		}
+
+		found := false
+		for _, file := range files {
+			found = found || file.Prefix == prefix
+		}
+		if !found {
+			abortWithNotFound(c, "prefix not found", "the file isn't in the shared folder")
+			return
+		}
+	}
+
+	schema, ok := getSharedFile(c, tenant, share.UserId, prefix)
+	if !ok {
+		return
+	}
+
+	definitionsMap := share.DefinitionsMap(schema.DefinitionsMap)
+	if len(definitionsMap) == 0 {
+		abortWithNotFound(c, "prefix not found", "the file has no shared definitions")
+		return
+	}
+
+	definition := utils.AtoFileDefinitions(c.Request.URL.Query().Get("definition"))
+	objectName := utils.GetClosestPrefix(definitionsMap, definition)
+
+	// The encrypted objects can't be signed, so they're streamed.
+	if schema.Encryption != nil {
+		if !s.countDownload(c, share) {
+			return
+		}
+
+		serveObject(c, tenant.BlobStore, s.keyWrapper, schema, objectName)
+		return
+	}
+
+	response, err := tenant.BlobStore.GetSignedObject(objectName, storage.SignPolicy{ExpiresIn: shareURLExpiration})
+	if err != nil {
+		if storage.CheckIsNotFoundError(err) {
+			abortWithNotFound(c, "prefix not found")
+			return
+		}
+
+		logger.Error("error signing shared object",
+			zap.String("objectName", objectName),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error getting signed URL")
+		return
+	}
+
+	if response.Temporary || response.Trashed {
+		abortWithNotFound(c, "prefix not found")
+		return
+	}
+	if response.Quarantined {
+		abortWithForbidden(c, "quarantined file", "the file is under moderation review")
+		return
+	}
+
+	if !s.countDownload(c, share) {
+		return
+	}
+
+	c.Redirect(http.StatusFound, response.Url)
+}
+
+// getShare returns the share of the token path param, if it can be opened with the request password.
+// It aborts the request when false is returned.
+func (s *ShareController) getShare(c *gin.Context) (*views.DynamoDBShareSchema, bool) {
+	share, err := s.metadataStore.GetShare(hashShareToken(c.Param("token")))
+	if err != nil {
+		abortWithShareError(c, "error retrieving share", err)
+		return nil, false
+	}
+
+	if share.IsExpired(time.Now()) {
+		abortWithGone(c, "expired share", "the share link expired")
+		return nil, false
+	}
+	if share.MaxDownloads > 0 && share.Downloads >= share.MaxDownloads {
+		abortWithGone(c, "share limit reached", metadata.ErrShareLimitReached.Error())
+		return nil, false
+	}
+
+	if share.PasswordHash != "" {
+		password := c.GetHeader(SharePasswordHeader)
+		if password == "" {
+			password = c.PostForm("password")
+		}
+
+		if bcrypt.CompareHashAndPassword([]byte(share.PasswordHash), []byte(password)) != nil {
+			abortWithUnauthorized(c, "invalid share password", "the share is protected by a password")
+			return nil, false
+		}
+	}
+
+	return share, true
+}
+
+// countDownload counts the share download. The concurrent downloads can't exceed the limit.
+// It aborts the request when false is returned.
+func (s *ShareController) countDownload(c *gin.Context, share *views.DynamoDBShareSchema) bool {
+	if err := s.metadataStore.IncrementShareDownloads(share.TokenHash); err != nil {
+		abortWithShareError(c, "error counting share download", err)
+		return false
+	}
+
+	return true
+}
+
+// getSharedFile returns the active file of the tenant. It aborts the request when false is returned.
+func getSharedFile(c *gin.Context, tenant *tenants.Tenant, userId string, prefix string) (*views.DynamoDBUploadSchema, bool) {
+	schema, err := tenant.MetadataStore.GetFile(userId, prefix)
+	if errors.Is(err, metadata.ErrNotFound) {
+		abortWithNotFound(c, "prefix not found")
+		return nil, false
+	}
+	if err != nil {
+		logger.Error("error retrieving prefix info from DB",
+			zap.String("prefix", prefix),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error retrieving prefix info")
+		return nil, false
+	}
+
+	switch schema.Status {
+	case views.StatusTrashed:
+		abortWithNotFound(c, "prefix not found", "the file is in the trash")
+		return nil, false
+	case views.StatusQuarantined:
+		abortWithForbidden(c, "quarantined file", "the file is under moderation review")
+		return nil, false
+	}
+
+	return schema, true
+}
+
+// newShareToken returns a random share token.
+func newShareToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// hashShareToken returns the stored token hash, so the stored shares can't be opened.
+func hashShareToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// abortWithShareError aborts the request with the shares error.
+func abortWithShareError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, metadata.ErrShareNotFound):
+		abortWithNotFound(c, "share not found")
+	case errors.Is(err, metadata.ErrShareLimitReached):
+		abortWithGone(c, "share limit reached", err.Error())
+	case errors.Is(err, metadata.ErrSharesNotConfigured), errors.Is(err, metadata.ErrFoldersNotConfigured):
+		abortWithBadRequest(c, message, err.Error())
+	default:
+		logger.Error(message,
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, message)
+	}
+}
@@ -0,0 +1,119 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gearpoint/filepoint/internal/controllers"
+	"github.com/gearpoint/filepoint/internal/server"
+	"github.com/gearpoint/filepoint/internal/tenants"
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareRoute(t *testing.T) {
+	store, err := storage.NewFileSystemStore(t.TempDir(), storage.NewHMACSigner("/v1/files", "secret"))
+	assert.Nil(t, err)
+	metadataStore := metadata.NewMemoryStore()
+
+	s := server.NewServer(server.ServerConfig{
+		BlobStore:     store,
+		MetadataStore: metadataStore,
+		Tenants:       tenants.NewRegistry(&tenants.Tenant{BlobStore: store, MetadataStore: metadataStore}),
+	})
+	s.MapHandlers()
+
+	userId := uuid.NewString()
+	prefix := utils.GetUniquePrefix(userId)
+	objectName := prefix + "/original.txt"
+	assert.Nil(t, store.PutObject(objectName, strings.NewReader("content"), "text/plain", nil, nil))
+	assert.Nil(t, metadataStore.PutFile(&views.DynamoDBUploadSchema{
+		UserId:         userId,
+		Prefix:         prefix,
+		Status:         views.StatusActive,
+		DefinitionsMap: utils.FileDefinitionsMapping{utils.MediumDef: objectName},
+	}))
+
+	request := func(method string, url string, password string, body any) *httptest.ResponseRecorder {
+		var content []byte
+		if body != nil {
+			content, err = json.Marshal(body)
+			assert.Nil(t, err)
+		}
+
+		req, err := http.NewRequest(method, url, bytes.NewReader(content))
+		assert.Nil(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if password != "" {
+			req.Header.Set(controllers.SharePasswordHeader, password)
+		}
+
+		w := httptest.NewRecorder()
+		s.Engine.ServeHTTP(w, req)
+
+		return w
+	}
+
+	create := func(body map[string]any) *views.ShareResponse {
+		w := request("POST", "/v1/share", "", body)
+		assert.Equal(t, 200, w.Code)
+
+		share := &views.ShareResponse{}
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), share))
+
+		return share
+	}
+
+	w := request("POST", "/v1/share", "", map[string]any{"userId": userId, "prefix": utils.GetUniquePrefix(userId)})
+	assert.Equal(t, 404, w.Code)
+
+	w = request("POST", "/v1/share", "", map[string]any{"userId": userId, "prefix": prefix, "expiresOn": time.Now().Add(-time.Hour)})
+	assert.Equal(t, 400, w.Code)
+
+	share := create(map[string]any{"userId": userId, "prefix": prefix, "password": "secret-password", "maxDownloads": 1})
+	assert.True(t, share.Protected)
+	assert.Equal(t, "/v1/s/"+share.Token, share.Url)
+
+	w = request("GET", share.Url, "", nil)
+	assert.Equal(t, 401, w.Code)
+
+	w = request("GET", share.Url, "wrong-password", nil)
+	assert.Equal(t, 401, w.Code)
+
+	w = request("GET", share.Url, "secret-password", nil)
+	assert.Equal(t, 302, w.Code)
+
+	w = request("GET", w.Header().Get("Location"), "", nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "content", w.Body.String())
+
+	w = request("GET", share.Url, "secret-password", nil)
+	assert.Equal(t, 410, w.Code)
+
+	share = create(map[string]any{"userId": userId, "prefix": prefix, "definitions": []int{int(utils.HighDef)}})
+
+	w = request("GET", share.Url, "", nil)
+	assert.Equal(t, 404, w.Code)
+
+	share = create(map[string]any{"userId": userId, "prefix": prefix, "expiresOn": time.Now().Add(time.Second)})
+
+	w = request("GET", share.Url, "", nil)
+	assert.Equal(t, 302, w.Code)
+
+	time.Sleep(time.Second)
+
+	w = request("GET", share.Url, "", nil)
+	assert.Equal(t, 410, w.Code)
+
+	w = request("GET", "/v1/s/unknown", "", nil)
+	assert.Equal(t, 404, w.Code)
+}
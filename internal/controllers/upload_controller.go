@@ -3,23 +3,36 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"mime/multipart"
 	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/internal/apikeys"
 	cache_control "github.com/gearpoint/filepoint/internal/cache-control"
 	"github.com/gearpoint/filepoint/internal/sender_handlers"
+	"github.com/gearpoint/filepoint/internal/tenants"
 	"github.com/gearpoint/filepoint/internal/uploader"
 	"github.com/gearpoint/filepoint/internal/uploader/strategies"
+	"github.com/gearpoint/filepoint/internal/usage"
 	"github.com/gearpoint/filepoint/internal/views"
 	"github.com/gearpoint/filepoint/pkg/aws_repository"
+	"github.com/gearpoint/filepoint/pkg/envelope"
 	http_utils "github.com/gearpoint/filepoint/pkg/http"
 	"github.com/gearpoint/filepoint/pkg/logger"
+	"github.com/gearpoint/filepoint/pkg/metadata"
 	"github.com/gearpoint/filepoint/pkg/redis"
+	"github.com/gearpoint/filepoint/pkg/storage"
 	"github.com/gearpoint/filepoint/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -28,6 +41,18 @@ import (
 const (
 	// The field that contains the file.
 	ContentField = "content"
+
+	// The default page size of the search results.
+	defaultSearchLimit int32 = 20
+
+	// The max pages read by a search request. The client continues with the cursor.
+	maxSearchPages = 3
+
+	// The default page size of the files listing.
+	defaultListLimit int32 = 20
+
+	// The max number of objects signed at the same time.
+	maxSigningConcurrency = 10
 )
 
 // UploadConfig contains the upload controller config.
@@ -36,50 +61,139 @@ type UploadConfig struct {
 	PartitionKey    string
 	Publisher       message.Publisher
 	AWSRepository   *aws_repository.AWSRepository
+	BlobStore       storage.BlobStore
+	MetadataStore   metadata.MetadataStore
 	RedisRepository *redis.RedisRepository
+	// KeyWrapper unwraps the encrypted files data keys. It's nil without encrypted routes.
+	KeyWrapper envelope.KeyWrapper
+	// SigningConfig bounds the signed URLs expiration. The storage defaults are used when empty.
+	SigningConfig config.SigningConfig
+	// TenantId is set in the published messages, to process them with the tenant configuration.
+	TenantId string
+	// AllowedStrategies are the accepted upload strategies. All the strategies are accepted when empty.
+	AllowedStrategies []string
+	// MaxFileSize is the max uploaded file size, in bytes. Zero doesn't limit the size.
+	MaxFileSize int64
+	// Quota is the storage quotas, checked before the uploads are accepted.
+	Quota config.QuotaConfig
+	// ApiKeys and Tenants are used by the API keys management.
+	ApiKeys *apikeys.Manager
+	Tenants *tenants.Registry
 }
 
 // UploadController is the controller for the upload route methods.
 type UploadController struct {
-	tableName     string
-	topic         string
-	webhookURL    string
-	partitionKey  string
-	publisher     message.Publisher
-	awsRepository *aws_repository.AWSRepository
-	cacheControl  *cache_control.UploadCacheControl
+	topic             string
+	webhookURL        string
+	partitionKey      string
+	tagKeys           []string
+	publisher         message.Publisher
+	awsRepository     *aws_repository.AWSRepository
+	blobStore         storage.BlobStore
+	metadataStore     metadata.MetadataStore
+	cacheControl      *cache_control.UploadCacheControl
+	keyWrapper        envelope.KeyWrapper
+	minSignExpiration time.Duration
+	maxSignExpiration time.Duration
+	tenantId          string
+	allowedStrategies []string
+	maxFileSize       int64
+	quota             config.QuotaConfig
 }
 
 // NewUploadController returns a new UploadService instance.
 func NewUploadController(cfg *UploadConfig) *UploadController {
+	minSignExpiration := cfg.SigningConfig.MinExpiration
+	if minSignExpiration <= 0 {
+		minSignExpiration = storage.MinSignExpiration
+	}
+
+	maxSignExpiration := cfg.SigningConfig.MaxExpiration
+	if maxSignExpiration <= 0 {
+		maxSignExpiration = storage.MaxSignExpiration
+	}
+
 	return &UploadController{
-		tableName:     cfg.RouteConfig.TableName,
-		topic:         cfg.RouteConfig.Topic,
-		webhookURL:    cfg.RouteConfig.WebhookURL,
-		partitionKey:  cfg.PartitionKey,
-		publisher:     cfg.Publisher,
-		awsRepository: cfg.AWSRepository,
-		cacheControl:  cache_control.NewUploadCacheControl(cfg.RedisRepository),
+		topic:             cfg.RouteConfig.Topic,
+		webhookURL:        cfg.RouteConfig.WebhookURL,
+		partitionKey:      cfg.PartitionKey,
+		tagKeys:           cfg.RouteConfig.TaggedMetadataKeys,
+		publisher:         cfg.Publisher,
+		awsRepository:     cfg.AWSRepository,
+		blobStore:         cfg.BlobStore,
+		metadataStore:     cfg.MetadataStore,
+		cacheControl:      cache_control.NewUploadCacheControl(cfg.RedisRepository),
+		keyWrapper:        cfg.KeyWrapper,
+		minSignExpiration: minSignExpiration,
+		maxSignExpiration: maxSignExpiration,
+		tenantId:          cfg.TenantId,
+		allowedStrategies: cfg.AllowedStrategies,
+		maxFileSize:       cfg.MaxFileSize,
+		quota:             cfg.Quota,
+	}
+}
+
+// checkTenantLimits aborts the request when the file strategy or size isn't allowed for the tenant.
+func (u *UploadController) checkTenantLimits(c *gin.Context, eventType strategies.EventTypeKey, size int64) bool {
+	if len(u.allowedStrategies) > 0 && !slices.Contains(u.allowedStrategies, string(eventType)) {
+		abortWithBadRequest(c, "error validating file content type", fmt.Sprintf("%s files are not allowed", eventType))
+		return false
+	}
+
+	if u.maxFileSize > 0 && size > u.maxFileSize {
+		abortWithRequestEntityTooLarge(c, "file too large", fmt.Sprintf("the max file size is %d bytes", u.maxFileSize))
+		return false
+	}
+
+	return true
+}
+
+// checkQuota aborts the request when the bytes and files exceed the user or tenant storage quota.
+// The quotas are soft: the files are counted when processed, so the concurrent uploads can all pass the check.
+func (u *UploadController) checkQuota(c *gin.Context, userId string, size int64, files int64) bool {
+	err := usage.Check(u.metadataStore, u.quota, userId, size, files)
+	if err == nil {
+		return true
+	}
+
+	quotaErr := &usage.QuotaError{}
+	if errors.As(err, &quotaErr) {
+		abortWithRequestEntityTooLarge(c, "storage quota exceeded", quotaErr.Error())
+		return false
 	}
+
+	logger.Error("error checking the storage quota",
+		zap.String("userId", userId),
+		zap.Error(err),
+	)
+	abortWithBadRequest(c, "error checking the storage quota", err.Error())
+	return false
 }
 
 // todo: batch upload
 
 // Upload godoc
 // @Summary File upload
-// @Description Saves a file in the storage service and sends webhook.
+// @Description Saves a file in the storage service and sends webhook. The file must fit in the user and tenant storage quotas.
 // @Tags Upload
 // @Accept multipart/form-data
 // @Param userId formData string true "User Identifier"
 // @Param author formData string false "File upload author"
 // @Param title formData string false "File title"
+// @Param metadata formData string false "File custom metadata (JSON object)"
+// @Param folderId formData string false "Folder Identifier"
 // @Param content formData file true "File to be uploaded"
 // @Produce json
 // @Success 202
 // @Header 202 {object} Webhook-Request-Body "views.WebhookPayload{Id:"X-Request-Id", Success:true, CorrelationId:"", Location:"{location}", Error:""}"
 // @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 413 {object} http_utils.RestError
 // @Failure 500
 // @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /upload [post]
 func (u *UploadController) Upload(c *gin.Context) {
 	requestBody := &views.UploadRequest{}
@@ -106,12 +220,25 @@ func (u *UploadController) Upload(c *gin.Context) {
 		return
 	}
 
+	if !checkOwner(c, requestBody.UserId) {
+		return
+	}
+
+	if !u.checkTenantLimits(c, eventType, fileHeader.Size) {
+		return
+	}
+
+	if !u.checkQuota(c, requestBody.UserId, fileHeader.Size, 1) {
+		return
+	}
+
 	uploadPubSub := &views.UploadPubSub{
 		Id:            http_utils.GetRequestId(c),
 		UserId:        requestBody.UserId,
 		Author:        requestBody.Author,
 		Title:         requestBody.Title,
 		CorrelationId: requestBody.CorrelationId,
+		Metadata:      requestBody.Metadata,
 		Filename:      fileHeader.Filename,
 		ContentType:   contentType,
 		Size:          fileHeader.Size,
@@ -124,14 +251,19 @@ func (u *UploadController) Upload(c *gin.Context) {
 		Prefix:        utils.GetUniquePrefix(uploadPubSub.UserId),
 		Author:        requestBody.Author,
 		Title:         requestBody.Title,
+		Filename:      fileHeader.Filename,
+		FolderId:      requestBody.FolderId,
 		RequestId:     uploadPubSub.Id,
 		CorrelationId: uploadPubSub.CorrelationId,
-		OccurredOn:    time.Now(),
+		Metadata:      uploadPubSub.Metadata,
+		ContentType:   contentType,
+		OccurredOn:    time.Now().UTC(),
 	}
 
 	uploader.SetConfig(&strategies.UploaderConfig{
 		UploadView:    uploadPubSub,
 		AWSRepository: u.awsRepository,
+		BlobStore:     u.blobStore,
 		Prefix:        dynamoDBSchema.Prefix,
 	})
 
@@ -144,18 +276,31 @@ func (u *UploadController) Upload(c *gin.Context) {
 		}
 	}
 
+	uploadPubSub.Tags, err = views.MetadataTags(uploadPubSub.Metadata, u.tagKeys)
+	if err != nil {
+		abortWithBadRequest(c, "error validating metadata", err.Error())
+		return
+	}
+
+	if requestBody.FolderId != "" {
+		if _, err := u.metadataStore.GetFolder(requestBody.UserId, requestBody.FolderId); err != nil {
+			abortWithBadRequest(c, "error validating folder", err.Error())
+			return
+		}
+	}
+
 	file, err := fileHeader.Open()
 	if err != nil {
 		abortWithBadRequest(c, "error reading file", err.Error())
 	}
 
-	err = u.awsRepository.AddTableRow(u.tableName, dynamoDBSchema)
+	err = u.metadataStore.PutFile(&dynamoDBSchema)
 	if err != nil {
 		abortWithBadRequest(c, "error saving file information", err.Error())
 		return
 	}
 
-	go u.uploadWorker(eventType, uploader, file)
+	go u.uploadWorker(eventType, uploader, dynamoDBSchema.Prefix, file)
 
 	// Returns the schema of the webhook content.
 	c.Header("Webhook-Request-Body", fmt.Sprintf("%#v", views.WebhookPayload{
@@ -168,8 +313,8 @@ func (u *UploadController) Upload(c *gin.Context) {
 	c.Status(http.StatusAccepted)
 }
 
-// uploadWorker makes the upload publish.
-func (u *UploadController) uploadWorker(eventType strategies.EventTypeKey, uploader strategies.Uploader, file multipart.File) {
+// uploadWorker makes the upload publish. The prefix is the file record prefix.
+func (u *UploadController) uploadWorker(eventType strategies.EventTypeKey, uploader strategies.Uploader, prefix string, file multipart.File) {
 	cfg := uploader.Config()
 	ctx := logger.NewContext(context.Background(), zap.String("request_id", cfg.UploadView.Id))
 	logger := logger.WithContext(ctx)
@@ -192,8 +337,11 @@ func (u *UploadController) uploadWorker(eventType strategies.EventTypeKey, uploa
 
 	message := message.NewMessage(cfg.UploadView.Id, payload)
 	message.Metadata.Set(views.EventType, string(eventType))
-	message.Metadata.Set(views.S3Prefix, cfg.Prefix)
+	message.Metadata.Set(views.S3Prefix, prefix)
 	message.Metadata.Set(views.TempObjectPrefix, tempObjectPrefix)
+	if u.tenantId != "" {
+		message.Metadata.Set(views.TenantId, u.tenantId)
+	}
 
 	if u.partitionKey != "" {
 		message.Metadata.Set(u.partitionKey, cfg.UploadView.UserId)
@@ -214,304 +362,1240 @@ func (u *UploadController) uploadWorker(eventType strategies.EventTypeKey, uploa
 // @Tags Upload
 // @Param prefix query string true "File folder prefix"
 // @Param definition query utils.FileDefinitions false "File definition config"
+// @Param expiresIn query int false "URL lifetime in seconds, within the configured bounds (default 12h)"
+// @Param notBefore query string false "URL start time (RFC 3339)"
+// @Param ipRange query string false "Allowed client IP or CIDR"
+// @Param attachment query bool false "Forces the download with the original filename"
+// @Param filename query string false "Forces the download with the given filename"
 // @Produce json
 // @Success 200 {object} views.GetSignedURLResponse
 // @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
 // @Failure 500
 // @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /upload [get]
 func (u *UploadController) GetSignedURL(c *gin.Context) {
-	prefix := c.Request.URL.Query().Get("prefix")
-	if prefix == "" || !utils.CheckPrefixIsFolder(prefix) {
-		abortWithBadRequest(c, "the file prefix is required", "you must provide a valid file prefix")
-		return
-	}
-
-	userId, depth := utils.GetPrefixFolder(prefix)
-	if depth != 1 {
-		abortWithBadRequest(c, "the file prefix is required", "you must provide a valid file prefix")
-		return
-	}
-
-	schema := &views.DynamoDBUploadSchema{
-		UserId: userId,
-		Prefix: prefix,
-	}
-
-	err := u.awsRepository.GetTableRow(u.tableName, schema)
-	if err != nil {
-		logger.Error("error retrieving prefix info from DB",
-			zap.Any("prefix", prefix),
-			zap.Error(err),
-		)
-		abortWithBadRequest(c, "error retrieving prefix info")
+	schema, ok := u.getReadableFile(c)
+	if !ok {
 		return
 	}
 
 	definition := utils.AtoFileDefinitions(c.Request.URL.Query().Get("definition"))
 	completePrefix := utils.GetClosestPrefix(schema.DefinitionsMap, definition)
 
-	cached, err := u.cacheControl.SignedURLCacheControl.GetBytes(c, completePrefix)
-	if err == nil {
-		c.Data(http.StatusOK, gin.MIMEJSON, cached)
+	policy, ok := u.readSignPolicy(c, schema.Filename, completePrefix)
+	if !ok {
 		return
 	}
 
-	response, err := u.awsRepository.GetSignedObject(completePrefix)
+	response, err := u.signObject(c, completePrefix, policy)
 	if err != nil {
-		if aws_repository.CheckIsNotFoundError(err) {
+		if storage.CheckIsNotFoundError(err) {
 			abortWithNotFound(c, "prefix not found")
 			return
 		}
 
-		abortWithBadRequest(c, "error getting signed URL")
-		return
-	}
+		if errors.Is(err, storage.ErrUnsupportedPolicy) {
+			abortWithBadRequest(c, "unsupported policy", "the configured URL signer doesn't support the policy")
+			return
+		}
 
-	jsonResponse, err := json.Marshal(response)
-	if err != nil {
 		abortWithBadRequest(c, "error getting signed URL")
 		return
 	}
 
-	u.cacheControl.SignedURLCacheControl.AddBytes(c, completePrefix, jsonResponse)
-
 	if response.Temporary {
 		abortWithBadRequest(c, "temporary file")
 		return
 	}
 
-	c.Data(http.StatusOK, gin.MIMEJSON, jsonResponse)
-}
+	if response.Quarantined {
+		abortWithForbidden(c, "quarantined file", "the file is under moderation review")
+		return
+	}
 
-// Upload godoc
-// @Summary List files URLs from a folder
-// @Description Returns the files signed URLs
-// @Tags Upload
-// @Param prefix query string true "Folder prefix"
-// @Produce json
-// @Success 200 {object} []views.ListSignedURLResponse
-// @Failure 400 {object} http_utils.RestError
-// @Failure 500
-// @Header all {string} X-Request-Id "Request ID (UUID)"
-// @Router /upload/folder [get]
-func (u *UploadController) ListFolder(c *gin.Context) {
-	prefix := c.Request.URL.Query().Get("prefix")
-	if prefix == "" || !utils.CheckPrefixIsFolder(prefix) {
-		abortWithBadRequest(c, "the folder prefix is required", "you must provide a valid folder prefix")
+	if response.Encrypted {
+		abortWithForbidden(c, "encrypted file", "the encrypted files are downloaded from /upload/download")
 		return
 	}
 
-	prefixes := u.getFolderPrefixesFullDepth(c, prefix)
-	response := u.listPrefixes(c, prefixes)
+	response.CustomMetadata = schema.Metadata
 
 	c.JSON(http.StatusOK, response)
 }
 
 // Upload godoc
-// @Summary List files URLs
-// @Description Returns the files signed URLs
+// @Summary Download file
+// @Description Streams the file definition through the API, for the clients that can't follow the signed URLs. Supports the Range, If-Range and If-None-Match headers.
 // @Tags Upload
-// @Accept json
-// @Param ListObjectsRequest body views.ListObjectsRequest true "List files URLs request body"
-// @Produce json
-// @Success 200 {object} []views.ListSignedURLResponse
+// @Param prefix query string true "File folder prefix"
+// @Param definition query utils.FileDefinitions false "File definition config"
+// @Param Range header string false "Byte ranges (i.e. bytes=0-1023)"
+// @Param If-None-Match header string false "File ETag"
+// @Produce octet-stream
+// @Success 200 {file} file
+// @Success 206 {file} file
+// @Success 304
 // @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 416
 // @Failure 500
 // @Header all {string} X-Request-Id "Request ID (UUID)"
-// @Router /upload/list [post]
-func (u *UploadController) ListObjects(c *gin.Context) {
-	request := &views.ListObjectsRequest{}
-	err := http_utils.ReadRequest(c, request)
-	if err != nil || request.Prefixes == nil {
-		abortWithBadRequest(c, "the prefixes are required", "you must provide valid prefixes and a valid file definition")
+// @Header 200,206 {string} ETag "File ETag"
+// @Header 200,206 {string} Content-Disposition "Attachment with the original filename"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/download [get]
+func (u *UploadController) Download(c *gin.Context) {
+	schema, ok := u.getReadableFile(c)
+	if !ok {
 		return
 	}
 
-	var completePrefixes []string
-
-	var mu sync.Mutex
-	var wg sync.WaitGroup
-
-	for _, prefix := range request.Prefixes {
-		wg.Add(1)
-		go func(prefix string) {
-			defer wg.Done()
-			userId, depth := utils.GetPrefixFolder(prefix)
-			if depth != 1 {
-				logger.Error("unable to retrieve userId",
-					zap.String("prefix", prefix),
-				)
-				return
-			}
-
-			schema := &views.DynamoDBUploadSchema{
-				UserId: userId,
-				Prefix: prefix,
-			}
+	definition := utils.AtoFileDefinitions(c.Request.URL.Query().Get("definition"))
+	objectName := utils.GetClosestPrefix(schema.DefinitionsMap, definition)
 
-			err := u.awsRepository.GetTableRow(u.tableName, schema)
-			if err != nil {
-				logger.Error("error retrieving prefix info from DB",
-					zap.Any("prefix", prefix),
-					zap.Error(err),
-				)
-				return
-			}
+	serveObject(c, u.blobStore, u.keyWrapper, schema, objectName)
+}
 
-			completePrefix := utils.GetClosestPrefix(schema.DefinitionsMap, request.Definition)
+// serveObject streams the file object, with the Range, If-Range and If-None-Match headers support.
+// The temporary, quarantined and trashed objects aren't served.
+func serveObject(
+	c *gin.Context, blobStore storage.BlobStore, keyWrapper envelope.KeyWrapper, schema *views.DynamoDBUploadSchema, objectName string,
+) {
+	tagging, temporary, err := blobStore.GetObjectTagging(objectName)
+	if err != nil {
+		if storage.CheckIsNotFoundError(err) {
+			abortWithNotFound(c, "prefix not found")
+			return
+		}
 
-			mu.Lock()
-			completePrefixes = append(completePrefixes, completePrefix)
-			mu.Unlock()
-		}(prefix)
+		logger.Error("error retrieving object tags",
+			zap.String("objectName", objectName),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error retrieving file")
+		return
 	}
 
-	wg.Wait()
-
-	response := u.listPrefixes(c, completePrefixes)
-
-	c.JSON(http.StatusOK, response)
-}
-
-// getFolderPrefixesFullDepth returns all prefixes from the given folder.
-// It doesn't return folders in the prefixes list, only saved objects.
-func (u *UploadController) getFolderPrefixesFullDepth(ctx context.Context, folders ...string) []string {
-	var mu sync.Mutex
-	var wg sync.WaitGroup
+	if temporary {
+		abortWithBadRequest(c, "temporary file")
+		return
+	}
+	if _, quarantined := tagging[storage.QuarantineTag]; quarantined {
+		abortWithForbidden(c, "quarantined file", "the file is under moderation review")
+		return
+	}
+	if _, trashed := tagging[storage.TrashTag]; trashed {
+		abortWithNotFound(c, "prefix not found", "the file is in the trash")
+		return
+	}
 
-	var prefixes []string
-	for _, subPrefix := range folders {
-		if !utils.CheckPrefixIsFolder(subPrefix) {
-			prefixes = append(
-				prefixes,
-				subPrefix,
-			)
-			continue
+	info, err := blobStore.HeadObject(objectName)
+	if err != nil {
+		if storage.CheckIsNotFoundError(err) {
+			abortWithNotFound(c, "prefix not found")
+			return
 		}
 
-		newPrefixes, err := u.getFolderPrefixes(ctx, subPrefix)
-		if err != nil {
-			logger.Warn("error listing prefixes from folder",
-				zap.String("folder", subPrefix),
-			)
-		}
+		logger.Error("error retrieving object info",
+			zap.String("objectName", objectName),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error retrieving file")
+		return
+	}
 
-		wg.Add(1)
-		go func(folders []string) {
-			defer wg.Done()
-			toAppend := u.getFolderPrefixesFullDepth(
-				ctx,
-				folders...,
-			)
+	filename := info.Metadata["filename"]
+	if filename == "" {
+		filename = schema.Filename
+	}
+	filename = views.ArchiveNames{}.Name(filename, objectName)
 
-			mu.Lock()
-			prefixes = append(
-				prefixes,
-				toAppend...,
-			)
-			mu.Unlock()
-		}(newPrefixes)
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
 	}
 
-	wg.Wait()
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	if info.ETag != "" {
+		c.Header("ETag", info.ETag)
+	}
 
-	return prefixes
+	reader, err := openObject(blobStore, keyWrapper, objectName, info, schema.Encryption)
+	if err != nil {
+		logger.Error("error opening object",
+			zap.String("objectName", objectName),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error retrieving file")
+		return
+	}
+	defer reader.Close()
+
+	http.ServeContent(c.Writer, c.Request, "", info.LastModified, reader)
 }
 
-// getFolderPrefixes returns the given folder prefixes.
-func (u *UploadController) getFolderPrefixes(ctx context.Context, folderPrefix string) ([]string, error) {
-	prefixes, err := u.cacheControl.PrefixesCacheControl.Get(ctx, folderPrefix)
-	if err == nil {
-		return prefixes, nil
+// openObject returns the object content reader, decrypted when the file is encrypted.
+// The reader can be seeked, so the content can be served with HTTP ranges.
+func openObject(
+	blobStore storage.BlobStore, keyWrapper envelope.KeyWrapper, objectName string, info *storage.ObjectInfo, encryption *views.FileEncryption,
+) (io.ReadSeekCloser, error) {
+	reader := storage.NewObjectReader(blobStore, objectName, info.ContentLength)
+	if encryption == nil {
+		return reader, nil
 	}
 
-	prefixes, err = u.awsRepository.ListObjects(folderPrefix)
+	if keyWrapper == nil {
+		return nil, errors.New("the encryption master key isn't configured")
+	}
+
+	dataKey, err := keyWrapper.UnwrapKey(encryption.KeyId, encryption.WrappedKey)
 	if err != nil {
 		return nil, err
 	}
 
-	u.cacheControl.PrefixesCacheControl.Add(ctx, folderPrefix, prefixes)
+	decrypted, err := envelope.NewDecryptReader(dataKey, reader, info.ContentLength)
+	if err != nil {
+		return nil, err
+	}
 
-	return prefixes, nil
+	return struct {
+		io.ReadSeeker
+		io.Closer
+	}{decrypted, reader}, nil
 }
 
-// listPrefixes list the given prefixes.
-func (u *UploadController) listPrefixes(c context.Context, prefixes []string) []*views.ListSignedURLResponse {
-	var mu sync.Mutex
-	var wg sync.WaitGroup
-
-	response := []*views.ListSignedURLResponse{}
-	for _, objKey := range prefixes {
-		wg.Add(1)
-		go func(prefix string) {
-			defer wg.Done()
-
-			cached, err := u.cacheControl.SignedURLCacheControl.Get(c, prefix)
-			if err == nil {
-				if cached.Temporary {
-					return
-				}
+// getReadableFile returns the file of the prefix query, if it can be read.
+// The trashed and quarantined files can't be read. It aborts the request when false is returned.
+func (u *UploadController) getReadableFile(c *gin.Context) (*views.DynamoDBUploadSchema, bool) {
+	schema, ok := u.getFile(c)
+	if !ok {
+		return nil, false
+	}
 
-				mu.Lock()
-				response = append(response, &views.ListSignedURLResponse{
-					prefix: cached,
-				})
-				mu.Unlock()
+	if schema.Status == views.StatusTrashed {
+		abortWithNotFound(c, "prefix not found", "the file is in the trash")
+		return nil, false
+	}
 
-				return
-			}
+	if schema.Status == views.StatusQuarantined {
+		abortWithForbidden(c, "quarantined file", "the file is under moderation review")
+		return nil, false
+	}
 
-			signedUrlResponse, err := u.awsRepository.GetSignedObject(prefix)
-			if err != nil {
-				logger.Error("error getting signed object", zap.Any("prefix", prefix), zap.Error(err))
-				return
-			}
+	return schema, true
+}
 
-			u.cacheControl.SignedURLCacheControl.Add(c, prefix, signedUrlResponse)
+// getFile returns the file of the prefix query. It aborts the request when false is returned.
+func (u *UploadController) getFile(c *gin.Context) (*views.DynamoDBUploadSchema, bool) {
+	prefix := c.Request.URL.Query().Get("prefix")
+	if prefix == "" || !utils.CheckPrefixIsFolder(prefix) {
+		abortWithBadRequest(c, "the file prefix is required", "you must provide a valid file prefix")
+		return nil, false
+	}
 
-			if signedUrlResponse.Temporary {
-				return
-			}
+	userId, depth := utils.GetPrefixFolder(prefix)
+	if depth != 1 {
+		abortWithBadRequest(c, "the file prefix is required", "you must provide a valid file prefix")
+		return nil, false
+	}
 
-			mu.Lock()
-			response = append(response, &views.ListSignedURLResponse{
-				prefix: signedUrlResponse,
-			})
-			mu.Unlock()
-		}(objKey)
+	if !checkOwner(c, userId) {
+		return nil, false
+	}
+
+	schema, err := u.metadataStore.GetFile(userId, prefix)
+	if err != nil {
+		logger.Error("error retrieving prefix info from DB",
+			zap.Any("prefix", prefix),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error retrieving prefix info")
+		return nil, false
+	}
+
+	return schema, true
+}
+
+// Upload godoc
+// @Summary Update file
+// @Description Edits the file attributes, with the upload validation rules. When the version is sent, the file must still be in that version. Sends the file.updated webhook.
+// @Tags Upload
+// @Accept json
+// @Param prefix query string true "File folder prefix"
+// @Param UpdateFileRequest body views.UpdateFileRequest true "File changes"
+// @Produce json
+// @Success 200 {object} views.FileResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 409 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload [patch]
+func (u *UploadController) Update(c *gin.Context) {
+	prefix, userId, ok := readFilePrefix(c)
+	if !ok {
+		return
+	}
+
+	request := &views.UpdateFileRequest{}
+	if err := http_utils.ReadRequest(c, request); err != nil {
+		abortWithBadRequest(c, "error reading request", err.Error())
+		return
+	}
+
+	schema, err := u.metadataStore.GetFile(userId, prefix)
+	if err != nil || schema.Status == views.StatusTrashed {
+		abortWithNotFound(c, "prefix not found")
+		return
+	}
+
+	version := schema.Version
+	if request.Version != nil && *request.Version != version {
+		abortWithConflict(c, "file version conflict", "the file was changed, get it again before updating")
+		return
+	}
+
+	request.Apply(schema)
+	if err := request.Validate(schema); err != nil {
+		abortWithBadRequest(c, "error validating data", utils.FormatValidatorErrors(err)...)
+		return
+	}
+
+	if request.FolderId != nil && *request.FolderId != "" {
+		if _, err := u.metadataStore.GetFolder(userId, *request.FolderId); err != nil {
+			abortWithBadRequest(c, "error validating folder", err.Error())
+			return
+		}
+	}
+
+	err = u.metadataStore.UpdateFileIfVersion(schema, version)
+	if errors.Is(err, metadata.ErrVersionConflict) {
+		abortWithConflict(c, "file version conflict", "the file was changed, get it again before updating")
+		return
+	}
+	if err != nil {
+		logger.Error("error updating file info in DB",
+			zap.String("prefix", prefix),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error updating file")
+		return
+	}
+
+	var objects []string
+	for _, objectName := range schema.DefinitionsMap {
+		objects = append(objects, objectName)
+		if err := u.refreshObjectMetadata(objectName, schema); err != nil {
+			logger.Error("error refreshing object metadata",
+				zap.String("objectName", objectName),
+				zap.Error(err),
+			)
+		}
+	}
+
+	u.cacheControl.SignedURLCacheControl.DelMany(c, objects)
+
+	response := schema.ToFileResponse()
+
+	go sender_handlers.SendEventWebhook(context.Background(), &views.EventWebhookPayload{
+		Event:         views.FileUpdatedEvent,
+		Id:            http_utils.GetRequestId(c),
+		CorrelationId: schema.CorrelationId,
+		Location:      prefix,
+		Data:          response,
+	}, u.webhookURL)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// refreshObjectMetadata copies the object in place, with the file attributes in its metadata.
+func (u *UploadController) refreshObjectMetadata(objectName string, schema *views.DynamoDBUploadSchema) error {
+	return u.copyObject(objectName, objectName, schema)
+}
+
+// copyContent copies the objects and the text of a file content. It returns the copied objects.
+// The objects copied before a failure are deleted.
+func (u *UploadController) copyContent(src views.FileVersion, dst views.FileVersion, file *views.DynamoDBUploadSchema) ([]string, error) {
+	contentFile := *file
+	contentFile.Filename = dst.Filename
+
+	var copied []string
+	for definition, objectName := range src.DefinitionsMap {
+		dstObjectName := dst.DefinitionsMap[definition]
+		if err := u.copyObject(objectName, dstObjectName, &contentFile); err != nil {
+			u.blobStore.DeleteMany(copied)
+			return nil, err
+		}
+		copied = append(copied, dstObjectName)
+	}
+
+	if src.TextObject != "" {
+		if err := u.blobStore.CopyObject(src.TextObject, dst.TextObject, nil); err != nil {
+			u.blobStore.DeleteMany(copied)
+			return nil, err
+		}
+		copied = append(copied, dst.TextObject)
+	}
+
+	return copied, nil
+}
+
+// copyObject copies the object to the destination, with the file attributes in its metadata.
+func (u *UploadController) copyObject(srcObjectName string, dstObjectName string, schema *views.DynamoDBUploadSchema) error {
+	info, err := u.blobStore.HeadObject(srcObjectName)
+	if err != nil {
+		return err
+	}
+
+	objectMetadata := make(map[string]string, len(info.Metadata))
+	for key, value := range info.Metadata {
+		objectMetadata[key] = value
+	}
+	objectMetadata["user-id"] = schema.UserId
+	objectMetadata["title"] = schema.Title
+	objectMetadata["author"] = schema.Author
+	if schema.Filename != "" {
+		objectMetadata["filename"] = schema.Filename
+	}
+
+	return u.blobStore.CopyObject(srcObjectName, dstObjectName, objectMetadata)
+}
+
+// Upload godoc
+// @Summary Copy file
+// @Description Copies the file and its objects to a new prefix of the given user, within the user storage quota. Only the admins and API keys can copy to other users. Sends the file.copied webhook.
+// @Tags Upload
+// @Accept json
+// @Param prefix query string true "File folder prefix"
+// @Param TransferFileRequest body views.TransferFileRequest true "Destination user"
+// @Produce json
+// @Success 200 {object} views.FileResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 413 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/copy [post]
+func (u *UploadController) Copy(c *gin.Context) {
+	prefix, userId, ok := readFilePrefix(c)
+	if !ok {
+		return
+	}
+
+	request := &views.TransferFileRequest{}
+	if err := http_utils.ReadRequest(c, request); err != nil {
+		abortWithBadRequest(c, "error reading request", err.Error())
+		return
+	}
+
+	if err := utils.Validate.Struct(request); err != nil {
+		abortWithBadRequest(c, "error validating data", utils.FormatValidatorErrors(err)...)
+		return
+	}
+
+	// The files are only copied to other users by the admins and API keys.
+	if !checkOwner(c, request.UserId) {
+		return
+	}
+
+	schema, ok := u.getTransferableFile(c, userId, prefix)
+	if !ok {
+		return
+	}
+
+	if !u.checkQuota(c, request.UserId, schema.Size, 1) {
+		return
+	}
+
+	file := schema.Transfer(request.UserId, utils.GetUniquePrefix(request.UserId))
+	file.RequestId = http_utils.GetRequestId(c)
+	file.OccurredOn = time.Now().UTC()
+
+	err := u.copyFile(schema, file)
+	if err != nil {
+		logger.Error("error copying file",
+			zap.String("prefix", prefix),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error copying file")
+		return
+	}
+
+	u.cacheControl.PrefixesCacheControl.Del(c, file.UserId)
+
+	response := file.ToFileResponse()
+
+	go sender_handlers.SendEventWebhook(context.Background(), &views.EventWebhookPayload{
+		Event:         views.FileCopiedEvent,
+		Id:            http_utils.GetRequestId(c),
+		CorrelationId: file.CorrelationId,
+		Location:      file.Prefix,
+		Data:          &views.FileTransferData{Source: prefix, File: response},
+	}, u.webhookURL)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Upload godoc
+// @Summary Move file
+// @Description Moves the file, its versions and its objects to the given user, keeping the file identifier, within the user storage quota. Only the admins and API keys can move the files. Sends the file.moved webhook.
+// @Tags Upload
+// @Accept json
+// @Param prefix query string true "File folder prefix"
+// @Param TransferFileRequest body views.TransferFileRequest true "Destination user"
+// @Produce json
+// @Success 200 {object} views.FileResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 409 {object} http_utils.RestError
+// @Failure 413 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/move [post]
+func (u *UploadController) Move(c *gin.Context) {
+	prefix, userId, ok := readFilePrefix(c)
+	if !ok {
+		return
+	}
+
+	request := &views.TransferFileRequest{}
+	if err := http_utils.ReadRequest(c, request); err != nil {
+		abortWithBadRequest(c, "error reading request", err.Error())
+		return
+	}
+
+	if err := utils.Validate.Struct(request); err != nil {
+		abortWithBadRequest(c, "error validating data", utils.FormatValidatorErrors(err)...)
+		return
+	}
+
+	if request.UserId == userId {
+		abortWithBadRequest(c, "the file already belongs to the user")
+		return
+	}
+
+	// The files are only moved to other users by the admins and API keys.
+	if !checkOwner(c, request.UserId) {
+		return
+	}
+
+	schema, ok := u.getTransferableFile(c, userId, prefix)
+	if !ok {
+		return
+	}
+
+	if !u.checkQuota(c, request.UserId, schema.StoredSize(), 1) {
+		return
+	}
+
+	file := schema.Move(request.UserId, utils.CreatePrefix(request.UserId, path.Base(prefix)))
+	if _, err := u.metadataStore.GetFile(file.UserId, file.Prefix); err == nil {
+		abortWithConflict(c, "file already exists", "the destination prefix is already in use")
+		return
+	}
+
+	err := u.copyFile(schema, file)
+	if err != nil {
+		logger.Error("error moving file",
+			zap.String("prefix", prefix),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error moving file")
+		return
+	}
+
+	var objects []string
+	for _, objectName := range schema.DefinitionsMap {
+		objects = append(objects, objectName)
+	}
+
+	err = sender_handlers.PurgeFile(u.metadataStore, u.blobStore, schema)
+	if err != nil {
+		logger.Error("error deleting moved file source",
+			zap.String("prefix", prefix),
+			zap.Error(err),
+		)
+	}
+
+	u.cacheControl.SignedURLCacheControl.DelMany(c, objects)
+	u.cacheControl.RemoveKeyFromCachedPrefixes(c, prefix)
+	u.cacheControl.PrefixesCacheControl.Del(c, file.UserId)
+
+	response := file.ToFileResponse()
+
+	go sender_handlers.SendEventWebhook(context.Background(), &views.EventWebhookPayload{
+		Event:         views.FileMovedEvent,
+		Id:            http_utils.GetRequestId(c),
+		CorrelationId: file.CorrelationId,
+		Location:      file.Prefix,
+		Data:          &views.FileTransferData{Source: prefix, File: response},
+	}, u.webhookURL)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Upload godoc
+// @Summary Rename file
+// @Description Changes the file display name, saved in the objects metadata. Sends the file.renamed webhook.
+// @Tags Upload
+// @Accept json
+// @Param prefix query string true "File folder prefix"
+// @Param RenameFileRequest body views.RenameFileRequest true "New file name"
+// @Produce json
+// @Success 200 {object} views.FileResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 409 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/rename [patch]
+func (u *UploadController) Rename(c *gin.Context) {
+	prefix, userId, ok := readFilePrefix(c)
+	if !ok {
+		return
+	}
+
+	request := &views.RenameFileRequest{}
+	if err := http_utils.ReadRequest(c, request); err != nil {
+		abortWithBadRequest(c, "error reading request", err.Error())
+		return
+	}
+
+	if err := utils.Validate.Struct(request); err != nil {
+		abortWithBadRequest(c, "error validating data", utils.FormatValidatorErrors(err)...)
+		return
+	}
+
+	schema, err := u.metadataStore.GetFile(userId, prefix)
+	if err != nil || schema.Status == views.StatusTrashed {
+		abortWithNotFound(c, "prefix not found")
+		return
+	}
+
+	schema.Filename = request.Filename
+
+	err = u.metadataStore.UpdateFileIfVersion(schema, schema.Version)
+	if errors.Is(err, metadata.ErrVersionConflict) {
+		abortWithConflict(c, "file version conflict", "the file was changed, try again")
+		return
+	}
+	if err != nil {
+		logger.Error("error updating file info in DB",
+			zap.String("prefix", prefix),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error renaming file")
+		return
+	}
+
+	var objects []string
+	for _, objectName := range schema.DefinitionsMap {
+		objects = append(objects, objectName)
+		if err := u.refreshObjectMetadata(objectName, schema); err != nil {
+			logger.Error("error refreshing object metadata",
+				zap.String("objectName", objectName),
+				zap.Error(err),
+			)
+		}
+	}
+
+	u.cacheControl.SignedURLCacheControl.DelMany(c, objects)
+
+	response := schema.ToFileResponse()
+
+	go sender_handlers.SendEventWebhook(context.Background(), &views.EventWebhookPayload{
+		Event:         views.FileRenamedEvent,
+		Id:            http_utils.GetRequestId(c),
+		CorrelationId: schema.CorrelationId,
+		Location:      prefix,
+		Data:          response,
+	}, u.webhookURL)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// getTransferableFile returns the file to copy or move, or aborts if it can't be transferred.
+func (u *UploadController) getTransferableFile(c *gin.Context, userId string, prefix string) (*views.DynamoDBUploadSchema, bool) {
+	schema, err := u.metadataStore.GetFile(userId, prefix)
+	if err != nil || schema.Status == views.StatusTrashed {
+		abortWithNotFound(c, "prefix not found")
+		return nil, false
+	}
+
+	if schema.Status == views.StatusQuarantined {
+		abortWithForbidden(c, "quarantined file", "the file is under moderation review")
+		return nil, false
+	}
+
+	if len(schema.DefinitionsMap) == 0 {
+		abortWithBadRequest(c, "file not processed", "the file is still being processed")
+		return nil, false
+	}
+
+	return schema, true
+}
+
+// copyFile copies the source file objects and saves the destination file and its index.
+// The destination versions, when moved, are copied from the source versions in the same order.
+// The copied objects are deleted if any copy fails.
+func (u *UploadController) copyFile(src *views.DynamoDBUploadSchema, dst *views.DynamoDBUploadSchema) error {
+	copied, err := u.copyContent(src.CurrentFileVersion(), dst.CurrentFileVersion(), dst)
+	if err != nil {
+		return err
+	}
+
+	for i, fileVersion := range dst.Versions {
+		versionCopied, err := u.copyContent(src.Versions[i], fileVersion, dst)
+		copied = append(copied, versionCopied...)
+		if err != nil {
+			u.blobStore.DeleteMany(copied)
+			return err
+		}
+	}
+
+	if err := u.metadataStore.PutFile(dst); err != nil {
+		u.blobStore.DeleteMany(copied)
+		return err
+	}
+
+	if err := u.metadataStore.PutIndex(dst.IndexSchemas()); err != nil {
+		logger.Error("error indexing copied file",
+			zap.String("prefix", dst.Prefix),
+			zap.Error(err),
+		)
+	}
+
+	if err := usage.AddFile(u.metadataStore, dst); err != nil && !errors.Is(err, metadata.ErrUsageNotConfigured) {
+		logger.Error("error counting copied file usage",
+			zap.String("prefix", dst.Prefix),
+			zap.Error(err),
+		)
+	}
+
+	return nil
+}
+
+// Upload godoc
+// @Summary Update file metadata
+// @Description Merges the custom metadata into the file metadata. Null values remove the keys. The configured keys are mirrored to the objects tags.
+// @Tags Upload
+// @Accept json
+// @Param prefix query string true "File folder prefix"
+// @Param UpdateMetadataRequest body views.UpdateMetadataRequest true "Metadata changes"
+// @Produce json
+// @Success 200 {object} views.FileResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 409 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/metadata [patch]
+func (u *UploadController) UpdateMetadata(c *gin.Context) {
+	prefix, userId, ok := readFilePrefix(c)
+	if !ok {
+		return
+	}
+
+	request := &views.UpdateMetadataRequest{}
+	if err := http_utils.ReadRequest(c, request); err != nil {
+		abortWithBadRequest(c, "error reading request", err.Error())
+		return
+	}
+
+	if err := utils.Validate.Struct(request); err != nil {
+		abortWithBadRequest(c, "error validating data", utils.FormatValidatorErrors(err)...)
+		return
+	}
+
+	schema, err := u.metadataStore.GetFile(userId, prefix)
+	if err != nil || schema.Status == views.StatusTrashed {
+		abortWithNotFound(c, "prefix not found")
+		return
+	}
+
+	customMetadata := request.Merge(schema.Metadata)
+	if err := utils.Validate.Var(customMetadata, "custom-metadata"); err != nil {
+		abortWithBadRequest(c, "error validating data", utils.FormatValidatorErrors(err)...)
+		return
+	}
+
+	tags, err := views.MetadataTags(customMetadata, u.tagKeys)
+	if err != nil {
+		abortWithBadRequest(c, "error validating metadata", err.Error())
+		return
+	}
+
+	var objects []string
+	for _, objectName := range schema.DefinitionsMap {
+		objects = append(objects, objectName)
+		if err := u.mirrorMetadataTags(objectName, tags); err != nil {
+			logger.Error("error mirroring metadata tags",
+				zap.String("objectName", objectName),
+				zap.Error(err),
+			)
+			abortWithBadRequest(c, "error updating metadata")
+			return
+		}
+	}
+
+	schema.Metadata = customMetadata
+
+	err = u.metadataStore.UpdateFileIfVersion(schema, schema.Version)
+	if errors.Is(err, metadata.ErrVersionConflict) {
+		abortWithConflict(c, "file version conflict", "the file was changed, try again")
+		return
+	}
+	if err != nil {
+		logger.Error("error updating file info in DB",
+			zap.String("prefix", prefix),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error updating metadata")
+		return
+	}
+
+	u.cacheControl.SignedURLCacheControl.DelMany(c, objects)
+
+	c.JSON(http.StatusOK, schema.ToFileResponse())
+}
+
+// mirrorMetadataTags replaces the object metadata tags, keeping the other ones.
+func (u *UploadController) mirrorMetadataTags(objectName string, metadataTags map[string]string) error {
+	tags, _, err := u.blobStore.GetObjectTagging(objectName)
+	if err != nil {
+		return err
+	}
+
+	for tagKey := range tags {
+		if views.IsMetadataTag(tagKey) {
+			delete(tags, tagKey)
+		}
+	}
+
+	for tagKey, tagValue := range metadataTags {
+		tags[tagKey] = tagValue
+	}
+
+	return u.blobStore.PutObjectTagging(objectName, tags)
+}
+
+// Upload godoc
+// @Summary List files URLs from a folder
+// @Description Returns the files signed URLs
+// @Tags Upload
+// @Param prefix query string true "Folder prefix"
+// @Produce json
+// @Success 200 {object} []views.ListSignedURLResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/folder [get]
+func (u *UploadController) ListFolder(c *gin.Context) {
+	prefix := c.Request.URL.Query().Get("prefix")
+	if prefix == "" || !utils.CheckPrefixIsFolder(prefix) {
+		abortWithBadRequest(c, "the folder prefix is required", "you must provide a valid folder prefix")
+		return
+	}
+
+	if !checkOwner(c, prefixOwner(prefix)) {
+		return
+	}
+
+	prefixes := u.getFolderPrefixesFullDepth(c, prefix)
+	response := u.listPrefixes(c, prefixes)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Upload godoc
+// @Summary List files URLs
+// @Description Returns the files signed URLs
+// @Tags Upload
+// @Accept json
+// @Param ListObjectsRequest body views.ListObjectsRequest true "List files URLs request body"
+// @Produce json
+// @Success 200 {object} []views.ListSignedURLResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/list [post]
+func (u *UploadController) ListObjects(c *gin.Context) {
+	request := &views.ListObjectsRequest{}
+	err := http_utils.ReadRequest(c, request)
+	if err != nil || request.Prefixes == nil {
+		abortWithBadRequest(c, "the prefixes are required", "you must provide valid prefixes and a valid file definition")
+		return
+	}
+
+	for _, prefix := range request.Prefixes {
+		if !checkOwner(c, prefixOwner(prefix)) {
+			return
+		}
+	}
+
+	var completePrefixes []string
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, prefix := range request.Prefixes {
+		wg.Add(1)
+		go func(prefix string) {
+			defer wg.Done()
+			userId, depth := utils.GetPrefixFolder(prefix)
+			if depth != 1 {
+				logger.Error("unable to retrieve userId",
+					zap.String("prefix", prefix),
+				)
+				return
+			}
+
+			schema, err := u.metadataStore.GetFile(userId, prefix)
+			if err != nil {
+				logger.Error("error retrieving prefix info from DB",
+					zap.Any("prefix", prefix),
+					zap.Error(err),
+				)
+				return
+			}
+
+			completePrefix := utils.GetClosestPrefix(schema.DefinitionsMap, request.Definition)
+
+			mu.Lock()
+			completePrefixes = append(completePrefixes, completePrefix)
+			mu.Unlock()
+		}(prefix)
+	}
+
+	wg.Wait()
+
+	response := u.listPrefixes(c, completePrefixes)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// getFolderPrefixesFullDepth returns all prefixes from the given folder.
+// It doesn't return folders in the prefixes list, only saved objects.
+func (u *UploadController) getFolderPrefixesFullDepth(ctx context.Context, folders ...string) []string {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	var prefixes []string
+	for _, subPrefix := range folders {
+		if !utils.CheckPrefixIsFolder(subPrefix) {
+			prefixes = append(
+				prefixes,
+				subPrefix,
+			)
+			continue
+		}
+
+		newPrefixes, err := u.getFolderPrefixes(ctx, subPrefix)
+		if err != nil {
+			logger.Warn("error listing prefixes from folder",
+				zap.String("folder", subPrefix),
+			)
+		}
+
+		wg.Add(1)
+		go func(folders []string) {
+			defer wg.Done()
+			toAppend := u.getFolderPrefixesFullDepth(
+				ctx,
+				folders...,
+			)
+
+			mu.Lock()
+			prefixes = append(
+				prefixes,
+				toAppend...,
+			)
+			mu.Unlock()
+		}(newPrefixes)
+	}
+
+	wg.Wait()
+
+	return prefixes
+}
+
+// getFolderPrefixes returns the given folder prefixes.
+func (u *UploadController) getFolderPrefixes(ctx context.Context, folderPrefix string) ([]string, error) {
+	prefixes, err := u.cacheControl.PrefixesCacheControl.Get(ctx, folderPrefix)
+	if err == nil {
+		return prefixes, nil
+	}
+
+	prefixes, err = u.blobStore.ListObjects(folderPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	u.cacheControl.PrefixesCacheControl.Add(ctx, folderPrefix, prefixes)
+
+	return prefixes, nil
+}
+
+// listPrefixes list the given prefixes.
+func (u *UploadController) listPrefixes(c context.Context, prefixes []string) []*views.ListSignedURLResponse {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	response := []*views.ListSignedURLResponse{}
+	for _, objKey := range prefixes {
+		wg.Add(1)
+		go func(prefix string) {
+			defer wg.Done()
+
+			signedUrlResponse, err := u.signObject(c, prefix, storage.SignPolicy{})
+			if err != nil {
+				logger.Error("error getting signed object", zap.Any("prefix", prefix), zap.Error(err))
+				return
+			}
+
+			if signedUrlResponse.Temporary || signedUrlResponse.Quarantined || signedUrlResponse.Trashed || signedUrlResponse.Encrypted {
+				return
+			}
+
+			mu.Lock()
+			response = append(response, &views.ListSignedURLResponse{
+				prefix: signedUrlResponse,
+			})
+			mu.Unlock()
+		}(objKey)
+	}
+
+	wg.Wait()
+
+	return response
+}
+
+// readSignPolicy returns the signed URL policy from the request query params.
+// The filename is the original file name, used in the attachment downloads.
+func (u *UploadController) readSignPolicy(c *gin.Context, filename string, objectName string) (storage.SignPolicy, bool) {
+	query := c.Request.URL.Query()
+	policy := storage.SignPolicy{}
+
+	if expiresIn := query.Get("expiresIn"); expiresIn != "" {
+		seconds, err := strconv.ParseInt(expiresIn, 10, 64)
+		expiration := time.Duration(seconds) * time.Second
+		if err != nil || expiration < u.minSignExpiration || expiration > u.maxSignExpiration {
+			abortWithBadRequest(c, "invalid expiresIn", fmt.Sprintf(
+				"the expiration must be between %d and %d seconds",
+				int64(u.minSignExpiration.Seconds()),
+				int64(u.maxSignExpiration.Seconds()),
+			))
+			return policy, false
+		}
+		policy.ExpiresIn = expiration
+	}
+
+	if notBefore := query.Get("notBefore"); notBefore != "" {
+		startTime, err := time.Parse(time.RFC3339, notBefore)
+		if err != nil {
+			abortWithBadRequest(c, "invalid notBefore", "the start time must be a RFC 3339 date")
+			return policy, false
+		}
+
+		if !startTime.Before(time.Now().Add(policy.Expiration())) {
+			abortWithBadRequest(c, "invalid notBefore", "the start time must be before the URL expiration")
+			return policy, false
+		}
+		policy.NotBefore = startTime
+	}
+
+	if ipRange := query.Get("ipRange"); ipRange != "" {
+		cidr, err := storage.ParseIPRange(ipRange)
+		if err != nil {
+			abortWithBadRequest(c, "invalid ipRange", err.Error())
+			return policy, false
+		}
+		policy.IPRange = cidr
+	}
+
+	if query.Get("filename") != "" {
+		policy.Filename = views.ArchiveNames{}.Name(query.Get("filename"), objectName)
+	} else if query.Get("attachment") == "true" {
+		policy.Filename = views.ArchiveNames{}.Name(filename, objectName)
+	}
+
+	return policy, true
+}
+
+// signObject returns the object signed URL response, from cache when available.
+func (u *UploadController) signObject(c context.Context, objectName string, policy storage.SignPolicy) (*views.GetSignedURLResponse, error) {
+	cached, err := u.cacheControl.SignedURLCacheControl.Get(c, objectName, policy)
+	if err == nil {
+		return cached, nil
+	}
+
+	signedUrlResponse, err := u.blobStore.GetSignedObject(objectName, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	u.cacheControl.SignedURLCacheControl.Add(c, objectName, policy, signedUrlResponse)
+
+	return signedUrlResponse, nil
+}
+
+// Upload godoc
+// @Summary List user files
+// @Description Returns a page of the user files from the DB, sorted by prefix, with the requested definition signed URLs
+// @Tags Upload
+// @Param userId query string true "User Identifier"
+// @Param definition query utils.FileDefinitions false "File definition config"
+// @Param limit query int false "Page size (max 100)"
+// @Param cursor query string false "Page cursor"
+// @Param order query string false "Sort order (asc or desc)"
+// @Produce json
+// @Success 200 {object} views.ListFilesResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/files [get]
+func (u *UploadController) ListFiles(c *gin.Context) {
+	request := &views.ListFilesRequest{}
+	if err := http_utils.ReadQueryParam(c, request); err != nil {
+		abortWithBadRequest(c, "error reading request", err.Error())
+		return
+	}
+
+	if err := utils.Validate.Struct(request); err != nil {
+		abortWithBadRequest(c, "error validating data", utils.FormatValidatorErrors(err)...)
+		return
+	}
+
+	if !checkOwner(c, request.UserId) {
+		return
+	}
+
+	if request.Limit == 0 {
+		request.Limit = defaultListLimit
+	}
+
+	schemas, cursor, err := u.metadataStore.ListFiles(request.UserId, request.Limit, request.Cursor, request.Order != "desc")
+	if errors.Is(err, metadata.ErrInvalidCursor) {
+		abortWithBadRequest(c, "invalid cursor", err.Error())
+		return
+	}
+	if err != nil {
+		logger.Error("error listing files",
+			zap.String("userId", request.UserId),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error listing files")
+		return
+	}
+
+	response := &views.ListFilesResponse{
+		Items:  []*views.ListFilesItem{},
+		Cursor: cursor,
+	}
+
+	semaphore := make(chan struct{}, maxSigningConcurrency)
+
+	var wg sync.WaitGroup
+	for _, schema := range schemas {
+		if schema.Status == views.StatusTrashed {
+			continue
+		}
+
+		item := schema.ToListFilesItem()
+		response.Items = append(response.Items, item)
+		if schema.Status == views.StatusQuarantined || item.Encrypted || len(schema.DefinitionsMap) == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func(item *views.ListFilesItem) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			objectName := utils.GetClosestPrefix(item.DefinitionsMap, request.Definition)
+
+			signedUrlResponse, err := u.signObject(c, objectName, storage.SignPolicy{})
+			if err != nil {
+				logger.Error("error getting signed object", zap.String("prefix", objectName), zap.Error(err))
+				return
+			}
+
+			if !signedUrlResponse.Temporary && !signedUrlResponse.Quarantined && !signedUrlResponse.Trashed && !signedUrlResponse.Encrypted {
+				item.SignedURL = signedUrlResponse
+			}
+		}(item)
 	}
 
 	wg.Wait()
 
-	return response
+	c.JSON(http.StatusOK, response)
 }
 
 // Upload godoc
-// @Summary Delete file
-// @Description Deletes the file
+// @Summary Get file text
+// @Description Returns the text extracted from the file (OCR or document text), with the lines bounding boxes
 // @Tags Upload
 // @Param prefix query string true "File folder prefix"
 // @Produce json
-// @Success 200 {string} OK
+// @Success 200 {object} views.TextDocument
 // @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
 // @Failure 500
 // @Header all {string} X-Request-Id "Request ID (UUID)"
-// @Router /upload [delete]
-func (u *UploadController) Delete(c *gin.Context) {
-	prefix := c.Request.URL.Query().Get("prefix")
-	userId, depth := utils.GetPrefixFolder(prefix)
-
-	if prefix == "" || !utils.CheckPrefixIsFolder(prefix) || depth != 1 {
-		abortWithBadRequest(c, "the file prefix is required", "you must provide a valid file prefix")
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/text [get]
+func (u *UploadController) GetText(c *gin.Context) {
+	prefix, userId, ok := readFilePrefix(c)
+	if !ok {
 		return
 	}
 
-	schema := &views.DynamoDBUploadSchema{
-		UserId: userId,
-		Prefix: prefix,
-	}
-	err := u.awsRepository.GetTableRow(u.tableName, schema)
+	schema, err := u.metadataStore.GetFile(userId, prefix)
 	if err != nil {
 		logger.Error("error retrieving prefix info from DB",
 			zap.Any("prefix", prefix),
@@ -521,64 +1605,449 @@ func (u *UploadController) Delete(c *gin.Context) {
 		return
 	}
 
-	for _, filePrefix := range schema.DefinitionsMap {
-		err = u.awsRepository.DeleteObject(filePrefix)
+	if schema.Status == views.StatusTrashed {
+		abortWithNotFound(c, "prefix not found", "the file is in the trash")
+		return
+	}
+
+	if schema.Status == views.StatusQuarantined {
+		abortWithForbidden(c, "quarantined file", "the file is under moderation review")
+		return
+	}
+
+	if schema.TextObject == "" {
+		abortWithNotFound(c, "file text not found", "no text was extracted from the file")
+		return
+	}
+
+	reader, err := u.blobStore.DownloadFile(schema.TextObject)
+	if err != nil {
+		if storage.CheckIsNotFoundError(err) {
+			abortWithNotFound(c, "file text not found")
+			return
+		}
+
+		abortWithBadRequest(c, "error getting file text")
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, -1, gin.MIMEJSON, reader, nil)
+}
+
+// Upload godoc
+// @Summary Search files
+// @Description Returns the user files matching all the filters. Labels and text are searched in the labels index. A page may have fewer items than the limit, the search continues while there's a cursor.
+// @Tags Upload
+// @Param userId query string true "User Identifier"
+// @Param label query []string false "File labels" collectionFormat(multi)
+// @Param text query string false "Text terms, from OCR or documents text"
+// @Param title query string false "Title substring"
+// @Param author query string false "Author substring"
+// @Param contentType query string false "File content type"
+// @Param from query string false "Min occurredOn date (RFC 3339)"
+// @Param to query string false "Max occurredOn date (RFC 3339)"
+// @Param limit query int false "Page size (max 100)"
+// @Param cursor query string false "Page cursor"
+// @Produce json
+// @Success 200 {object} views.SearchResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/search [get]
+func (u *UploadController) Search(c *gin.Context) {
+	request := &views.SearchRequest{}
+	if err := http_utils.ReadQueryParam(c, request); err != nil {
+		abortWithBadRequest(c, "error reading request", err.Error())
+		return
+	}
+
+	if err := utils.Validate.Struct(request); err != nil {
+		abortWithBadRequest(c, "error validating data", utils.FormatValidatorErrors(err)...)
+		return
+	}
+
+	if !checkOwner(c, request.UserId) {
+		return
+	}
+
+	if request.Limit == 0 {
+		request.Limit = defaultSearchLimit
+	}
+
+	response := &views.SearchResponse{
+		Items:  []*views.FileResponse{},
+		Cursor: request.Cursor,
+	}
+
+	// The pages read are limited, so the searches matching few files don't read all the user files.
+	for page := 0; page < maxSearchPages; page++ {
+		remaining := request.Limit - int32(len(response.Items))
+
+		schemas, cursor, err := u.searchPage(request, remaining, response.Cursor)
+		if errors.Is(err, metadata.ErrIndexNotConfigured) {
+			abortWithBadRequest(c, "label search is not available", err.Error())
+			return
+		}
 		if err != nil {
-			logger.Error("error deleting object storage",
-				zap.Any("prefix", prefix),
+			logger.Error("error searching files",
+				zap.String("userId", request.UserId),
 				zap.Error(err),
 			)
+			abortWithBadRequest(c, "error searching files")
+			return
+		}
+
+		for _, schema := range schemas {
+			if schema.Status != views.StatusQuarantined && schema.Status != views.StatusTrashed && request.Matches(schema) {
+				response.Items = append(response.Items, schema.ToFileResponse())
+			}
+		}
+
+		response.Cursor = cursor
+		if cursor == "" || int32(len(response.Items)) >= request.Limit {
+			break
 		}
 	}
 
-	u.awsRepository.DelTableRow(u.tableName, schema)
+	c.JSON(http.StatusOK, response)
+}
+
+// searchPage returns a page of the user files that may match the search.
+// When labels are searched, the first label is looked up in the labels index.
+// Otherwise, when text is searched, the first text term is looked up.
+func (u *UploadController) searchPage(request *views.SearchRequest, limit int32, cursor string) ([]*views.DynamoDBUploadSchema, string, error) {
+	var indexKey string
+	if len(request.Labels) > 0 {
+		indexKey = views.LabelIndexKey(request.Labels[0], "")
+	} else if terms := request.TextTerms(); len(terms) > 0 {
+		indexKey = views.TextIndexKey(terms[0], "")
+	} else {
+		return u.metadataStore.ListFiles(request.UserId, limit, cursor, true)
+	}
+
+	indexSchemas, nextCursor, err := u.metadataStore.QueryIndex(request.UserId, indexKey, limit, cursor)
 	if err != nil {
-		logger.Error("error deleting prefix info from DB",
-			zap.Any("prefix", prefix),
+		return nil, "", err
+	}
+
+	schemas := make([]*views.DynamoDBUploadSchema, len(indexSchemas))
+
+	var wg sync.WaitGroup
+	for i, indexSchema := range indexSchemas {
+		wg.Add(1)
+		go func(i int, prefix string) {
+			defer wg.Done()
+			schema, err := u.metadataStore.GetFile(request.UserId, prefix)
+			if err != nil {
+				logger.Warn("indexed prefix not found in DB",
+					zap.String("prefix", prefix),
+					zap.Error(err),
+				)
+				return
+			}
+
+			schemas[i] = schema
+		}(i, indexSchema.Prefix)
+	}
+	wg.Wait()
+
+	found := []*views.DynamoDBUploadSchema{}
+	for _, schema := range schemas {
+		if schema != nil {
+			found = append(found, schema)
+		}
+	}
+
+	return found, nextCursor, nil
+}
+
+// Upload godoc
+// @Summary Delete file
+// @Description Moves the file to the trash. It's purged after the trash retention period.
+// @Tags Upload
+// @Param prefix query string true "File folder prefix"
+// @Produce json
+// @Success 200 {string} OK
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload [delete]
+func (u *UploadController) Delete(c *gin.Context) {
+	prefix, userId, ok := readFilePrefix(c)
+	if !ok {
+		return
+	}
+
+	schema, err := u.metadataStore.GetFile(userId, prefix)
+	if err != nil || schema.Status == views.StatusTrashed {
+		abortWithNotFound(c, "prefix not found")
+		return
+	}
+
+	err = trashFile(c, u.blobStore, u.metadataStore, u.cacheControl, schema)
+	if err != nil {
+		logger.Error("error moving file to trash",
+			zap.String("prefix", prefix),
 			zap.Error(err),
 		)
+		abortWithBadRequest(c, "error deleting file")
+		return
 	}
-	u.cacheControl.RemoveKeyFromCachedPrefixes(c, prefix)
 
 	c.String(http.StatusOK, "OK")
 }
 
 // Upload godoc
 // @Summary Delete all
-// @Description Deletes all files from prefix
+// @Description Moves all the user files to the trash
 // @Tags Upload
-// @Param prefix query string true "File folder prefix"
+// @Param prefix query string true "User folder prefix"
 // @Produce json
 // @Success 200 {string} OK
 // @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
 // @Failure 500
 // @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /upload/all [delete]
 func (u *UploadController) DeleteAll(c *gin.Context) {
-	prefix := c.Request.URL.Query().Get("prefix")
-	if prefix == "" || !utils.CheckPrefixIsFolder(prefix) {
-		abortWithBadRequest(c, "the file prefix is required", "you must provide a valid file prefix")
+	userId := strings.Trim(c.Request.URL.Query().Get("prefix"), "/")
+	if err := utils.Validate.Var(userId, "required,uuid"); err != nil {
+		abortWithBadRequest(c, "the file prefix is required", "you must provide a valid user folder prefix")
 		return
 	}
 
-	prefixes := u.getFolderPrefixesFullDepth(c, prefix)
-	if len(prefixes) > 0 {
-		// the prefix must be a valid userId otherwise will not exclude.
-		err := u.awsRepository.DelTablePartition(u.tableName, "userId", prefix, &views.DynamoDBUploadSchema{})
+	if !checkOwner(c, userId) {
+		return
+	}
+
+	schemas, _, err := u.metadataStore.ListFiles(userId, 0, "", true)
+	if err != nil {
+		abortWithBadRequest(c, "error listing files information", err.Error())
+		return
+	}
+
+	for _, schema := range schemas {
+		if schema.Status == views.StatusTrashed {
+			continue
+		}
+
+		err := trashFile(c, u.blobStore, u.metadataStore, u.cacheControl, schema)
 		if err != nil {
-			abortWithBadRequest(c, "error deleting files information", err.Error())
+			logger.Error("error moving file to trash",
+				zap.String("prefix", schema.Prefix),
+				zap.Error(err),
+			)
+			abortWithBadRequest(c, "error deleting files")
 			return
 		}
-		err = u.awsRepository.DeleteMany(prefixes)
+	}
+
+	u.cacheControl.PrefixesCacheControl.Del(c, userId)
+
+	c.String(http.StatusOK, "OK")
+}
+
+// Upload godoc
+// @Summary List trash
+// @Description Returns the user files in the trash
+// @Tags Upload
+// @Param userId query string true "User Identifier"
+// @Produce json
+// @Success 200 {object} []views.FileResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/trash [get]
+func (u *UploadController) ListTrash(c *gin.Context) {
+	userId := c.Request.URL.Query().Get("userId")
+	if err := utils.Validate.Var(userId, "required,uuid"); err != nil {
+		abortWithBadRequest(c, "the userId is required", "you must provide a valid userId")
+		return
+	}
+
+	if !checkOwner(c, userId) {
+		return
+	}
+
+	schemas, err := u.metadataStore.ListFilesByStatus(userId, views.StatusTrashed)
+	if err != nil {
+		logger.Error("error listing trashed files",
+			zap.String("userId", userId),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error listing trashed files")
+		return
+	}
+
+	response := []*views.FileResponse{}
+	for _, schema := range schemas {
+		response = append(response, schema.ToFileResponse())
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Upload godoc
+// @Summary Restore file
+// @Description Restores the file from the trash
+// @Tags Upload
+// @Param prefix query string true "File folder prefix"
+// @Produce json
+// @Success 200 {object} views.FileResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/restore [post]
+func (u *UploadController) Restore(c *gin.Context) {
+	prefix, userId, ok := readFilePrefix(c)
+	if !ok {
+		return
+	}
+
+	schema, err := u.metadataStore.GetFile(userId, prefix)
+	if err != nil {
+		abortWithNotFound(c, "prefix not found")
+		return
+	}
+
+	if schema.Status != views.StatusTrashed {
+		abortWithBadRequest(c, "file is not in the trash")
+		return
+	}
+
+	var objects []string
+	for _, objectName := range schema.DefinitionsMap {
+		objects = append(objects, objectName)
+		err := u.blobStore.RemoveObjectTags(objectName, storage.TrashTag)
 		if err != nil {
-			abortWithBadRequest(c, "error deleting files", err.Error())
+			logger.Error("error removing trash tag",
+				zap.String("objectName", objectName),
+				zap.Error(err),
+			)
+			abortWithBadRequest(c, "error restoring file")
 			return
 		}
+	}
+
+	schema, err = metadata.UpdateFileFunc(u.metadataStore, userId, prefix, func(file *views.DynamoDBUploadSchema) error {
+		file.Restore()
+
+		// The file goes back to the root if its folder was deleted meanwhile.
+		if file.FolderId != "" {
+			if _, err := u.metadataStore.GetFolder(userId, file.FolderId); errors.Is(err, metadata.ErrFolderNotFound) {
+				file.FolderId = ""
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		logger.Error("error updating file info in DB",
+			zap.String("prefix", prefix),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error restoring file")
+		return
+	}
+
+	u.cacheControl.SignedURLCacheControl.DelMany(c, objects)
+	u.cacheControl.PrefixesCacheControl.Del(c, userId)
+
+	c.JSON(http.StatusOK, schema.ToFileResponse())
+}
+
+// trashFile moves the file to the trash, tagging its objects for the trash lifecycle rule.
+func trashFile(
+	c context.Context,
+	blobStore storage.BlobStore,
+	metadataStore metadata.MetadataStore,
+	cacheControl *cache_control.UploadCacheControl,
+	schema *views.DynamoDBUploadSchema,
+) error {
+	var objects []string
+	for _, objectName := range schema.DefinitionsMap {
+		objects = append(objects, objectName)
+		err := blobStore.AddObjectTags(objectName, map[string]string{
+			storage.TrashTag: "true",
+		})
+		if err != nil {
+			return err
+		}
+	}
 
-		u.cacheControl.RemoveFolderFromCache(c, prefix, prefixes)
+	trashedOn := time.Now().UTC()
+	updated, err := metadata.UpdateFileFunc(metadataStore, schema.UserId, schema.Prefix, func(file *views.DynamoDBUploadSchema) error {
+		file.Trash(trashedOn)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	*schema = *updated
 
-	c.String(http.StatusOK, "OK")
+	cacheControl.SignedURLCacheControl.DelMany(c, objects)
+	cacheControl.RemoveKeyFromCachedPrefixes(c, schema.Prefix)
+
+	return nil
+}
+
+// readFilePrefix reads and validates the file prefix query param.
+// It returns the prefix and the user identifier or aborts with bad request.
+func readFilePrefix(c *gin.Context) (string, string, bool) {
+	prefix := c.Request.URL.Query().Get("prefix")
+	userId, depth := utils.GetPrefixFolder(prefix)
+
+	if prefix == "" || !utils.CheckPrefixIsFolder(prefix) || depth != 1 {
+		abortWithBadRequest(c, "the file prefix is required", "you must provide a valid file prefix")
+		return "", "", false
+	}
+
+	if !checkOwner(c, userId) {
+		return "", "", false
+	}
+
+	return prefix, userId, true
+}
+
+// checkOwner checks the request principal can access the user files. It aborts the request when false is returned.
+// The requests pass when the authentication is disabled, or the principal is an admin or a service API key.
+func checkOwner(c *gin.Context, userId string) bool {
+	principal, ok := http_utils.GetPrincipal(c)
+	if !ok || principal.Admin || principal.ApiKeyId != "" || principal.Subject == userId {
+		return true
+	}
+
+	abortWithForbidden(c, "access denied", "the files belong to another user")
+	return false
+}
+
+// prefixOwner returns the user identifier of the prefix, its first folder.
+func prefixOwner(prefix string) string {
+	userId, _, _ := strings.Cut(strings.Trim(prefix, "/"), "/")
+
+	return userId
 }
 
 // abortWithBadRequest aborts the request with a bad request error.
@@ -589,6 +2058,46 @@ func abortWithBadRequest(c *gin.Context, message string, description ...string)
 	c.AbortWithStatusJSON(fmtErr.Status(), fmtErr)
 }
 
+// abortWithUnauthorized aborts the request with an unauthorized error.
+func abortWithUnauthorized(c *gin.Context, message string, description ...string) {
+	fmtErr := http_utils.NewUnauthorizedError(message, description...)
+
+	c.Error(fmtErr)
+	c.AbortWithStatusJSON(fmtErr.Status(), fmtErr)
+}
+
+// abortWithForbidden aborts the request with a forbidden error.
+func abortWithForbidden(c *gin.Context, message string, description ...string) {
+	fmtErr := http_utils.NewForbiddenError(message, description...)
+
+	c.Error(fmtErr)
+	c.AbortWithStatusJSON(fmtErr.Status(), fmtErr)
+}
+
+// abortWithConflict aborts the request with a conflict error.
+func abortWithConflict(c *gin.Context, message string, description ...string) {
+	fmtErr := http_utils.NewConflictError(message, description...)
+
+	c.Error(fmtErr)
+	c.AbortWithStatusJSON(fmtErr.Status(), fmtErr)
+}
+
+// abortWithRequestEntityTooLarge aborts the request with a request entity too large error.
+func abortWithRequestEntityTooLarge(c *gin.Context, message string, description ...string) {
+	fmtErr := http_utils.NewRequestEntityTooLargeError(message, description...)
+
+	c.Error(fmtErr)
+	c.AbortWithStatusJSON(fmtErr.Status(), fmtErr)
+}
+
+// abortWithGone aborts the request with a gone error.
+func abortWithGone(c *gin.Context, message string, description ...string) {
+	fmtErr := http_utils.NewGoneError(message, description...)
+
+	c.Error(fmtErr)
+	c.AbortWithStatusJSON(fmtErr.Status(), fmtErr)
+}
+
 // abortWithNotFound aborts the request with a not found error.
 func abortWithNotFound(c *gin.Context, message string, description ...string) {
 	fmtErr := http_utils.NewNotFoundError(message, description...)
@@ -596,3 +2105,11 @@ func abortWithNotFound(c *gin.Context, message string, description ...string) {
 	c.Error(fmtErr)
 	c.AbortWithStatusJSON(fmtErr.Status(), fmtErr)
 }
+
+// abortWithInternalServerError aborts the request with an internal server error.
+func abortWithInternalServerError(c *gin.Context, message string, description ...string) {
+	fmtErr := http_utils.NewInternalServerError(message, description...)
+
+	c.Error(fmtErr)
+	c.AbortWithStatusJSON(fmtErr.Status(), fmtErr)
+}
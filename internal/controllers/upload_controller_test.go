@@ -0,0 +1,257 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/internal/server"
+	"github.com/gearpoint/filepoint/internal/tenants"
+	"github.com/gearpoint/filepoint/internal/views"
+	http_utils "github.com/gearpoint/filepoint/pkg/http"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// newUploadRequest returns an upload request with the file content.
+func newUploadRequest(t *testing.T, userId string, contentType string, content string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	assert.Nil(t, writer.WriteField("userId", userId))
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="content"; filename="file"`)
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	assert.Nil(t, err)
+	_, err = part.Write([]byte(content))
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+
+	req, err := http.NewRequest("POST", "/v1/upload", body)
+	assert.Nil(t, err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return req
+}
+
+func TestTenantUploadRoute(t *testing.T) {
+	signer := storage.NewHMACSigner("/v1/files", "secret")
+	store, err := storage.NewFileSystemStore(t.TempDir(), signer)
+	assert.Nil(t, err)
+	metadataStore := metadata.NewMemoryStore()
+	acmeMetadataStore := metadata.NewMemoryStore()
+	acmeStore := storage.NewPrefixedStore(store, "acme")
+
+	registry := tenants.NewRegistry(&tenants.Tenant{BlobStore: store, MetadataStore: metadataStore})
+	registry.Add(&tenants.Tenant{
+		Id:                "acme",
+		BlobStore:         acmeStore,
+		ObjectStore:       store,
+		KeyPrefix:         "acme",
+		MetadataStore:     acmeMetadataStore,
+		AllowedStrategies: []string{"image"},
+		MaxFileSize:       4,
+	}, "acme-key")
+
+	userId := uuid.NewString()
+	files := map[string]metadata.MetadataStore{}
+	for blobStore, filesMetadata := range map[storage.BlobStore]metadata.MetadataStore{store: metadataStore, acmeStore: acmeMetadataStore} {
+		prefix := utils.GetUniquePrefix(userId)
+		objectName := prefix + "/original.txt"
+		files[prefix] = filesMetadata
+
+		err = blobStore.PutObject(objectName, strings.NewReader("content"), "text/plain", nil, nil)
+		assert.Nil(t, err)
+		assert.Nil(t, filesMetadata.PutFile(&views.DynamoDBUploadSchema{
+			UserId:         userId,
+			Prefix:         prefix,
+			Status:         views.StatusActive,
+			DefinitionsMap: utils.FileDefinitionsMapping{utils.MediumDef: objectName},
+		}))
+	}
+
+	s := server.NewServer(server.ServerConfig{BlobStore: store, MetadataStore: metadataStore, Tenants: registry})
+	s.MapHandlers()
+
+	for prefix, filesMetadata := range files {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/v1/upload/download?prefix="+prefix, nil)
+		assert.Nil(t, err)
+		req.Header.Set("Authorization", "ApiKey acme-key")
+		s.Engine.ServeHTTP(w, req)
+
+		if filesMetadata == acmeMetadataStore {
+			assert.Equal(t, 200, w.Code)
+			assert.Equal(t, "content", w.Body.String())
+
+			w = httptest.NewRecorder()
+			req, err = http.NewRequest("GET", signer.Sign("acme/"+prefix+"/original.txt", time.Now().Add(time.Hour)), nil)
+			assert.Nil(t, err)
+			s.Engine.ServeHTTP(w, req)
+
+			assert.Equal(t, 200, w.Code)
+		} else {
+			assert.NotEqual(t, 200, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/v1/upload/files?userId="+userId, nil)
+	assert.Nil(t, err)
+	req.Header.Set("Authorization", "ApiKey other-key")
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 401, w.Code)
+
+	w = httptest.NewRecorder()
+	req = newUploadRequest(t, userId, "text/plain", "text")
+	req.Header.Set("Authorization", "ApiKey acme-key")
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 400, w.Code)
+
+	w = httptest.NewRecorder()
+	req = newUploadRequest(t, userId, "image/png", "large image")
+	req.Header.Set("Authorization", "ApiKey acme-key")
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 413, w.Code)
+}
+
+func TestOwnerAuthRoute(t *testing.T) {
+	store, err := storage.NewFileSystemStore(t.TempDir(), storage.NewHMACSigner("/v1/files", "secret"))
+	assert.Nil(t, err)
+	metadataStore := metadata.NewMemoryStore()
+
+	s := server.NewServer(server.ServerConfig{
+		BlobStore:     store,
+		MetadataStore: metadataStore,
+		AuthConfig:    config.AuthConfig{AdminScope: "admin"},
+		KeyFunc:       http_utils.NewHS256KeyFunc([]byte("jwt-secret")),
+	})
+	s.MapHandlers()
+
+	owner := uuid.NewString()
+	prefix := utils.GetUniquePrefix(owner)
+	objectName := prefix + "/original.txt"
+	assert.Nil(t, store.PutObject(objectName, strings.NewReader("content"), "text/plain", nil, nil))
+	assert.Nil(t, metadataStore.PutFile(&views.DynamoDBUploadSchema{
+		UserId:         owner,
+		Prefix:         prefix,
+		Status:         views.StatusActive,
+		DefinitionsMap: utils.FileDefinitionsMapping{utils.MediumDef: objectName},
+	}))
+
+	request := func(method string, url string, claims jwt.MapClaims, body string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		assert.Nil(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		if claims != nil {
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("jwt-secret"))
+			assert.Nil(t, err)
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		w := httptest.NewRecorder()
+		s.Engine.ServeHTTP(w, req)
+
+		return w
+	}
+
+	ownerClaims := jwt.MapClaims{"sub": owner}
+	otherClaims := jwt.MapClaims{"sub": uuid.NewString()}
+	adminClaims := jwt.MapClaims{"sub": uuid.NewString(), "scope": "admin"}
+
+	w := request("GET", "/v1/upload/download?prefix="+prefix, nil, "")
+	assert.Equal(t, 401, w.Code)
+
+	w = request("GET", "/v1/upload/download?prefix="+prefix, ownerClaims, "")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "content", w.Body.String())
+
+	w = request("GET", "/v1/upload/download?prefix="+prefix, otherClaims, "")
+	assert.Equal(t, 403, w.Code)
+
+	w = request("GET", "/v1/upload/files?userId="+owner, otherClaims, "")
+	assert.Equal(t, 403, w.Code)
+
+	w = request("DELETE", "/v1/upload?prefix="+prefix, otherClaims, "")
+	assert.Equal(t, 403, w.Code)
+
+	w = request("GET", "/v1/upload/download?prefix="+prefix, adminClaims, "")
+	assert.Equal(t, 200, w.Code)
+
+	w = request("GET", "/v1/admin/quarantine?userId="+owner, otherClaims, "")
+	assert.Equal(t, 403, w.Code)
+
+	transfer := fmt.Sprintf(`{"userId":%q}`, otherClaims["sub"])
+
+	w = request("POST", "/v1/upload/copy?prefix="+prefix, ownerClaims, transfer)
+	assert.Equal(t, 403, w.Code)
+
+	w = request("POST", "/v1/upload/move?prefix="+prefix, ownerClaims, transfer)
+	assert.Equal(t, 403, w.Code)
+}
+
+func TestSearchRoute(t *testing.T) {
+	store, err := storage.NewFileSystemStore(t.TempDir(), storage.NewHMACSigner("/v1/files", "secret"))
+	assert.Nil(t, err)
+	metadataStore := metadata.NewMemoryStore()
+
+	s := server.NewServer(server.ServerConfig{BlobStore: store, MetadataStore: metadataStore})
+	s.MapHandlers()
+
+	userId := uuid.NewString()
+	for i := 0; i < 30; i++ {
+		title := "Other"
+		if i == 29 {
+			title = "Invoice"
+		}
+
+		assert.Nil(t, metadataStore.PutFile(&views.DynamoDBUploadSchema{
+			UserId: userId,
+			Prefix: fmt.Sprintf("%s/%02d", userId, i),
+			Title:  title,
+			Status: views.StatusActive,
+		}))
+	}
+
+	var found []*views.FileResponse
+	var requests int
+	cursor := ""
+	for {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/v1/upload/search?title=invoice&limit=5&userId="+userId+"&cursor="+cursor, nil)
+		assert.Nil(t, err)
+		s.Engine.ServeHTTP(w, req)
+		assert.Equal(t, 200, w.Code)
+		requests++
+
+		response := &views.SearchResponse{}
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), response))
+		found = append(found, response.Items...)
+
+		cursor = response.Cursor
+		if cursor == "" {
+			break
+		}
+	}
+
+	assert.Len(t, found, 1)
+	assert.Equal(t, "Invoice", found[0].Title)
+	assert.Equal(t, 2, requests)
+}
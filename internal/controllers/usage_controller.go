@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/internal/usage"
+	"github.com/gearpoint/filepoint/pkg/logger"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// UsageController is the controller for the storage usage methods.
+type UsageController struct {
+	metadataStore metadata.MetadataStore
+	quota         config.QuotaConfig
+}
+
+// NewUsageController returns a new UsageController instance.
+func NewUsageController(cfg *UploadConfig) *UsageController {
+	return &UsageController{
+		metadataStore: cfg.MetadataStore,
+		quota:         cfg.Quota,
+	}
+}
+
+// Usage godoc
+// @Summary Get storage usage
+// @Description Returns the processed files bytes and count, by upload strategy, with the quota limits. Returns the tenant usage without the userId.
+// @Tags Usage
+// @Param userId query string false "User Identifier"
+// @Produce json
+// @Success 200 {object} views.UsageResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /usage [get]
+func (u *UsageController) Get(c *gin.Context) {
+	userId := c.Request.URL.Query().Get("userId")
+	if err := utils.Validate.Var(userId, "omitempty,uuid"); err != nil {
+		abortWithBadRequest(c, "invalid userId", "you must provide a valid userId")
+		return
+	}
+
+	// The tenant usage is only returned to the admins and API keys.
+	if !checkOwner(c, userId) {
+		return
+	}
+
+	counterId, maxBytes, maxFiles := usage.TenantUserId, u.quota.TenantMaxBytes, u.quota.TenantMaxFiles
+	if userId != "" {
+		counterId, maxBytes, maxFiles = userId, u.quota.UserMaxBytes, u.quota.UserMaxFiles
+	}
+
+	response, err := usage.Get(u.metadataStore, counterId)
+	if err != nil {
+		if errors.Is(err, metadata.ErrUsageNotConfigured) {
+			abortWithBadRequest(c, "error retrieving usage", err.Error())
+			return
+		}
+
+		logger.Error("error retrieving usage",
+			zap.String("userId", userId),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error retrieving usage")
+		return
+	}
+
+	response.MaxBytes = maxBytes
+	response.MaxFiles = maxFiles
+
+	c.JSON(http.StatusOK, response)
+}
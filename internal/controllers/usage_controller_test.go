@@ -0,0 +1,108 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/internal/server"
+	"github.com/gearpoint/filepoint/internal/tenants"
+	"github.com/gearpoint/filepoint/internal/usage"
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageRoute(t *testing.T) {
+	store, err := storage.NewFileSystemStore(t.TempDir(), storage.NewHMACSigner("/v1/files", "secret"))
+	assert.Nil(t, err)
+	metadataStore := metadata.NewMemoryStore()
+
+	s := server.NewServer(server.ServerConfig{
+		BlobStore:     store,
+		MetadataStore: metadataStore,
+		Tenants: tenants.NewRegistry(&tenants.Tenant{
+			BlobStore:     store,
+			MetadataStore: metadataStore,
+			Quota:         config.QuotaConfig{UserMaxBytes: 100, TenantMaxFiles: 10},
+		}),
+	})
+	s.MapHandlers()
+
+	userId := uuid.NewString()
+	assert.Nil(t, usage.AddFile(metadataStore, &views.DynamoDBUploadSchema{UserId: userId, Size: 60, Strategy: "image"}))
+	assert.Nil(t, usage.AddFile(metadataStore, &views.DynamoDBUploadSchema{UserId: userId, Size: 30, Strategy: "file"}))
+	assert.Nil(t, usage.AddFile(metadataStore, &views.DynamoDBUploadSchema{UserId: uuid.NewString(), Size: 10, Strategy: "file"}))
+
+	request := func(url string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", url, nil)
+		assert.Nil(t, err)
+
+		w := httptest.NewRecorder()
+		s.Engine.ServeHTTP(w, req)
+
+		return w
+	}
+
+	w := request("/v1/usage?userId=invalid")
+	assert.Equal(t, 400, w.Code)
+
+	w = request("/v1/usage?userId=" + userId)
+	assert.Equal(t, 200, w.Code)
+
+	response := &views.UsageResponse{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), response))
+	assert.Equal(t, &views.UsageResponse{
+		UserId:   userId,
+		Bytes:    90,
+		Files:    2,
+		MaxBytes: 100,
+		Strategies: map[string]*views.StrategyUsage{
+			"file":  {Bytes: 30, Files: 1},
+			"image": {Bytes: 60, Files: 1},
+		},
+	}, response)
+
+	w = request("/v1/usage")
+	assert.Equal(t, 200, w.Code)
+
+	response = &views.UsageResponse{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), response))
+	assert.Empty(t, response.UserId)
+	assert.Equal(t, int64(100), response.Bytes)
+	assert.Equal(t, int64(3), response.Files)
+	assert.Equal(t, int64(10), response.MaxFiles)
+	assert.Equal(t, &views.StrategyUsage{Bytes: 40, Files: 2}, response.Strategies["file"])
+
+	w = httptest.NewRecorder()
+	s.Engine.ServeHTTP(w, newUploadRequest(t, userId, "image/png", "more than ten bytes"))
+
+	assert.Equal(t, 413, w.Code)
+	assert.Contains(t, w.Body.String(), "the user storage quota has 10 bytes left")
+
+	otherUserId := uuid.NewString()
+	prefix := utils.GetUniquePrefix(otherUserId)
+	assert.Nil(t, metadataStore.PutFile(&views.DynamoDBUploadSchema{
+		UserId:         otherUserId,
+		Prefix:         prefix,
+		Status:         views.StatusActive,
+		Size:           5,
+		Versions:       []views.FileVersion{{Version: 1, Size: 20}},
+		DefinitionsMap: utils.FileDefinitionsMapping{utils.MediumDef: prefix + "/original.txt"},
+	}))
+
+	w = httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/v1/upload/move?prefix="+prefix, strings.NewReader(fmt.Sprintf(`{"userId":%q}`, userId)))
+	assert.Nil(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 413, w.Code)
+}
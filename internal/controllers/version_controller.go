@@ -0,0 +1,405 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gearpoint/filepoint/internal/sender_handlers"
+	"github.com/gearpoint/filepoint/internal/uploader"
+	"github.com/gearpoint/filepoint/internal/uploader/strategies"
+	"github.com/gearpoint/filepoint/internal/uploader/strategies/archive_type"
+	"github.com/gearpoint/filepoint/internal/views"
+	http_utils "github.com/gearpoint/filepoint/pkg/http"
+	"github.com/gearpoint/filepoint/pkg/logger"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// VersionController is the controller for the file content versions methods.
+// The new contents are published and processed like the uploads.
+type VersionController struct {
+	webhookURL    string
+	tagKeys       []string
+	metadataStore metadata.MetadataStore
+	uploads       *UploadController
+}
+
+// NewVersionController returns a new VersionController instance.
+func NewVersionController(cfg *UploadConfig) *VersionController {
+	return &VersionController{
+		webhookURL:    cfg.RouteConfig.WebhookURL,
+		tagKeys:       cfg.RouteConfig.TaggedMetadataKeys,
+		metadataStore: cfg.MetadataStore,
+		uploads:       NewUploadController(cfg),
+	}
+}
+
+// ReplaceContent godoc
+// @Summary Replace file content
+// @Description Processes the new content into the same file, as a new version. The current content is kept in the file versions.
+// @Description The upload webhook is sent when the new version is processed.
+// @Tags Versions
+// @Accept multipart/form-data
+// @Param prefix query string true "File folder prefix"
+// @Param content formData file true "New file content"
+// @Produce json
+// @Success 202 {object} views.ReplaceContentResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 409 {object} http_utils.RestError
+// @Failure 413 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/content [put]
+func (v *VersionController) ReplaceContent(c *gin.Context) {
+	schema, ok := v.getVersionedFile(c)
+	if !ok {
+		return
+	}
+
+	fileHeader, err := http_utils.ReadRequestFile(c, ContentField)
+	if err != nil {
+		abortWithBadRequest(c, "error getting file contents", err.Error())
+		return
+	}
+
+	contentType, err := utils.GetFileContentType(fileHeader.Header)
+	if err != nil {
+		abortWithBadRequest(c, "error getting file content type", err.Error())
+		return
+	}
+
+	eventType, uploader, err := uploader.GetUploaderByContentType(contentType)
+	if err != nil {
+		abortWithBadRequest(c, "error validating file content type", err.Error())
+		return
+	}
+	if eventType == archive_type.Key {
+		abortWithBadRequest(c, "error validating file content type", "archives can't replace a file content")
+		return
+	}
+
+	if !v.uploads.checkTenantLimits(c, eventType, fileHeader.Size) {
+		return
+	}
+
+	if !v.uploads.checkQuota(c, schema.UserId, fileHeader.Size, 0) {
+		return
+	}
+
+	version := schema.Version
+	contentVersion := schema.NextContentVersion()
+
+	uploadPubSub := &views.UploadPubSub{
+		Id:             http_utils.GetRequestId(c),
+		UserId:         schema.UserId,
+		Author:         schema.Author,
+		Title:          schema.Title,
+		CorrelationId:  schema.CorrelationId,
+		Metadata:       schema.Metadata,
+		Filename:       fileHeader.Filename,
+		ContentType:    contentType,
+		Size:           fileHeader.Size,
+		IpAddress:      http_utils.GetIPAddress(c),
+		OccurredOn:     time.Now(),
+		ContentVersion: contentVersion,
+	}
+
+	uploader.SetConfig(&strategies.UploaderConfig{
+		UploadView:    uploadPubSub,
+		AWSRepository: v.uploads.awsRepository,
+		BlobStore:     v.uploads.blobStore,
+		Prefix:        views.VersionPrefix(schema.Prefix, contentVersion),
+	})
+
+	err = uploader.Validate(uploadPubSub)
+	if err != nil {
+		errSlice := utils.FormatValidatorErrors(err)
+		if errSlice != nil {
+			abortWithBadRequest(c, "error validating data", errSlice...)
+			return
+		}
+	}
+
+	uploadPubSub.Tags, err = views.MetadataTags(uploadPubSub.Metadata, v.tagKeys)
+	if err != nil {
+		abortWithBadRequest(c, "error validating metadata", err.Error())
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		abortWithBadRequest(c, "error reading file", err.Error())
+		return
+	}
+
+	// The version number is reserved before the content is processed.
+	err = v.metadataStore.UpdateFileIfVersion(schema, version)
+	if errors.Is(err, metadata.ErrVersionConflict) {
+		file.Close()
+		abortWithConflict(c, "file version conflict", "the file was changed, try again")
+		return
+	}
+	if err != nil {
+		file.Close()
+		logger.Error("error updating file info in DB",
+			zap.String("prefix", schema.Prefix),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error saving file information")
+		return
+	}
+
+	go v.uploads.uploadWorker(eventType, uploader, schema.Prefix, file)
+
+	// Returns the schema of the webhook content.
+	c.Header("Webhook-Request-Body", fmt.Sprintf("%#v", views.WebhookPayload{
+		Id:            "X-Request-Id",
+		Success:       true,
+		CorrelationId: "",
+		Location:      "{location}",
+		Error:         "",
+	}))
+	c.JSON(http.StatusAccepted, views.ReplaceContentResponse{
+		Version: contentVersion,
+	})
+}
+
+// ListVersions godoc
+// @Summary List file versions
+// @Description Returns the file content versions, with the current one
+// @Tags Versions
+// @Param prefix query string true "File folder prefix"
+// @Produce json
+// @Success 200 {object} views.FileVersionsResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/versions [get]
+func (v *VersionController) ListVersions(c *gin.Context) {
+	schema, ok := v.getVersionedFile(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, schema.ToFileVersionsResponse())
+}
+
+// GetVersionSignedURL godoc
+// @Summary Get file version URL
+// @Description Returns the signed URL of a file content version
+// @Tags Versions
+// @Param prefix query string true "File folder prefix"
+// @Param version query int true "Content version"
+// @Param definition query utils.FileDefinitions false "File definition config"
+// @Param expiresIn query int false "URL lifetime in seconds, within the configured bounds (default 12h)"
+// @Param notBefore query string false "URL start time (RFC 3339)"
+// @Param ipRange query string false "Allowed client IP or CIDR"
+// @Param attachment query bool false "Forces the download with the version filename"
+// @Param filename query string false "Forces the download with the given filename"
+// @Produce json
+// @Success 200 {object} views.GetSignedURLResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/versions/url [get]
+func (v *VersionController) GetVersionSignedURL(c *gin.Context) {
+	schema, ok := v.getVersionedFile(c)
+	if !ok {
+		return
+	}
+
+	fileVersion, ok := v.readFileVersion(c, schema)
+	if !ok {
+		return
+	}
+
+	if len(fileVersion.DefinitionsMap) == 0 {
+		abortWithNotFound(c, "version not found", "the version content isn't processed")
+		return
+	}
+
+	definition := utils.AtoFileDefinitions(c.Request.URL.Query().Get("definition"))
+	objectName := utils.GetClosestPrefix(fileVersion.DefinitionsMap, definition)
+
+	policy, ok := v.uploads.readSignPolicy(c, fileVersion.Filename, objectName)
+	if !ok {
+		return
+	}
+
+	response, err := v.uploads.signObject(c, objectName, policy)
+	if err != nil {
+		if storage.CheckIsNotFoundError(err) {
+			abortWithNotFound(c, "version not found")
+			return
+		}
+
+		if errors.Is(err, storage.ErrUnsupportedPolicy) {
+			abortWithBadRequest(c, "unsupported policy", "the configured URL signer doesn't support the policy")
+			return
+		}
+
+		abortWithBadRequest(c, "error getting signed URL")
+		return
+	}
+
+	if response.Temporary {
+		abortWithBadRequest(c, "temporary file")
+		return
+	}
+
+	if response.Quarantined {
+		abortWithForbidden(c, "quarantined file", "the version is under moderation review")
+		return
+	}
+
+	if response.Encrypted {
+		abortWithForbidden(c, "encrypted file", "the encrypted files can't be signed")
+		return
+	}
+
+	response.CustomMetadata = schema.Metadata
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RollBack godoc
+// @Summary Roll back file version
+// @Description Makes a previous content version current. The replaced content is kept in the file versions. Sends the file.version_restored webhook.
+// @Tags Versions
+// @Param prefix query string true "File folder prefix"
+// @Param version query int true "Content version"
+// @Produce json
+// @Success 200 {object} views.FileResponse
+// @Failure 400 {object} http_utils.RestError
+// @Failure 401 {object} http_utils.RestError
+// @Failure 403 {object} http_utils.RestError
+// @Failure 404 {object} http_utils.RestError
+// @Failure 409 {object} http_utils.RestError
+// @Failure 500
+// @Header all {string} X-Request-Id "Request ID (UUID)"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /upload/versions/rollback [post]
+func (v *VersionController) RollBack(c *gin.Context) {
+	schema, ok := v.getVersionedFile(c)
+	if !ok {
+		return
+	}
+
+	fileVersion, ok := v.readFileVersion(c, schema)
+	if !ok {
+		return
+	}
+
+	if fileVersion.Version == schema.CurrentContentVersion() {
+		abortWithBadRequest(c, "invalid version", "the version is already current")
+		return
+	}
+
+	previous := *schema
+	schema.SetFileVersion(fileVersion)
+
+	err := v.metadataStore.UpdateFileIfVersion(schema, previous.Version)
+	if errors.Is(err, metadata.ErrVersionConflict) {
+		abortWithConflict(c, "file version conflict", "the file was changed, try again")
+		return
+	}
+	if err != nil {
+		logger.Error("error updating file info in DB",
+			zap.String("prefix", schema.Prefix),
+			zap.Error(err),
+		)
+		abortWithBadRequest(c, "error restoring version")
+		return
+	}
+
+	if err := v.metadataStore.DeleteIndex(previous.IndexSchemas()); err != nil {
+		logger.Warn("error deleting the replaced content index",
+			zap.String("prefix", schema.Prefix),
+			zap.Error(err),
+		)
+	}
+	if err := v.metadataStore.PutIndex(schema.IndexSchemas()); err != nil {
+		logger.Warn("error indexing the restored content",
+			zap.String("prefix", schema.Prefix),
+			zap.Error(err),
+		)
+	}
+
+	// The file attributes may have been edited since the version was replaced.
+	for _, objectName := range schema.DefinitionsMap {
+		if err := v.uploads.refreshObjectMetadata(objectName, schema); err != nil {
+			logger.Error("error refreshing object metadata",
+				zap.String("objectName", objectName),
+				zap.Error(err),
+			)
+		}
+	}
+
+	response := schema.ToFileResponse()
+
+	go sender_handlers.SendEventWebhook(context.Background(), &views.EventWebhookPayload{
+		Event:         views.FileVersionRestoredEvent,
+		Id:            http_utils.GetRequestId(c),
+		CorrelationId: schema.CorrelationId,
+		Location:      schema.Prefix,
+		Data:          response,
+	}, v.webhookURL)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// getVersionedFile returns the file of the prefix query. The trashed files versions can't be changed.
+// It aborts the request when false is returned.
+func (v *VersionController) getVersionedFile(c *gin.Context) (*views.DynamoDBUploadSchema, bool) {
+	schema, ok := v.uploads.getFile(c)
+	if !ok {
+		return nil, false
+	}
+
+	if schema.Status == views.StatusTrashed {
+		abortWithNotFound(c, "prefix not found", "the file is in the trash")
+		return nil, false
+	}
+
+	return schema, true
+}
+
+// readFileVersion returns the file version of the version query. It aborts the request when false is returned.
+func (v *VersionController) readFileVersion(c *gin.Context, schema *views.DynamoDBUploadSchema) (views.FileVersion, bool) {
+	version, err := strconv.ParseInt(c.Request.URL.Query().Get("version"), 10, 64)
+	if err != nil || version < 1 {
+		abortWithBadRequest(c, "the version is required", "you must provide a valid version number")
+		return views.FileVersion{}, false
+	}
+
+	fileVersion, ok := schema.GetFileVersion(version)
+	if !ok {
+		abortWithNotFound(c, "version not found")
+		return views.FileVersion{}, false
+	}
+
+	return fileVersion, true
+}
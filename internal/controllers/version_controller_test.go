@@ -0,0 +1,76 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gearpoint/filepoint/internal/server"
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionsRoute(t *testing.T) {
+	store, err := storage.NewFileSystemStore(t.TempDir(), storage.NewHMACSigner("/v1/files", "secret"))
+	assert.Nil(t, err)
+	metadataStore := metadata.NewMemoryStore()
+
+	userId := uuid.NewString()
+	prefix := utils.GetUniquePrefix(userId)
+	assert.Nil(t, metadataStore.PutFile(&views.DynamoDBUploadSchema{
+		UserId:             userId,
+		Prefix:             prefix,
+		Filename:           "second.txt",
+		Status:             views.StatusActive,
+		ContentVersion:     2,
+		LastContentVersion: 2,
+		DefinitionsMap:     utils.FileDefinitionsMapping{utils.MediumDef: prefix + "/v2/medium.txt"},
+		Versions: []views.FileVersion{{
+			Version:        1,
+			Filename:       "first.txt",
+			Status:         views.StatusActive,
+			DefinitionsMap: utils.FileDefinitionsMapping{utils.MediumDef: prefix + "/medium.txt"},
+		}},
+	}))
+
+	s := server.NewServer(server.ServerConfig{BlobStore: store, MetadataStore: metadataStore})
+	s.MapHandlers()
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/v1/upload/versions?prefix="+prefix, nil)
+	assert.Nil(t, err)
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	versions := views.FileVersionsResponse{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &versions))
+	assert.Equal(t, int64(2), versions.Current)
+	assert.Len(t, versions.Versions, 2)
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("POST", "/v1/upload/versions/rollback?prefix="+prefix+"&version=1", nil)
+	assert.Nil(t, err)
+	s.Engine.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+
+	schema, err := metadataStore.GetFile(userId, prefix)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), schema.CurrentContentVersion())
+	assert.Equal(t, "first.txt", schema.Filename)
+	assert.Equal(t, prefix+"/medium.txt", schema.DefinitionsMap[utils.MediumDef])
+
+	for _, query := range []string{"&version=1", "&version=3", "&version=x"} {
+		w = httptest.NewRecorder()
+		req, err = http.NewRequest("POST", "/v1/upload/versions/rollback?prefix="+prefix+query, nil)
+		assert.Nil(t, err)
+		s.Engine.ServeHTTP(w, req)
+
+		assert.NotEqual(t, 200, w.Code)
+	}
+}
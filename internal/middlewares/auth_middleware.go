@@ -0,0 +1,108 @@
+package middlewares
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/internal/tenants"
+	http_utils "github.com/gearpoint/filepoint/pkg/http"
+	"github.com/gin-gonic/gin"
+)
+
+// AuthMiddleware authenticates the request JWT and sets the request principal.
+// The requests with an API key were authenticated by the TenantMiddleware.
+func AuthMiddleware(authCfg config.AuthConfig, keyFunc jwt.Keyfunc, registry *tenants.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if strings.HasPrefix(c.GetHeader("Authorization"), apiKeyScheme) {
+			c.Next()
+			return
+		}
+
+		claims, err := http_utils.ExtractJWTFromRequest(c.Request, keyFunc)
+		if err != nil {
+			abortWithUnauthorized(c, "invalid token", err.Error())
+			return
+		}
+
+		if authCfg.Issuer != "" && !claims.VerifyIssuer(authCfg.Issuer, true) {
+			abortWithUnauthorized(c, "invalid token", "unexpected token issuer")
+			return
+		}
+		if authCfg.Audience != "" && !verifyAudience(claims, authCfg.Audience) {
+			abortWithUnauthorized(c, "invalid token", "unexpected token audience")
+			return
+		}
+
+		subject, _ := claims["sub"].(string)
+		admin := authCfg.AdminScope != "" && slices.Contains(http_utils.ClaimScopes(claims), authCfg.AdminScope)
+		if subject == "" && !admin {
+			abortWithUnauthorized(c, "invalid token", "the token subject is required")
+			return
+		}
+
+		if authCfg.TenantClaim != "" {
+			if tenantId, _ := claims[authCfg.TenantClaim].(string); tenantId != "" {
+				tenant, ok := registry.Get(tenantId)
+				if !ok {
+					abortWithUnauthorized(c, "invalid token", "unknown token tenant")
+					return
+				}
+				tenants.SetContext(c, tenant)
+			}
+		}
+
+		http_utils.SetPrincipal(c, &http_utils.Principal{
+			Subject: subject,
+			Admin:   admin,
+			Claims:  claims,
+		})
+		c.Next()
+	}
+}
+
+// AdminMiddleware only allows the admin principals.
+// The requests without a principal are rejected, even when the JWT authentication is disabled.
+func AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := http_utils.GetPrincipal(c)
+		if !ok {
+			abortWithUnauthorized(c, "authentication required", "an admin token or API key is required")
+			return
+		}
+
+		if !principal.Admin {
+			fmtErr := http_utils.NewForbiddenError("the admin scope is required")
+			c.Error(fmtErr)
+			c.AbortWithStatusJSON(fmtErr.Status(), fmtErr)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// verifyAudience checks the aud claim, which can be a string or a list.
+func verifyAudience(claims jwt.MapClaims, audience string) bool {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, value := range aud {
+			if value == audience {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// abortWithUnauthorized aborts the request with an unauthorized error.
+func abortWithUnauthorized(c *gin.Context, message string, description ...string) {
+	fmtErr := http_utils.NewUnauthorizedError(message, description...)
+
+	c.Error(fmtErr)
+	c.AbortWithStatusJSON(fmtErr.Status(), fmtErr)
+}
@@ -0,0 +1,90 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/internal/tenants"
+	http_utils "github.com/gearpoint/filepoint/pkg/http"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	registry := tenants.NewRegistry(&tenants.Tenant{})
+	registry.Add(&tenants.Tenant{Id: "acme"}, "acme-key")
+
+	authCfg := config.AuthConfig{Audience: "filepoint", AdminScope: "admin", TenantClaim: "tenant"}
+
+	router := gin.New()
+	router.Use(TenantMiddleware(registry, nil), AuthMiddleware(authCfg, http_utils.NewHS256KeyFunc([]byte("secret")), registry))
+	router.GET("/", func(c *gin.Context) {
+		principal, _ := http_utils.GetPrincipal(c)
+		c.JSON(http.StatusOK, gin.H{
+			"subject": principal.Subject,
+			"admin":   principal.Admin,
+			"tenant":  tenants.FromContext(c).Id,
+		})
+	})
+	router.GET("/admin", AdminMiddleware(), func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+
+	sign := func(claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+		assert.Nil(t, err)
+
+		return "Bearer " + token
+	}
+
+	request := func(path string, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	w := request("/", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = request("/", sign(jwt.MapClaims{"sub": "user", "aud": "filepoint"}))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"subject":"user","admin":false,"tenant":"default"}`, w.Body.String())
+
+	w = request("/", sign(jwt.MapClaims{"sub": "user", "aud": []string{"other", "filepoint"}, "tenant": "acme"}))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"subject":"user","admin":false,"tenant":"acme"}`, w.Body.String())
+
+	w = request("/", sign(jwt.MapClaims{"sub": "user", "aud": "other"}))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = request("/", sign(jwt.MapClaims{"sub": "user", "aud": "filepoint", "tenant": "unknown"}))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = request("/", sign(jwt.MapClaims{"aud": "filepoint"}))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = request("/", "ApiKey acme-key")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"subject":"","admin":true,"tenant":"acme"}`, w.Body.String())
+
+	w = request("/admin", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = request("/admin", "ApiKey acme-key")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = request("/admin", sign(jwt.MapClaims{"sub": "user", "aud": "filepoint"}))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = request("/admin", sign(jwt.MapClaims{"sub": "user", "aud": "filepoint", "scope": "admin"}))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
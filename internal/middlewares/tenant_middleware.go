@@ -0,0 +1,85 @@
+package middlewares
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/gearpoint/filepoint/internal/apikeys"
+	"github.com/gearpoint/filepoint/internal/tenants"
+	http_utils "github.com/gearpoint/filepoint/pkg/http"
+	"github.com/gearpoint/filepoint/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// The Authorization header scheme of the API keys.
+const apiKeyScheme = "ApiKey "
+
+// TenantMiddleware resolves the request tenant and principal from the API key.
+// The tenants static keys can access all the tenant files. The managed keys, optional, are limited to their scopes.
+// The requests without an API key use the default tenant.
+func TenantMiddleware(registry *tenants.Registry, apiKeys *apikeys.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKey, found := strings.CutPrefix(c.GetHeader("Authorization"), apiKeyScheme)
+		if !found {
+			tenants.SetContext(c, registry.Default())
+			c.Next()
+			return
+		}
+		apiKey = strings.TrimSpace(apiKey)
+
+		if tenant, ok := registry.ResolveApiKey(apiKey); ok {
+			tenants.SetContext(c, tenant)
+			http_utils.SetPrincipal(c, &http_utils.Principal{Admin: true})
+			c.Next()
+			return
+		}
+
+		if apiKeys == nil {
+			abortWithUnauthorized(c, "invalid API key")
+			return
+		}
+
+		managedKey, err := apiKeys.Resolve(apiKey)
+		if err != nil {
+			if !errors.Is(err, apikeys.ErrInvalidKey) {
+				logger.Error("error resolving the API key",
+					zap.Error(err),
+				)
+			}
+			abortWithUnauthorized(c, "invalid API key")
+			return
+		}
+
+		tenant, ok := registry.Get(managedKey.TenantId)
+		if !ok {
+			abortWithUnauthorized(c, "invalid API key", "the API key tenant doesn't exist")
+			return
+		}
+
+		tenants.SetContext(c, tenant)
+		http_utils.SetPrincipal(c, &http_utils.Principal{
+			Admin:    slices.Contains(managedKey.Scopes, apikeys.ScopeAdmin),
+			ApiKeyId: managedKey.KeyId,
+			Scopes:   managedKey.Scopes,
+		})
+		c.Next()
+	}
+}
+
+// ScopeMiddleware only allows the API keys with the scope. The admin keys have all the scopes.
+// The requests without a managed API key pass.
+func ScopeMiddleware(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := http_utils.GetPrincipal(c)
+		if ok && principal.ApiKeyId != "" && !principal.Admin && !slices.Contains(principal.Scopes, scope) {
+			fmtErr := http_utils.NewForbiddenError("missing API key scope", "the API key requires the "+scope+" scope")
+			c.Error(fmtErr)
+			c.AbortWithStatusJSON(fmtErr.Status(), fmtErr)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gearpoint/filepoint/internal/tenants"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	registry := tenants.NewRegistry(&tenants.Tenant{})
+	registry.Add(&tenants.Tenant{Id: "acme"}, "acme-key")
+
+	router := gin.New()
+	router.Use(TenantMiddleware(registry, nil))
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, tenants.FromContext(c).Id)
+	})
+
+	request := func(authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	w := request("")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, tenants.DefaultTenant, w.Body.String())
+
+	w = request("ApiKey acme-key")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "acme", w.Body.String())
+
+	w = request("ApiKey other-key")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
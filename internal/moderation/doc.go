@@ -0,0 +1,2 @@
+// Package moderation contains the content moderation policy.
+package moderation
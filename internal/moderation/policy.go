@@ -0,0 +1,58 @@
+package moderation
+
+import (
+	"strings"
+
+	"github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/pkg/labeler"
+)
+
+// Policy decides which moderation labels quarantine a file.
+type Policy struct {
+	thresholds map[string]float32
+}
+
+// NewPolicy returns a Policy instance.
+// The categories are case insensitive, as the config keys are lowercased when loaded.
+func NewPolicy(cfg *config.ModerationConfig) *Policy {
+	thresholds := map[string]float32{}
+	if cfg != nil {
+		for category, threshold := range cfg.Thresholds {
+			thresholds[strings.ToLower(category)] = threshold
+		}
+	}
+
+	return &Policy{
+		thresholds: thresholds,
+	}
+}
+
+// Enabled returns whether any moderation category is configured.
+func (p *Policy) Enabled() bool {
+	return p != nil && len(p.thresholds) > 0
+}
+
+// Evaluate returns the labels that exceed their category threshold.
+// A label matches a category by its own name or by its parent name.
+func (p *Policy) Evaluate(labels []labeler.ModerationLabel) []labeler.ModerationLabel {
+	var flagged []labeler.ModerationLabel
+	for _, label := range labels {
+		threshold, ok := p.threshold(label)
+		if ok && label.Confidence >= threshold {
+			flagged = append(flagged, label)
+		}
+	}
+
+	return flagged
+}
+
+// threshold returns the label category threshold.
+// The most specific category (the label name) takes precedence.
+func (p *Policy) threshold(label labeler.ModerationLabel) (float32, bool) {
+	if threshold, ok := p.thresholds[strings.ToLower(label.Name)]; ok {
+		return threshold, true
+	}
+
+	threshold, ok := p.thresholds[strings.ToLower(label.ParentName)]
+	return threshold, ok && label.ParentName != ""
+}
@@ -0,0 +1,33 @@
+package moderation
+
+import (
+	"testing"
+
+	"github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/pkg/labeler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyEvaluate(t *testing.T) {
+	policy := NewPolicy(&config.ModerationConfig{
+		Thresholds: map[string]float32{
+			"explicit nudity":  80,
+			"Graphic Violence": 95,
+		},
+	})
+
+	flagged := policy.Evaluate([]labeler.ModerationLabel{
+		{Name: "Nudity", ParentName: "Explicit Nudity", Confidence: 85},
+		{Name: "Graphic Violence", ParentName: "Violence", Confidence: 90},
+		{Name: "Smoking", ParentName: "Tobacco", Confidence: 99},
+	})
+
+	assert.Len(t, flagged, 1)
+	assert.True(t, policy.Enabled())
+	assert.Equal(t, "Nudity", flagged[0].Name)
+
+	assert.False(t, NewPolicy(nil).Enabled())
+	assert.Empty(t, NewPolicy(nil).Evaluate([]labeler.ModerationLabel{
+		{Name: "Violence", Confidence: 99},
+	}))
+}
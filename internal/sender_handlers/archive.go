@@ -0,0 +1,224 @@
+package sender_handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/gearpoint/filepoint/internal/uploader"
+	"github.com/gearpoint/filepoint/internal/uploader/strategies"
+	"github.com/gearpoint/filepoint/internal/uploader/strategies/archive_type"
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/logger"
+	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// processArchiveMessage extracts the uploaded archive and returns the extraction webhook message.
+func (h *UploadHandler) processArchiveMessage(msg *message.Message, uploadPubSub *views.UploadPubSub) ([]*message.Message, error) {
+	s3Prefix := msg.Metadata.Get(views.S3Prefix)
+
+	data, err := h.handleArchive(msg, uploadPubSub)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.WithContext(msg.Context()).Info("sending archive extracted webhook",
+		zap.Int("created", len(data.Created)),
+		zap.Int("rejected", len(data.Rejected)),
+	)
+
+	payload, err := json.Marshal(views.EventWebhookPayload{
+		Event:         views.ArchiveExtractedEvent,
+		Id:            uploadPubSub.Id,
+		CorrelationId: uploadPubSub.CorrelationId,
+		Location:      s3Prefix,
+		Data:          data,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, prefix := range data.Created {
+		h.uploadCacheControl.PrefixesCacheControl.AddKeyToCachedPrefixes(msg.Context(), prefix)
+	}
+
+	msg.Ack()
+
+	return message.Messages{
+		message.NewMessage(uploadPubSub.Id, payload),
+	}, nil
+}
+
+// handleArchive extracts the archive entries as individual files.
+// The archive file record is removed, as the archive itself isn't stored.
+// Once the extraction starts it doesn't fail, so the created files aren't duplicated by retries.
+func (h *UploadHandler) handleArchive(msg *message.Message, uploadPubSub *views.UploadPubSub) (*views.ArchiveExtractionData, error) {
+	s3Prefix := msg.Metadata.Get(views.S3Prefix)
+	tempObjectPrefix := msg.Metadata.Get(views.TempObjectPrefix)
+
+	ctx := logger.NewContext(msg.Context(), zap.Any("s3Prefix", s3Prefix))
+	logger := logger.WithContext(ctx)
+
+	archive, err := h.metadataStore.GetFile(uploadPubSub.UserId, s3Prefix)
+	if err != nil {
+		logger.Error("error retrieving table info from DB",
+			zap.Error(err),
+		)
+		return nil, errors.New("error retrieving table info from DB")
+	}
+
+	tempReader, err := h.blobStore.DownloadFile(tempObjectPrefix)
+	if err != nil {
+		logger.Error("error downloading temp file",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	filename, err := utils.CreateTmpFile(tempReader)
+	tempReader.Close()
+	if err != nil {
+		logger.Error("error creating temp file",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	defer os.Remove(filename)
+
+	data := &views.ArchiveExtractionData{
+		Created:  []string{},
+		Rejected: []views.RejectedArchiveEntry{},
+	}
+
+	rejected, err := archive_type.Extract(filename, uploadPubSub.ContentType, archive_type.DefaultLimits,
+		func(entry *archive_type.Entry, reader io.Reader) error {
+			prefix, err := h.extractEntry(ctx, archive, uploadPubSub, entry, reader)
+			if err != nil {
+				return err
+			}
+
+			data.Created = append(data.Created, prefix)
+
+			return nil
+		},
+	)
+	if rejected != nil {
+		data.Rejected = rejected
+	}
+	if err != nil {
+		logger.Warn("error extracting archive",
+			zap.Error(err),
+		)
+		data.Error = err.Error()
+	}
+
+	err = h.metadataStore.DeleteFile(archive.UserId, archive.Prefix)
+	if err != nil {
+		logger.Error("error deleting archive info from DB",
+			zap.Error(err),
+		)
+	}
+
+	return data, nil
+}
+
+// extractEntry uploads the archive entry as a new file, processed by the strategy of its content type.
+// It returns the new file prefix.
+func (h *UploadHandler) extractEntry(
+	ctx context.Context, archive *views.DynamoDBUploadSchema, archivePubSub *views.UploadPubSub, entry *archive_type.Entry, reader io.Reader,
+) (string, error) {
+	logger := logger.WithContext(ctx)
+
+	filename, err := utils.CreateTmpFile(io.NopCloser(reader))
+	if err != nil {
+		return "", errors.New("the entry can't be read")
+	}
+	defer os.Remove(filename)
+
+	contentType := archive_type.ContentType(entry.Name, filename)
+	eventType, entryUploader, err := uploader.GetUploaderByContentType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("unsupported content type %q", contentType)
+	}
+	if eventType == archive_type.Key {
+		return "", errors.New("nested archives are not extracted")
+	}
+	if len(h.allowedStrategies) > 0 && !slices.Contains(h.allowedStrategies, string(eventType)) {
+		return "", fmt.Errorf("%s files are not allowed", eventType)
+	}
+	if h.maxFileSize > 0 && entry.Size > h.maxFileSize {
+		return "", errors.New("the entry exceeds the file size limit")
+	}
+
+	entryPubSub := *archivePubSub
+	entryPubSub.Id = uuid.NewString()
+	entryPubSub.Filename = path.Base(entry.Name)
+	entryPubSub.ContentType = contentType
+	entryPubSub.Size = entry.Size
+	entryPubSub.OccurredOn = time.Now()
+
+	schema := &views.DynamoDBUploadSchema{
+		UserId:        archive.UserId,
+		Prefix:        utils.GetUniquePrefix(archive.UserId),
+		Author:        archive.Author,
+		Title:         archive.Title,
+		Filename:      entryPubSub.Filename,
+		FolderId:      archive.FolderId,
+		RequestId:     entryPubSub.Id,
+		CorrelationId: archive.CorrelationId,
+		Metadata:      archive.Metadata,
+		ContentType:   contentType,
+		OccurredOn:    time.Now().UTC(),
+	}
+
+	entryUploader.SetConfig(&strategies.UploaderConfig{
+		UploadView:    &entryPubSub,
+		AWSRepository: h.awsRepository,
+		BlobStore:     h.blobStore,
+		Labeler:       h.labeler,
+		Prefix:        schema.Prefix,
+	})
+
+	if err := entryUploader.Validate(&entryPubSub); err != nil {
+		return "", errors.New("the entry is empty or exceeds the file size limit")
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", errors.New("the entry can't be read")
+	}
+	tempObjectPrefix, err := entryUploader.UploadTemp(file)
+	file.Close()
+	if err != nil {
+		logger.Error("error saving entry temp file",
+			zap.String("entry", entry.Name),
+			zap.Error(err),
+		)
+		return "", errors.New("the entry could not be uploaded")
+	}
+
+	err = h.metadataStore.PutFile(schema)
+	if err != nil {
+		logger.Error("error saving entry info in DB",
+			zap.String("entry", entry.Name),
+			zap.Error(err),
+		)
+		return "", errors.New("the entry could not be uploaded")
+	}
+
+	_, err = h.processFile(ctx, eventType, &entryPubSub, schema.Prefix, tempObjectPrefix)
+	if err != nil {
+		h.metadataStore.DeleteFile(schema.UserId, schema.Prefix)
+		return "", errors.New("the entry could not be processed")
+	}
+
+	return schema.Prefix, nil
+}
@@ -0,0 +1,75 @@
+package sender_handlers
+
+import (
+	"fmt"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/gearpoint/filepoint/internal/views"
+)
+
+// TenantUploadHandlers dispatches the upload messages to the handler of the message tenant.
+type TenantUploadHandlers struct {
+	defaultHandler *UploadHandler
+	handlers       map[string]*UploadHandler
+	publisher      message.Publisher
+}
+
+// NewTenantUploadHandlers returns a TenantUploadHandlers instance.
+// The default handler processes the messages without tenant. Its webhook URL is the router handler topic,
+// the results of the tenants with other webhook URLs are published with the publisher.
+func NewTenantUploadHandlers(defaultHandler *UploadHandler, publisher message.Publisher) *TenantUploadHandlers {
+	return &TenantUploadHandlers{
+		defaultHandler: defaultHandler,
+		handlers:       map[string]*UploadHandler{},
+		publisher:      publisher,
+	}
+}
+
+// Add registers the tenant handler.
+func (t *TenantUploadHandlers) Add(tenantId string, handler *UploadHandler) {
+	t.handlers[tenantId] = handler
+}
+
+// handler returns the handler of the message tenant.
+func (t *TenantUploadHandlers) handler(msg *message.Message) (*UploadHandler, error) {
+	tenantId := msg.Metadata.Get(views.TenantId)
+	if tenantId == "" {
+		return t.defaultHandler, nil
+	}
+
+	handler, ok := t.handlers[tenantId]
+	if !ok {
+		return nil, fmt.Errorf("unknown tenant %q", tenantId)
+	}
+
+	return handler, nil
+}
+
+// ProccessUploadMessages proccess the upload with the tenant handler and returns the callback message.
+func (t *TenantUploadHandlers) ProccessUploadMessages() message.HandlerFunc {
+	return func(msg *message.Message) ([]*message.Message, error) {
+		handler, err := t.handler(msg)
+		if err != nil {
+			return nil, err
+		}
+
+		messages, err := handler.ProccessUploadMessages()(msg)
+		if err != nil || handler.webhookURL == t.defaultHandler.webhookURL {
+			return messages, err
+		}
+
+		return nil, t.publisher.Publish(handler.webhookURL, messages...)
+	}
+}
+
+// SetupUploadMiddlewares returns the upload middlewares, with the failed messages sent to the tenant webhook URL.
+func (t *TenantUploadHandlers) SetupUploadMiddlewares() []message.HandlerMiddleware {
+	return setupUploadMiddlewares(t.defaultHandler.maxRetries, t.defaultHandler.poisonQueueTopic, func(msg *message.Message) string {
+		handler, err := t.handler(msg)
+		if err != nil {
+			return t.defaultHandler.webhookURL
+		}
+
+		return handler.webhookURL
+	})
+}
@@ -0,0 +1,37 @@
+package sender_handlers
+
+import (
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/watermill"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantUploadHandlers(t *testing.T) {
+	defaultHandler := NewUploadHandler(&UploadHandlerConfig{
+		RouteConfig: config.RouteConfig{WebhookURL: "http://default.local/webhooks"},
+	})
+	acmeHandler := NewUploadHandler(&UploadHandlerConfig{
+		RouteConfig: config.RouteConfig{WebhookURL: "http://acme.local/webhooks"},
+	})
+
+	handlers := NewTenantUploadHandlers(defaultHandler, watermill.NewGoChannel())
+	handlers.Add("acme", acmeHandler)
+
+	msg := message.NewMessage("id", nil)
+	handler, err := handlers.handler(msg)
+	assert.Nil(t, err)
+	assert.Equal(t, defaultHandler, handler)
+
+	msg.Metadata.Set(views.TenantId, "acme")
+	handler, err = handlers.handler(msg)
+	assert.Nil(t, err)
+	assert.Equal(t, acmeHandler, handler)
+
+	msg.Metadata.Set(views.TenantId, "missing")
+	_, err = handlers.ProccessUploadMessages()(msg)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,140 @@
+package sender_handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/internal/usage"
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/logger"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"go.uber.org/zap"
+)
+
+// TrashPurgerConfig contains the trash purger config.
+type TrashPurgerConfig struct {
+	TrashConfig   config.TrashConfig
+	BlobStore     storage.BlobStore
+	MetadataStore metadata.MetadataStore
+}
+
+// TrashPurger permanently removes the files trashed for longer than the retention period.
+type TrashPurger struct {
+	retentionPeriod time.Duration
+	purgeInterval   time.Duration
+	blobStore       storage.BlobStore
+	metadataStore   metadata.MetadataStore
+}
+
+// NewTrashPurger returns a new TrashPurger instance.
+func NewTrashPurger(cfg *TrashPurgerConfig) *TrashPurger {
+	return &TrashPurger{
+		retentionPeriod: cfg.TrashConfig.RetentionPeriod,
+		purgeInterval:   cfg.TrashConfig.PurgeInterval,
+		blobStore:       cfg.BlobStore,
+		metadataStore:   cfg.MetadataStore,
+	}
+}
+
+// Run purges the trash in every interval, until the context is done.
+func (p *TrashPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			purged := p.Purge(now)
+			if purged > 0 {
+				logger.Info("trash purged", zap.Int("files", purged))
+			}
+		}
+	}
+}
+
+// Purge removes the files trashed before the retention period. It returns the number of purged files.
+func (p *TrashPurger) Purge(now time.Time) int {
+	schemas, err := p.metadataStore.ListFilesByStatus("", views.StatusTrashed)
+	if err != nil {
+		logger.Error("error listing trashed files", zap.Error(err))
+		return 0
+	}
+
+	purged := 0
+	for _, schema := range schemas {
+		if now.Sub(schema.TrashedOn) < p.retentionPeriod {
+			continue
+		}
+
+		err := PurgeFile(p.metadataStore, p.blobStore, schema)
+		if err != nil {
+			logger.Error("error purging trashed file",
+				zap.String("prefix", schema.Prefix),
+				zap.Error(err),
+			)
+			continue
+		}
+		purged++
+	}
+
+	return purged
+}
+
+// PurgeFile permanently removes the file objects, previous versions, text, index items and record.
+// The file is removed from the storage usage.
+func PurgeFile(metadataStore metadata.MetadataStore, blobStore storage.BlobStore, schema *views.DynamoDBUploadSchema) error {
+	objects := schema.VersionsObjects()
+	for _, objectName := range schema.DefinitionsMap {
+		objects = append(objects, objectName)
+	}
+
+	if len(objects) > 0 {
+		err := blobStore.DeleteMany(objects)
+		if err != nil {
+			return err
+		}
+	}
+
+	DeleteFileIndex(metadataStore, blobStore, schema)
+
+	err := metadataStore.DeleteFile(schema.UserId, schema.Prefix)
+	if err != nil {
+		return err
+	}
+
+	err = usage.RemoveFile(metadataStore, schema)
+	if err != nil && !errors.Is(err, metadata.ErrUsageNotConfigured) {
+		logger.Error("error removing the file usage",
+			zap.String("prefix", schema.Prefix),
+			zap.Error(err),
+		)
+	}
+
+	return nil
+}
+
+// DeleteFileIndex removes the file labels and text terms from the index, and the file text document.
+func DeleteFileIndex(metadataStore metadata.MetadataStore, blobStore storage.BlobStore, schema *views.DynamoDBUploadSchema) {
+	if schema.TextObject != "" {
+		err := blobStore.DeleteObject(schema.TextObject)
+		if err != nil {
+			logger.Error("error deleting file text",
+				zap.String("prefix", schema.Prefix),
+				zap.Error(err),
+			)
+		}
+	}
+
+	err := metadataStore.DeleteIndex(schema.IndexSchemas())
+	if err != nil {
+		logger.Error("error deleting file index",
+			zap.String("prefix", schema.Prefix),
+			zap.Error(err),
+		)
+	}
+}
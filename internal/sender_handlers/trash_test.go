@@ -0,0 +1,71 @@
+package sender_handlers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/internal/usage"
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrashPurger(t *testing.T) {
+	blobStore, err := storage.NewFileSystemStore(t.TempDir(), storage.NewHMACSigner("/v1/files", "secret"))
+	assert.Nil(t, err)
+	metadataStore := metadata.NewMemoryStore()
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	files := map[string]time.Time{
+		"user/expired": now.Add(-31 * 24 * time.Hour),
+		"user/recent":  now.Add(-time.Hour),
+	}
+
+	for prefix, trashedOn := range files {
+		objectName := prefix + "/original.txt"
+		err := blobStore.PutObject(objectName, strings.NewReader("content"), "text/plain", nil, nil)
+		assert.Nil(t, err)
+
+		schema := &views.DynamoDBUploadSchema{
+			UserId:         "user",
+			Prefix:         prefix,
+			Status:         views.StatusActive,
+			DefinitionsMap: utils.FileDefinitionsMapping{utils.LowDef: objectName},
+			Size:           7,
+			Strategy:       "file",
+		}
+		schema.Trash(trashedOn)
+		assert.Nil(t, metadataStore.PutFile(schema))
+		assert.Nil(t, usage.AddFile(metadataStore, schema))
+	}
+
+	purger := NewTrashPurger(&TrashPurgerConfig{
+		TrashConfig:   config.TrashConfig{RetentionPeriod: 30 * 24 * time.Hour, PurgeInterval: time.Hour},
+		BlobStore:     blobStore,
+		MetadataStore: metadataStore,
+	})
+
+	assert.Equal(t, 1, purger.Purge(now))
+
+	_, err = metadataStore.GetFile("user", "user/expired")
+	assert.ErrorIs(t, err, metadata.ErrNotFound)
+	_, err = blobStore.HeadObject("user/expired/original.txt")
+	assert.True(t, storage.CheckIsNotFoundError(err))
+
+	used, err := usage.Get(metadataStore, "user")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(7), used.Bytes)
+	assert.Equal(t, int64(1), used.Files)
+
+	schema, err := metadataStore.GetFile("user", "user/recent")
+	assert.Nil(t, err)
+	assert.Equal(t, views.StatusTrashed, schema.Status)
+
+	schema.Restore()
+	assert.Equal(t, views.StatusActive, schema.Status)
+	assert.True(t, schema.TrashedOn.IsZero())
+}
@@ -1,6 +1,7 @@
 package sender_handlers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -13,34 +14,73 @@ import (
 	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
 	"github.com/gearpoint/filepoint/config"
 	cache_control "github.com/gearpoint/filepoint/internal/cache-control"
+	"github.com/gearpoint/filepoint/internal/moderation"
 	"github.com/gearpoint/filepoint/internal/uploader"
 	"github.com/gearpoint/filepoint/internal/uploader/strategies"
+	"github.com/gearpoint/filepoint/internal/uploader/strategies/archive_type"
+	"github.com/gearpoint/filepoint/internal/usage"
 	"github.com/gearpoint/filepoint/internal/views"
 	"github.com/gearpoint/filepoint/pkg/aws_repository"
+	"github.com/gearpoint/filepoint/pkg/envelope"
+	"github.com/gearpoint/filepoint/pkg/labeler"
 	"github.com/gearpoint/filepoint/pkg/logger"
+	"github.com/gearpoint/filepoint/pkg/metadata"
 	"github.com/gearpoint/filepoint/pkg/redis"
+	"github.com/gearpoint/filepoint/pkg/storage"
 	"github.com/gearpoint/filepoint/pkg/utils"
 	"github.com/gearpoint/filepoint/pkg/watermill"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+// UploadHandlerConfig contains the upload handler config.
+type UploadHandlerConfig struct {
+	RouteConfig      config.RouteConfig
+	AWSRepository    *aws_repository.AWSRepository
+	BlobStore        storage.BlobStore
+	MetadataStore    metadata.MetadataStore
+	RedisRepository  *redis.RedisRepository
+	Labeler          labeler.Labeler
+	ModerationPolicy *moderation.Policy
+	// KeyWrapper wraps the data keys of the encrypted route files.
+	KeyWrapper envelope.KeyWrapper
+	// AllowedStrategies are the strategies of the extracted archive entries. All are accepted when empty.
+	AllowedStrategies []string
+	// MaxFileSize is the max extracted archive entry size, in bytes. Zero uses the strategies limits.
+	MaxFileSize int64
+}
+
 type UploadHandler struct {
-	tableName          string
 	maxRetries         int
 	poisonQueueTopic   string
 	webhookURL         string
 	awsRepository      *aws_repository.AWSRepository
+	blobStore          storage.BlobStore
+	metadataStore      metadata.MetadataStore
+	labeler            labeler.Labeler
+	moderationPolicy   *moderation.Policy
+	encrypted          bool
+	keyWrapper         envelope.KeyWrapper
 	uploadCacheControl *cache_control.UploadCacheControl
+	allowedStrategies  []string
+	maxFileSize        int64
 }
 
-func NewUploadHandler(awsRepository *aws_repository.AWSRepository, redisRepository *redis.RedisRepository, routeCfg config.RouteConfig) *UploadHandler {
+func NewUploadHandler(cfg *UploadHandlerConfig) *UploadHandler {
 	return &UploadHandler{
-		tableName:          routeCfg.TableName,
-		maxRetries:         routeCfg.MaxRetries,
-		poisonQueueTopic:   routeCfg.PoisonTopic,
-		webhookURL:         routeCfg.WebhookURL,
-		awsRepository:      awsRepository,
-		uploadCacheControl: cache_control.NewUploadCacheControl(redisRepository),
+		maxRetries:         cfg.RouteConfig.MaxRetries,
+		poisonQueueTopic:   cfg.RouteConfig.PoisonTopic,
+		webhookURL:         cfg.RouteConfig.WebhookURL,
+		awsRepository:      cfg.AWSRepository,
+		blobStore:          cfg.BlobStore,
+		metadataStore:      cfg.MetadataStore,
+		labeler:            cfg.Labeler,
+		moderationPolicy:   cfg.ModerationPolicy,
+		encrypted:          cfg.RouteConfig.Encrypted,
+		keyWrapper:         cfg.KeyWrapper,
+		uploadCacheControl: cache_control.NewUploadCacheControl(cfg.RedisRepository),
+		allowedStrategies:  cfg.AllowedStrategies,
+		maxFileSize:        cfg.MaxFileSize,
 	}
 }
 
@@ -73,13 +113,17 @@ func (h *UploadHandler) ProccessUploadMessages() message.HandlerFunc {
 			zap.Any("s3Prefix", s3Prefix),
 		)
 
-		uploadPubSub, err := h.unmarshalUpload(msg.Payload)
+		uploadPubSub, err := unmarshalUpload(msg.Payload)
 		if err != nil {
 			logger.Error(err.Error())
 			return nil, err
 		}
 
-		err = h.handleUpload(msg, uploadPubSub)
+		if eventType == archive_type.Key {
+			return h.processArchiveMessage(msg, uploadPubSub)
+		}
+
+		schema, err := h.handleUpload(msg, uploadPubSub)
 		if err != nil {
 			return nil, err
 		}
@@ -99,16 +143,35 @@ func (h *UploadHandler) ProccessUploadMessages() message.HandlerFunc {
 
 		h.uploadCacheControl.PrefixesCacheControl.AddKeyToCachedPrefixes(msg.Context(), s3Prefix)
 
+		messages := message.Messages{
+			message.NewMessage(uploadPubSub.Id, webhookPayload),
+		}
+
+		if schema.Status == views.StatusQuarantined {
+			logger.Info("sending moderation flagged webhook")
+
+			flaggedPayload, err := json.Marshal(views.EventWebhookPayload{
+				Event:         views.ModerationFlaggedEvent,
+				Id:            uploadPubSub.Id,
+				CorrelationId: uploadPubSub.CorrelationId,
+				Location:      s3Prefix,
+				Data:          schema.FlaggedLabels,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			messages = append(messages, message.NewMessage(uuid.NewString(), flaggedPayload))
+		}
+
 		msg.Ack()
 
-		return message.Messages{
-			message.NewMessage(uploadPubSub.Id, webhookPayload),
-		}, nil
+		return messages, nil
 	}
 }
 
 // unmarshalUpload returns the UploadPubSub view or error.
-func (h *UploadHandler) unmarshalUpload(payload message.Payload) (*views.UploadPubSub, error) {
+func unmarshalUpload(payload message.Payload) (*views.UploadPubSub, error) {
 	var uploadPubSub = &views.UploadPubSub{}
 	err := json.Unmarshal(payload, uploadPubSub)
 
@@ -116,27 +179,29 @@ func (h *UploadHandler) unmarshalUpload(payload message.Payload) (*views.UploadP
 }
 
 // handleUpload is responsible for uploading the file.
-func (h *UploadHandler) handleUpload(msg *message.Message, uploadPubSub *views.UploadPubSub) error {
+// It returns the updated file schema.
+func (h *UploadHandler) handleUpload(msg *message.Message, uploadPubSub *views.UploadPubSub) (*views.DynamoDBUploadSchema, error) {
 	eventType := strategies.EventTypeKey(msg.Metadata.Get(views.EventType))
 	s3Prefix := msg.Metadata.Get(views.S3Prefix)
 	tempObjectPrefix := msg.Metadata.Get(views.TempObjectPrefix)
 
-	ctx := logger.NewContext(msg.Context(), zap.Any("s3Prefix", s3Prefix))
+	return h.processFile(msg.Context(), eventType, uploadPubSub, s3Prefix, tempObjectPrefix)
+}
+
+// processFile uploads the file definitions from the temp object, with its labels and text.
+// It returns the updated file schema.
+func (h *UploadHandler) processFile(
+	ctx context.Context, eventType strategies.EventTypeKey, uploadPubSub *views.UploadPubSub, s3Prefix string, tempObjectPrefix string,
+) (*views.DynamoDBUploadSchema, error) {
+	ctx = logger.NewContext(ctx, zap.Any("s3Prefix", s3Prefix))
 	logger := logger.WithContext(ctx)
 
-	schema := &views.DynamoDBUploadSchema{
-		UserId: uploadPubSub.UserId,
-		Prefix: s3Prefix,
-	}
-	err := h.awsRepository.GetTableRow(
-		h.tableName, schema,
-	)
+	schema, err := h.metadataStore.GetFile(uploadPubSub.UserId, s3Prefix)
 	if err != nil {
 		logger.Error("error retrieving table info from DB",
-			zap.String("tableName", h.tableName),
 			zap.Error(err),
 		)
-		return errors.New("error retrieving table info from DB")
+		return nil, errors.New("error retrieving table info from DB")
 	}
 
 	uploader, err := uploader.GetUploaderByEventType(eventType)
@@ -144,21 +209,68 @@ func (h *UploadHandler) handleUpload(msg *message.Message, uploadPubSub *views.U
 		logger.Error("unrecognized event-type",
 			zap.Error(err),
 		)
-		return errors.New("unrecognized event-type")
+		return nil, errors.New("unrecognized event-type")
+	}
+
+	// The replaced contents are stored in the version sub-keys.
+	objectsPrefix := views.VersionPrefix(s3Prefix, uploadPubSub.ContentVersion)
+
+	// The encrypted route files get a data key per content.
+	var dataKey []byte
+	var encryption *views.FileEncryption
+	if h.encrypted {
+		dataKey, encryption, err = h.newDataKey()
+		if err != nil {
+			logger.Error("error creating the file data key",
+				zap.Error(err),
+			)
+			return nil, err
+		}
 	}
 
 	uploader.SetConfig(&strategies.UploaderConfig{
 		UploadView:    uploadPubSub,
 		AWSRepository: h.awsRepository,
-		Prefix:        s3Prefix,
+		BlobStore:     h.blobStore,
+		Labeler:       h.labeler,
+		Prefix:        objectsPrefix,
+		DataKey:       dataKey,
 	})
 
+	// The labels are detected in the original file, before the content type changes.
+	// The moderated files are retried, so they're never stored without moderation.
+	fileLabels, err := uploader.DetectLabels(tempObjectPrefix)
+	if err != nil {
+		if h.moderationPolicy.Enabled() {
+			logger.Error("error detecting file moderation labels",
+				zap.Error(err),
+			)
+			return nil, err
+		}
+
+		logger.Warn("error detecting file labels",
+			zap.Error(err),
+		)
+	}
+
+	// The flagged files objects are uploaded with the quarantine tag, so they're never signed.
+	var flagged []labeler.ModerationLabel
+	if fileLabels != nil && h.moderationPolicy.Enabled() {
+		flagged = h.moderationPolicy.Evaluate(fileLabels.Moderation)
+		if len(flagged) > 0 {
+			logger.Info("file flagged by the moderation policy",
+				zap.Any("flaggedLabels", flagged),
+			)
+			uploader.Config().Tags = map[string]string{storage.QuarantineTag: "true"}
+		}
+	}
+
 	tempReader, err := uploader.DownloadTemp(tempObjectPrefix)
 	if err != nil {
 		logger.Error("error downloading temp file",
 			zap.Error(err),
 		)
-		return err
+		return nil, err
 	}
 	filename, err := utils.CreateTmpFile(tempReader)
 	tempReader.Close()
@@ -166,10 +278,17 @@ func (h *UploadHandler) handleUpload(msg *message.Message, uploadPubSub *views.U
 		logger.Error("error creating temp file",
 			zap.Error(err),
 		)
-		return err
+		return nil, err
 	}
 	defer os.Remove(filename)
 
+	// The encrypted files text isn't stored, as the text document and the index aren't encrypted.
+	var textObject string
+	var textTerms []string
+	if encryption == nil {
+		textObject, textTerms = h.storeText(logger, uploader, tempObjectPrefix, filename, objectsPrefix)
+	}
+
 	fileDefs := uploader.FileDefinitions()
 	definitionsMap := utils.FileDefinitionsMapping{}
 
@@ -216,38 +335,222 @@ func (h *UploadHandler) handleUpload(msg *message.Message, uploadPubSub *views.U
 	wg.Wait()
 
 	if len(fileDefs) == 0 {
-		return errors.New("file could not be uploaded")
+		return nil, errors.New("file could not be uploaded")
 	}
 
-	schema.DefinitionsMap = definitionsMap
+	fileVersion := views.FileVersion{
+		Version:        uploadPubSub.ContentVersion,
+		Filename:       uploadPubSub.Filename,
+		ContentType:    uploadPubSub.ContentType,
+		DefinitionsMap: definitionsMap,
+		FileLabels:     fileLabels,
+		TextObject:     textObject,
+		TextTerms:      textTerms,
+		Status:         views.StatusActive,
+		OccurredOn:     uploadPubSub.OccurredOn.UTC(),
+		Encryption:     encryption,
+		Size:           uploadPubSub.Size,
+	}
 
-	err = h.awsRepository.UpdateTableRow(
-		h.tableName, schema,
-	)
+	if len(flagged) > 0 {
+		fileVersion.Status = views.StatusQuarantined
+		fileVersion.FlaggedLabels = flagged
+	}
+
+	// The file is updated from its stored record, which may have been changed or deleted while it was processed.
+	var previous views.DynamoDBUploadSchema
+	var counted bool
+	schema, err = metadata.UpdateFileFunc(h.metadataStore, uploadPubSub.UserId, s3Prefix, func(file *views.DynamoDBUploadSchema) error {
+		previous = *file
+
+		// The retried messages don't count the processed content again.
+		processedVersion, processed := previous.GetFileVersion(max(fileVersion.Version, 1))
+		counted = processed && len(processedVersion.DefinitionsMap) > 0
+
+		// The file contents are counted in the strategy of the first content.
+		if file.Strategy == "" {
+			file.Strategy = string(eventType)
+		}
+
+		if uploadPubSub.ContentVersion > 1 {
+			// The versions may be processed out of order, a later version stays current.
+			if fileVersion.Version > file.CurrentContentVersion() {
+				file.SetFileVersion(fileVersion)
+			} else {
+				file.AddFileVersion(fileVersion)
+			}
+		} else {
+			file.DefinitionsMap = fileVersion.DefinitionsMap
+			file.FileLabels = fileVersion.FileLabels
+			file.TextObject = fileVersion.TextObject
+			file.TextTerms = fileVersion.TextTerms
+			file.Status = fileVersion.Status
+			file.FlaggedLabels = fileVersion.FlaggedLabels
+			file.Encryption = fileVersion.Encryption
+			file.Size = fileVersion.Size
+		}
+
+		// The files trashed while processed stay in the trash.
+		if previous.Status == views.StatusTrashed {
+			file.Trash(previous.TrashedOn)
+		}
+
+		return nil
+	})
 	if err != nil {
 		logger.Error("error updating table row",
-			zap.Any("tableName", h.tableName),
 			zap.Any("userId", uploadPubSub.UserId),
 			zap.Error(err),
 		)
-		return errors.New("unable to update file data in DB")
+		return nil, errors.New("unable to update file data in DB")
+	}
+
+	if schema.Status == views.StatusTrashed {
+		h.tagObjects(logger, definitionsMap, storage.TrashTag)
 	}
 
-	return nil
+	if !counted {
+		var files int64
+		if fileVersion.Version <= 1 {
+			files = 1
+		}
+
+		err = usage.Add(h.metadataStore, schema.UserId, schema.Strategy, fileVersion.Size, files)
+		if err != nil && !errors.Is(err, metadata.ErrUsageNotConfigured) {
+			logger.Warn("error counting the file usage",
+				zap.Error(err),
+			)
+		}
+	}
+
+	if schema.CurrentContentVersion() != previous.CurrentContentVersion() {
+		err = h.metadataStore.DeleteIndex(previous.IndexSchemas())
+		if err != nil {
+			logger.Warn("error deleting the replaced content index",
+				zap.Error(err),
+			)
+		}
+	}
+
+	h.indexFile(logger, schema)
+
+	// The plaintext temp file of the encrypted files is removed, without waiting for the lifecycle rule.
+	if encryption != nil {
+		err = h.blobStore.DeleteObject(tempObjectPrefix)
+		if err != nil {
+			logger.Warn("error deleting temp file",
+				zap.Error(err),
+			)
+		}
+	}
+
+	return schema, nil
+}
+
+// newDataKey returns a new file data key, with the wrapped key stored in the file record.
+func (h *UploadHandler) newDataKey() ([]byte, *views.FileEncryption, error) {
+	if h.keyWrapper == nil {
+		return nil, nil, errors.New("the encryption master key isn't configured")
+	}
+
+	dataKey, err := envelope.NewDataKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wrappedKey, err := h.keyWrapper.WrapKey(dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return dataKey, &views.FileEncryption{
+		Algorithm:  envelope.Algorithm,
+		KeyId:      h.keyWrapper.KeyId(),
+		WrappedKey: wrappedKey,
+	}, nil
+}
+
+// storeText extracts the file text and stores it as a companion JSON object.
+// It returns the text object prefix and the searchable terms.
+func (h *UploadHandler) storeText(
+	logger *zap.Logger, uploader strategies.Uploader, tempObjectPrefix string, filename string, s3Prefix string,
+) (string, []string) {
+	document, err := uploader.ExtractText(tempObjectPrefix, filename)
+	if err != nil {
+		logger.Warn("error extracting file text",
+			zap.Error(err),
+		)
+		return "", nil
+	}
+	if document.IsEmpty() {
+		return "", nil
+	}
+
+	content, err := json.Marshal(document)
+	if err != nil {
+		logger.Warn("error marshaling file text", zap.Error(err))
+		return "", nil
+	}
+
+	textObject := views.TextObjectPrefix(s3Prefix)
+	err = h.blobStore.PutObject(textObject, bytes.NewReader(content), "application/json", nil, nil)
+	if err != nil {
+		logger.Warn("error storing file text",
+			zap.String("textObject", textObject),
+			zap.Error(err),
+		)
+		return "", nil
+	}
+
+	return textObject, document.Terms()
+}
+
+// indexFile adds the file labels and text terms to the index, used in search.
+func (h *UploadHandler) indexFile(logger *zap.Logger, schema *views.DynamoDBUploadSchema) {
+	err := h.metadataStore.PutIndex(schema.IndexSchemas())
+	if err != nil {
+		logger.Warn("error indexing file",
+			zap.Error(err),
+		)
+	}
+}
+
+// tagObjects adds the tag to the file objects.
+func (h *UploadHandler) tagObjects(logger *zap.Logger, definitionsMap utils.FileDefinitionsMapping, tagKey string) {
+	for _, objectName := range definitionsMap {
+		err := h.blobStore.AddObjectTags(objectName, map[string]string{
+			tagKey: "true",
+		})
+		if err != nil {
+			logger.Error("error tagging object",
+				zap.String("objectName", objectName),
+				zap.String("tag", tagKey),
+				zap.Error(err),
+			)
+		}
+	}
 }
 
 // SetupUploadMiddlewares returns the specific upload middlewares.
 func (h *UploadHandler) SetupUploadMiddlewares() []message.HandlerMiddleware {
+	return setupUploadMiddlewares(h.maxRetries, h.poisonQueueTopic, func(*message.Message) string {
+		return h.webhookURL
+	})
+}
+
+// setupUploadMiddlewares returns the retry and poison queue middlewares.
+// The failed messages are sent to the webhook URL returned by webhookURL.
+func setupUploadMiddlewares(maxRetries int, poisonQueueTopic string, webhookURL func(*message.Message) string) []message.HandlerMiddleware {
 	gochannel := watermill.NewGoChannel()
 
-	poisonQueue, err := middleware.PoisonQueue(gochannel, h.poisonQueueTopic)
+	poisonQueue, err := middleware.PoisonQueue(gochannel, poisonQueueTopic)
 	if err != nil {
 		panic(err)
 	}
-	go h.processUploadPoisonQueue(gochannel, h.poisonQueueTopic)
+	go processUploadPoisonQueue(gochannel, poisonQueueTopic, webhookURL)
 
 	retryMiddleware := middleware.Retry{
-		MaxRetries:      h.maxRetries,
+		MaxRetries:      maxRetries,
 		InitialInterval: time.Second * 5,
 		MaxInterval:     time.Hour * 5,
 		Multiplier:      1.25,
@@ -261,7 +564,7 @@ func (h *UploadHandler) SetupUploadMiddlewares() []message.HandlerMiddleware {
 }
 
 // processUploadPoisonQueue consumes the messages coming from poison queue.
-func (h *UploadHandler) processUploadPoisonQueue(gochannel *gochannel.GoChannel, poisonQueueTopic string) {
+func processUploadPoisonQueue(gochannel *gochannel.GoChannel, poisonQueueTopic string, webhookURL func(*message.Message) string) {
 	messages, err := gochannel.Subscribe(context.Background(), poisonQueueTopic)
 	if err != nil {
 		logger.Error("unable to publish error messages")
@@ -269,7 +572,7 @@ func (h *UploadHandler) processUploadPoisonQueue(gochannel *gochannel.GoChannel,
 
 	go func(messages <-chan *message.Message) {
 		for msg := range messages {
-			uploadPubSub, err := h.unmarshalUpload(msg.Payload)
+			uploadPubSub, err := unmarshalUpload(msg.Payload)
 			if err != nil {
 				uploadPubSub = &views.UploadPubSub{
 					Id: msg.UUID,
@@ -277,12 +580,36 @@ func (h *UploadHandler) processUploadPoisonQueue(gochannel *gochannel.GoChannel,
 			}
 
 			logger.Info("sending error message to webhook...")
-			SendUploadErrorWebhook(msg.Context(), uploadPubSub, h.webhookURL)
+			SendUploadErrorWebhook(msg.Context(), uploadPubSub, webhookURL(msg))
 			msg.Ack()
 		}
 	}(messages)
 }
 
+// SendEventWebhook calls the webhook with the file event.
+func SendEventWebhook(ctx context.Context, payload *views.EventWebhookPayload, webhookURL string) {
+	logger := logger.WithContext(ctx)
+
+	httpPublisher, err := watermill.NewHttpPublisher()
+	if err != nil {
+		logger.Error("error initializing http publisher", zap.Error(err))
+		return
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		logger.Error("cannot marshal message", zap.Error(err))
+		return
+	}
+
+	logger.Info("sending event webhook", zap.String("event", payload.Event))
+
+	err = httpPublisher.Publish(webhookURL, message.NewMessage(uuid.NewString(), body))
+	if err != nil {
+		logger.Error("error sending http request", zap.Error(err))
+	}
+}
+
 // SendUploadErrorWebhook calls the upload webhook with error message.
 func SendUploadErrorWebhook(ctx context.Context, uploadPubSub *views.UploadPubSub, webhookURL string) {
 	logger := logger.WithContext(ctx)
@@ -2,7 +2,11 @@ package server
 
 import (
 	"github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/internal/apikeys"
 	"github.com/gearpoint/filepoint/internal/controllers"
+	"github.com/gearpoint/filepoint/internal/middlewares"
+	"github.com/gearpoint/filepoint/internal/tenants"
+	"github.com/gearpoint/filepoint/pkg/storage"
 	"github.com/gin-gonic/gin"
 
 	swaggerfiles "github.com/swaggo/files"
@@ -23,25 +27,234 @@ func (s *Server) MapHandlers() error {
 	v1.GET("/docs/*any", gswagger.WrapHandler(swaggerfiles.Handler))
 	v1.GET("/health", controllers.HealthController{}.HealthCheck)
 
-	upload := v1.Group(string(config.Upload))
+	if s.blobStore != nil {
+		if hmacSigner, ok := s.blobStore.Signer().(*storage.HMACSigner); ok {
+			downloadController := controllers.NewDownloadController(s.tenants.ObjectStore, hmacSigner)
+
+			v1.GET("/files/*prefix", downloadController.Download)
+		}
+	}
+
+	byTenant := map[string]*tenantControllers{}
+	for _, tenant := range s.tenants.Tenants() {
+		byTenant[tenant.Id] = s.newTenantControllers(tenant)
+	}
+
+	uploads := func(method func(*controllers.UploadController, *gin.Context)) gin.HandlerFunc {
+		return tenantHandler(byTenant, func(t *tenantControllers) *controllers.UploadController { return t.upload }, method)
+	}
+	cookies := func(method func(*controllers.CookieController, *gin.Context)) gin.HandlerFunc {
+		return tenantHandler(byTenant, func(t *tenantControllers) *controllers.CookieController { return t.cookie }, method)
+	}
+	folders := func(method func(*controllers.FolderController, *gin.Context)) gin.HandlerFunc {
+		return tenantHandler(byTenant, func(t *tenantControllers) *controllers.FolderController { return t.folder }, method)
+	}
+	archives := func(method func(*controllers.ArchiveController, *gin.Context)) gin.HandlerFunc {
+		return tenantHandler(byTenant, func(t *tenantControllers) *controllers.ArchiveController { return t.archive }, method)
+	}
+	versions := func(method func(*controllers.VersionController, *gin.Context)) gin.HandlerFunc {
+		return tenantHandler(byTenant, func(t *tenantControllers) *controllers.VersionController { return t.version }, method)
+	}
+	moderation := func(method func(*controllers.ModerationController, *gin.Context)) gin.HandlerFunc {
+		return tenantHandler(byTenant, func(t *tenantControllers) *controllers.ModerationController { return t.moderation }, method)
+	}
+	usages := func(method func(*controllers.UsageController, *gin.Context)) gin.HandlerFunc {
+		return tenantHandler(byTenant, func(t *tenantControllers) *controllers.UsageController { return t.usage }, method)
+	}
+
+	upload := v1.Group(string(config.Upload), s.authMiddlewares()...)
 	{
-		uploadController := controllers.NewUploadController(
+		// The managed API keys are limited to the routes of their scopes.
+		read := upload.Group("", middlewares.ScopeMiddleware(apikeys.ScopeRead))
+		write := upload.Group("", middlewares.ScopeMiddleware(apikeys.ScopeUpload))
+		remove := upload.Group("", middlewares.ScopeMiddleware(apikeys.ScopeDelete))
+
+		read.GET("", uploads((*controllers.UploadController).GetSignedURL))
+		read.GET("/download", uploads((*controllers.UploadController).Download))
+		read.GET("/folder", uploads((*controllers.UploadController).ListFolder))
+		read.GET("/files", uploads((*controllers.UploadController).ListFiles))
+		read.GET("/search", uploads((*controllers.UploadController).Search))
+		read.GET("/text", uploads((*controllers.UploadController).GetText))
+		read.GET("/trash", uploads((*controllers.UploadController).ListTrash))
+		write.POST("", uploads((*controllers.UploadController).Upload))
+		read.POST("/list", uploads((*controllers.UploadController).ListObjects))
+		write.POST("/copy", uploads((*controllers.UploadController).Copy))
+		write.POST("/move", uploads((*controllers.UploadController).Move))
+		write.PATCH("", uploads((*controllers.UploadController).Update))
+		write.PATCH("/metadata", uploads((*controllers.UploadController).UpdateMetadata))
+		write.PATCH("/rename", uploads((*controllers.UploadController).Rename))
+		write.POST("/restore", uploads((*controllers.UploadController).Restore))
+		remove.DELETE("", uploads((*controllers.UploadController).Delete))
+		remove.DELETE("/all", uploads((*controllers.UploadController).DeleteAll))
+
+		read.GET("/cookies", cookies((*controllers.CookieController).GetSignedCookies))
+
+		read.GET("/folders", folders((*controllers.FolderController).List))
+		write.POST("/folders", folders((*controllers.FolderController).Create))
+		write.PATCH("/folders", folders((*controllers.FolderController).Update))
+		remove.DELETE("/folders", folders((*controllers.FolderController).Delete))
+
+		read.POST("/archive", archives((*controllers.ArchiveController).Archive))
+
+		write.PUT("/content", versions((*controllers.VersionController).ReplaceContent))
+		read.GET("/versions", versions((*controllers.VersionController).ListVersions))
+		read.GET("/versions/url", versions((*controllers.VersionController).GetVersionSignedURL))
+		write.POST("/versions/rollback", versions((*controllers.VersionController).RollBack))
+	}
+
+	v1.GET("/usage", append(s.authMiddlewares(), middlewares.ScopeMiddleware(apikeys.ScopeRead), usages((*controllers.UsageController).Get))...)
+
+	if s.metadataStore != nil {
+		shareController := controllers.NewShareController(
 			&controllers.UploadConfig{
-				RouteConfig:     s.routes[config.Upload],
-				PartitionKey:    s.partitionKey,
-				Publisher:       s.publisher,
-				AWSRepository:   s.awsRepository,
-				RedisRepository: s.redisRepository,
+				MetadataStore: s.metadataStore,
+				KeyWrapper:    s.keyWrapper,
+				Tenants:       s.tenants,
 			},
 		)
 
-		upload.GET("", uploadController.GetSignedURL)
-		upload.GET("/folder", uploadController.ListFolder)
-		upload.POST("", uploadController.Upload)
-		upload.POST("/list", uploadController.ListObjects)
-		upload.DELETE("", uploadController.Delete)
-		upload.DELETE("/all", uploadController.DeleteAll)
+		v1.POST("/share", append(s.authMiddlewares(), middlewares.ScopeMiddleware(apikeys.ScopeRead), shareController.Create)...)
+		// The share links are public, the token is the credential.
+		v1.GET("/s/:token", shareController.Resolve)
+		v1.POST("/s/:token", shareController.Resolve)
+	}
+
+	admin := v1.Group("/admin", append(s.authMiddlewares(), middlewares.AdminMiddleware())...)
+	{
+		admin.GET("/quarantine", moderation((*controllers.ModerationController).ListQuarantined))
+		admin.POST("/quarantine/approve", moderation((*controllers.ModerationController).Approve))
+		admin.POST("/quarantine/reject", moderation((*controllers.ModerationController).Reject))
+
+		if s.apiKeys != nil {
+			apiKeyController := controllers.NewApiKeyController(
+				&controllers.UploadConfig{
+					ApiKeys: s.apiKeys,
+					Tenants: s.tenants,
+				},
+			)
+
+			admin.GET("/apikeys", apiKeyController.List)
+			admin.POST("/apikeys", apiKeyController.Create)
+			admin.POST("/apikeys/rotate", apiKeyController.Rotate)
+			admin.DELETE("/apikeys", apiKeyController.Revoke)
+		}
 	}
 
 	return nil
 }
+
+// authMiddlewares returns the middlewares that resolve the request tenant and principal.
+func (s *Server) authMiddlewares() []gin.HandlerFunc {
+	handlers := []gin.HandlerFunc{middlewares.TenantMiddleware(s.tenants, s.apiKeys)}
+	if s.keyFunc != nil {
+		handlers = append(handlers, middlewares.AuthMiddleware(s.authConfig, s.keyFunc, s.tenants))
+	}
+
+	return handlers
+}
+
+// tenantControllers are the controllers of a tenant, configured with its stores and limits.
+type tenantControllers struct {
+	upload     *controllers.UploadController
+	cookie     *controllers.CookieController
+	folder     *controllers.FolderController
+	archive    *controllers.ArchiveController
+	version    *controllers.VersionController
+	moderation *controllers.ModerationController
+	usage      *controllers.UsageController
+}
+
+// newTenantControllers returns the tenant controllers.
+// The default tenant messages aren't tagged, so they're processed with the main configuration.
+func (s *Server) newTenantControllers(tenant *tenants.Tenant) *tenantControllers {
+	routeCfg := tenant.RouteConfig(s.routes[config.Upload])
+	redisRepository := tenant.Redis(s.redisRepository)
+
+	var tenantId string
+	if !tenant.IsDefault() {
+		tenantId = tenant.Id
+	}
+
+	return &tenantControllers{
+		upload: controllers.NewUploadController(
+			&controllers.UploadConfig{
+				RouteConfig:       routeCfg,
+				PartitionKey:      s.partitionKey,
+				Publisher:         s.publisher,
+				AWSRepository:     tenant.AWSRepository,
+				BlobStore:         tenant.BlobStore,
+				MetadataStore:     tenant.MetadataStore,
+				RedisRepository:   redisRepository,
+				KeyWrapper:        s.keyWrapper,
+				SigningConfig:     s.signingConfig,
+				TenantId:          tenantId,
+				AllowedStrategies: tenant.AllowedStrategies,
+				MaxFileSize:       tenant.MaxFileSize,
+				Quota:             tenant.Quota,
+			},
+		),
+		cookie: controllers.NewCookieController(
+			&controllers.UploadConfig{
+				BlobStore:     tenant.BlobStore,
+				SigningConfig: s.signingConfig,
+			},
+		),
+		folder: controllers.NewFolderController(
+			&controllers.UploadConfig{
+				RouteConfig:     routeCfg,
+				BlobStore:       tenant.BlobStore,
+				MetadataStore:   tenant.MetadataStore,
+				RedisRepository: redisRepository,
+			},
+		),
+		archive: controllers.NewArchiveController(
+			&controllers.UploadConfig{
+				RouteConfig:   routeCfg,
+				BlobStore:     tenant.BlobStore,
+				MetadataStore: tenant.MetadataStore,
+				KeyWrapper:    s.keyWrapper,
+			},
+		),
+		version: controllers.NewVersionController(
+			&controllers.UploadConfig{
+				RouteConfig:       routeCfg,
+				PartitionKey:      s.partitionKey,
+				Publisher:         s.publisher,
+				AWSRepository:     tenant.AWSRepository,
+				BlobStore:         tenant.BlobStore,
+				MetadataStore:     tenant.MetadataStore,
+				RedisRepository:   redisRepository,
+				KeyWrapper:        s.keyWrapper,
+				SigningConfig:     s.signingConfig,
+				TenantId:          tenantId,
+				AllowedStrategies: tenant.AllowedStrategies,
+				MaxFileSize:       tenant.MaxFileSize,
+				Quota:             tenant.Quota,
+			},
+		),
+		moderation: controllers.NewModerationController(
+			&controllers.UploadConfig{
+				RouteConfig:     routeCfg,
+				AWSRepository:   tenant.AWSRepository,
+				BlobStore:       tenant.BlobStore,
+				MetadataStore:   tenant.MetadataStore,
+				RedisRepository: redisRepository,
+			},
+		),
+		usage: controllers.NewUsageController(
+			&controllers.UploadConfig{
+				MetadataStore: tenant.MetadataStore,
+				Quota:         tenant.Quota,
+			},
+		),
+	}
+}
+
+// tenantHandler returns the handler that calls the method of the request tenant controller.
+func tenantHandler[T any](
+	byTenant map[string]*tenantControllers, controller func(*tenantControllers) T, method func(T, *gin.Context),
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method(controller(byTenant[tenants.FromContext(c).Id]), c)
+	}
+}
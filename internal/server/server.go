@@ -5,10 +5,16 @@ import (
 	_ "net/http/pprof"
 
 	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/internal/apikeys"
 	"github.com/gearpoint/filepoint/internal/middlewares"
+	"github.com/gearpoint/filepoint/internal/tenants"
 	"github.com/gearpoint/filepoint/pkg/aws_repository"
+	"github.com/gearpoint/filepoint/pkg/envelope"
+	"github.com/gearpoint/filepoint/pkg/metadata"
 	"github.com/gearpoint/filepoint/pkg/redis"
+	"github.com/gearpoint/filepoint/pkg/storage"
 	"github.com/gin-gonic/gin"
 )
 
@@ -19,7 +25,20 @@ type ServerConfig struct {
 	PartitionKey    string
 	Publisher       message.Publisher
 	AWSRepository   *aws_repository.AWSRepository
+	BlobStore       storage.BlobStore
+	MetadataStore   metadata.MetadataStore
 	RedisRepository *redis.RedisRepository
+	KeyWrapper      envelope.KeyWrapper
+	SigningConfig   config.SigningConfig
+	// Tenants are the tenants resolved from the requests.
+	// When nil, only the default tenant is registered, with the server stores.
+	Tenants *tenants.Registry
+	// AuthConfig and KeyFunc configure the JWT authentication.
+	// The authentication is disabled when KeyFunc is nil.
+	AuthConfig config.AuthConfig
+	KeyFunc    jwt.Keyfunc
+	// ApiKeys resolves the managed API keys. Only the tenants static keys are accepted when nil.
+	ApiKeys *apikeys.Manager
 }
 
 // Server struct.
@@ -30,11 +49,28 @@ type Server struct {
 	partitionKey    string
 	publisher       message.Publisher
 	awsRepository   *aws_repository.AWSRepository
+	blobStore       storage.BlobStore
+	metadataStore   metadata.MetadataStore
 	redisRepository *redis.RedisRepository
+	keyWrapper      envelope.KeyWrapper
+	signingConfig   config.SigningConfig
+	tenants         *tenants.Registry
+	authConfig      config.AuthConfig
+	keyFunc         jwt.Keyfunc
+	apiKeys         *apikeys.Manager
 }
 
 // NewServer is the Server constructor.
 func NewServer(serverConfig ServerConfig) *Server {
+	registry := serverConfig.Tenants
+	if registry == nil {
+		registry = tenants.NewRegistry(&tenants.Tenant{
+			AWSRepository: serverConfig.AWSRepository,
+			BlobStore:     serverConfig.BlobStore,
+			MetadataStore: serverConfig.MetadataStore,
+		})
+	}
+
 	return &Server{
 		Engine:          gin.New(),
 		config:          serverConfig.Config,
@@ -42,7 +78,15 @@ func NewServer(serverConfig ServerConfig) *Server {
 		partitionKey:    serverConfig.PartitionKey,
 		publisher:       serverConfig.Publisher,
 		awsRepository:   serverConfig.AWSRepository,
+		blobStore:       serverConfig.BlobStore,
+		metadataStore:   serverConfig.MetadataStore,
 		redisRepository: serverConfig.RedisRepository,
+		keyWrapper:      serverConfig.KeyWrapper,
+		signingConfig:   serverConfig.SigningConfig,
+		tenants:         registry,
+		authConfig:      serverConfig.AuthConfig,
+		keyFunc:         serverConfig.KeyFunc,
+		apiKeys:         serverConfig.ApiKeys,
 	}
 }
 
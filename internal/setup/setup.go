@@ -0,0 +1,255 @@
+// Package setup builds the stores, signers and tenants shared by the Filepoint binaries.
+package setup
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	config "github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/internal/tenants"
+	"github.com/gearpoint/filepoint/internal/uploader"
+	"github.com/gearpoint/filepoint/internal/uploader/strategies"
+	"github.com/gearpoint/filepoint/pkg/aws_repository"
+	"github.com/gearpoint/filepoint/pkg/envelope"
+	"github.com/gearpoint/filepoint/pkg/logger"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"go.uber.org/zap"
+)
+
+// AWSRepository returns the AWS repository, or nil when no AWS service is used.
+func AWSRepository(cfg *config.Config) *aws_repository.AWSRepository {
+	if !cfg.UsesAWS() {
+		return nil
+	}
+
+	awsRepository, err := aws_repository.NewAWSRepository(&cfg.AWSConfig, context.Background())
+	if err != nil {
+		logger.Fatal("cannot initialize AWS repository",
+			zap.Error(err),
+		)
+	}
+	logger.Info("AWS connected")
+
+	return awsRepository
+}
+
+// BlobStore returns the blob store configured in the StorageConfig.
+func BlobStore(cfg *config.Config, awsRepository *aws_repository.AWSRepository) storage.BlobStore {
+	storageCfg := cfg.StorageConfig
+
+	switch storage.Backend(storageCfg.Backend) {
+	case storage.S3:
+		awsRepository.SetSigner(URLSigner(cfg, awsRepository))
+		return awsRepository
+	case storage.FileSystem:
+		blobStore, err := storage.NewFileSystemStore(
+			storageCfg.RootDir,
+			URLSigner(cfg, awsRepository),
+		)
+		if err != nil {
+			logger.Fatal("error initializing the filesystem storage",
+				zap.Error(err),
+			)
+		}
+		return blobStore
+	default:
+		log.Fatal("error initializing the blob store - unrecognized backend")
+	}
+
+	return nil
+}
+
+// URLSigner returns the URL signer configured in the StorageConfig.
+// The cloudfront and s3 signers only sign the S3 objects.
+func URLSigner(cfg *config.Config, awsRepository *aws_repository.AWSRepository) storage.URLSigner {
+	storageCfg := cfg.StorageConfig
+
+	signer := storage.SignerBackend(storageCfg.Signer)
+	if signer == "" {
+		signer = storage.Cloudfront
+		if storage.Backend(storageCfg.Backend) == storage.FileSystem {
+			signer = storage.HMAC
+		}
+	}
+
+	if signer != storage.HMAC && storage.Backend(storageCfg.Backend) != storage.S3 {
+		log.Fatal("error initializing the URL signer - the signer requires the s3 backend")
+	}
+
+	switch signer {
+	case storage.Cloudfront:
+		urlSigner, err := aws_repository.NewCloudfrontSigner(
+			cfg.AWSConfig.CloudfrontDist,
+			cfg.AWSConfig.CloudfrontKeyId,
+			cfg.AWSConfig.CloudfrontCrtFile,
+		)
+		if err != nil {
+			logger.Fatal("error initializing the Cloudfront signer",
+				zap.Error(err),
+			)
+		}
+		return urlSigner
+	case storage.S3Presign:
+		return aws_repository.NewS3Presigner(awsRepository)
+	case storage.HMAC:
+		return storage.NewHMACSigner(storageCfg.BaseURL, storageCfg.SigningKey)
+	default:
+		log.Fatal("error initializing the URL signer - unrecognized signer")
+	}
+
+	return nil
+}
+
+// MetadataStore returns the route metadata store configured in the MetadataConfig.
+func MetadataStore(cfg *config.Config, routeCfg config.RouteConfig, awsRepository *aws_repository.AWSRepository) metadata.MetadataStore {
+	switch metadata.Backend(cfg.MetadataConfig.Backend) {
+	case metadata.DynamoDB:
+		return aws_repository.NewDynamoDBMetadataStore(
+			awsRepository, routeCfg.TableName, routeCfg.IndexTableName, routeCfg.FolderTableName, routeCfg.ApiKeyTableName,
+			routeCfg.ShareTableName, routeCfg.UsageTableName,
+		)
+	case metadata.Postgres:
+		metadataStore, err := metadata.NewPostgresStore(cfg.MetadataConfig.PostgresDSN)
+		if err != nil {
+			logger.Fatal("error initializing the PostgreSQL metadata store",
+				zap.Error(err),
+			)
+		}
+		return metadataStore
+	case metadata.Memory:
+		return metadata.NewMemoryStore()
+	default:
+		log.Fatal("error initializing the metadata store - unrecognized backend")
+	}
+
+	return nil
+}
+
+// Tenants returns the tenants registry, with the default tenant configured by the main configuration.
+// The tenants share the main bucket under their KeyPrefix, unless they have their own bucket.
+func Tenants(
+	cfg *config.Config, routeCfg config.RouteConfig, awsRepository *aws_repository.AWSRepository,
+	blobStore storage.BlobStore, metadataStore metadata.MetadataStore,
+) *tenants.Registry {
+	registry := tenants.NewRegistry(&tenants.Tenant{
+		AWSRepository: awsRepository,
+		BlobStore:     blobStore,
+		MetadataStore: metadataStore,
+		Quota:         cfg.QuotaConfig,
+	})
+
+	for tenantId, tenantCfg := range cfg.Tenants {
+		if tenantId == tenants.DefaultTenant {
+			log.Fatal("error initializing the tenants - the default tenant id is reserved")
+		}
+
+		for _, strategy := range tenantCfg.AllowedStrategies {
+			if _, err := uploader.GetUploaderByEventType(strategies.EventTypeKey(strategy)); err != nil {
+				log.Fatalf("error initializing the tenant %s - unrecognized strategy %s", tenantId, strategy)
+			}
+		}
+
+		tenantConfig := *cfg
+		tenantRepository := awsRepository
+		objectStore := blobStore
+
+		if tenantCfg.Bucket != "" || tenantCfg.CloudfrontDist != "" {
+			if storage.Backend(cfg.StorageConfig.Backend) != storage.S3 {
+				log.Fatalf("error initializing the tenant %s - the bucket requires the s3 backend", tenantId)
+			}
+
+			if tenantCfg.Bucket != "" {
+				tenantConfig.AWSConfig.Bucket = tenantCfg.Bucket
+			}
+			if tenantCfg.CloudfrontDist != "" {
+				tenantConfig.AWSConfig.CloudfrontDist = tenantCfg.CloudfrontDist
+			}
+
+			var err error
+			tenantRepository, err = aws_repository.NewAWSRepository(&tenantConfig.AWSConfig, context.Background())
+			if err != nil {
+				logger.Fatal("cannot initialize the tenant AWS repository",
+					zap.String("tenant", tenantId),
+					zap.Error(err),
+				)
+			}
+			objectStore = BlobStore(&tenantConfig, tenantRepository)
+		}
+
+		// The HMAC signed URLs are served from the store of the key prefix.
+		keyPrefix := strings.Trim(tenantCfg.KeyPrefix, "/")
+		_, hmacSigned := objectStore.Signer().(*storage.HMACSigner)
+		if keyPrefix == "" && (objectStore == blobStore || hmacSigned) {
+			log.Fatalf("error initializing the tenant %s - the tenant requires a KeyPrefix", tenantId)
+		}
+
+		tenantStore := objectStore
+		if keyPrefix != "" {
+			tenantStore = storage.NewPrefixedStore(objectStore, keyPrefix)
+		}
+
+		tenantRouteCfg := routeCfg
+		switch metadata.Backend(cfg.MetadataConfig.Backend) {
+		case metadata.DynamoDB:
+			if tenantCfg.TableName == "" || tenantCfg.IndexTableName == "" || tenantCfg.FolderTableName == "" {
+				log.Fatalf("error initializing the tenant %s - the tenant requires its own DynamoDB tables", tenantId)
+			}
+			tenantRouteCfg.TableName = tenantCfg.TableName
+			tenantRouteCfg.IndexTableName = tenantCfg.IndexTableName
+			tenantRouteCfg.FolderTableName = tenantCfg.FolderTableName
+			tenantRouteCfg.UsageTableName = tenantCfg.UsageTableName
+			// The API keys and shares are kept in the main store.
+			tenantRouteCfg.ApiKeyTableName = ""
+			tenantRouteCfg.ShareTableName = ""
+		case metadata.Postgres:
+			if tenantCfg.PostgresDSN == "" {
+				log.Fatalf("error initializing the tenant %s - the tenant requires its own PostgresDSN", tenantId)
+			}
+			tenantConfig.MetadataConfig.PostgresDSN = tenantCfg.PostgresDSN
+		}
+
+		registry.Add(&tenants.Tenant{
+			Id:                tenantId,
+			AWSRepository:     tenantRepository,
+			BlobStore:         tenantStore,
+			ObjectStore:       objectStore,
+			KeyPrefix:         keyPrefix,
+			MetadataStore:     MetadataStore(&tenantConfig, tenantRouteCfg, tenantRepository),
+			WebhookURL:        tenantCfg.WebhookURL,
+			AllowedStrategies: tenantCfg.AllowedStrategies,
+			MaxFileSize:       tenantCfg.MaxFileSize,
+			Quota:             tenantCfg.QuotaConfig.WithDefaults(cfg.QuotaConfig),
+		}, tenantCfg.ApiKeys...)
+	}
+
+	return registry
+}
+
+// KeyWrapper returns the master key configured in the EncryptionConfig.
+// It returns nil without a backend, which is only allowed when no route is encrypted.
+func KeyWrapper(cfg *config.Config) envelope.KeyWrapper {
+	encryptionCfg := cfg.EncryptionConfig
+
+	switch envelope.Backend(encryptionCfg.Backend) {
+	case envelope.Local:
+		keyWrapper, err := envelope.NewLocalKeyWrapper(encryptionCfg.KeyFile)
+		if err != nil {
+			logger.Fatal("error initializing the local master key",
+				zap.Error(err),
+			)
+		}
+		return keyWrapper
+	case "":
+		for _, routeCfg := range cfg.Routes {
+			if routeCfg.Encrypted {
+				log.Fatal("error initializing the encryption - the encrypted routes require a master key")
+			}
+		}
+	default:
+		log.Fatal("error initializing the encryption - unrecognized backend")
+	}
+
+	return nil
+}
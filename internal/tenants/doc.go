@@ -0,0 +1,2 @@
+// Package tenants contains the tenants registry, resolved from the requests.
+package tenants
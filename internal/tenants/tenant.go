@@ -0,0 +1,178 @@
+package tenants
+
+import (
+	"crypto/sha256"
+	"sort"
+	"strings"
+
+	"github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/pkg/aws_repository"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"github.com/gearpoint/filepoint/pkg/redis"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultTenant is the id of the tenant configured by the main configuration.
+	DefaultTenant = "default"
+
+	// The gin context key of the request tenant.
+	contextKey = "tenant"
+)
+
+// Tenant contains a tenant stores and limits.
+type Tenant struct {
+	Id            string
+	AWSRepository *aws_repository.AWSRepository
+	// BlobStore is the tenant store, with the keys prefixed by the KeyPrefix.
+	BlobStore storage.BlobStore
+	// ObjectStore is the store that keeps the tenant objects, without the key prefix.
+	ObjectStore   storage.BlobStore
+	KeyPrefix     string
+	MetadataStore metadata.MetadataStore
+	// WebhookURL overrides the route webhook URL when set.
+	WebhookURL string
+	// AllowedStrategies are the accepted upload strategies. All the strategies are accepted when empty.
+	AllowedStrategies []string
+	// MaxFileSize is the max uploaded file size, in bytes. Zero doesn't limit the size.
+	MaxFileSize int64
+	// Quota is the tenant storage quotas.
+	Quota config.QuotaConfig
+}
+
+// IsDefault checks if the tenant is the default tenant.
+func (t *Tenant) IsDefault() bool {
+	return t.Id == DefaultTenant
+}
+
+// RouteConfig returns the route config with the tenant webhook URL.
+func (t *Tenant) RouteConfig(routeCfg config.RouteConfig) config.RouteConfig {
+	if t.WebhookURL != "" {
+		routeCfg.WebhookURL = t.WebhookURL
+	}
+
+	return routeCfg
+}
+
+// Redis returns the repository with the tenant cache keys.
+// The default tenant keeps the global keys.
+func (t *Tenant) Redis(redisRepository *redis.RedisRepository) *redis.RedisRepository {
+	if t.IsDefault() {
+		return redisRepository
+	}
+
+	return redisRepository.WithNamespace(t.Id)
+}
+
+// Registry contains the configured tenants.
+type Registry struct {
+	defaultTenant *Tenant
+	tenants       map[string]*Tenant
+	apiKeys       map[[sha256.Size]byte]*Tenant
+}
+
+// NewRegistry returns a registry with the default tenant.
+func NewRegistry(defaultTenant *Tenant) *Registry {
+	defaultTenant.Id = DefaultTenant
+	if defaultTenant.ObjectStore == nil {
+		defaultTenant.ObjectStore = defaultTenant.BlobStore
+	}
+
+	return &Registry{
+		defaultTenant: defaultTenant,
+		tenants:       map[string]*Tenant{DefaultTenant: defaultTenant},
+		apiKeys:       map[[sha256.Size]byte]*Tenant{},
+	}
+}
+
+// Add registers the tenant with its API keys. Only the keys hashes are kept.
+func (r *Registry) Add(tenant *Tenant, apiKeys ...string) {
+	if tenant.ObjectStore == nil {
+		tenant.ObjectStore = tenant.BlobStore
+	}
+
+	r.tenants[tenant.Id] = tenant
+	for _, apiKey := range apiKeys {
+		r.apiKeys[sha256.Sum256([]byte(apiKey))] = tenant
+	}
+}
+
+// Get returns the tenant with the id. The empty id is the default tenant.
+func (r *Registry) Get(id string) (*Tenant, bool) {
+	if id == "" {
+		return r.defaultTenant, true
+	}
+
+	tenant, ok := r.tenants[id]
+
+	return tenant, ok
+}
+
+// Default returns the default tenant.
+func (r *Registry) Default() *Tenant {
+	return r.defaultTenant
+}
+
+// Tenants returns all the tenants, sorted by id.
+func (r *Registry) Tenants() []*Tenant {
+	tenants := make([]*Tenant, 0, len(r.tenants))
+	for _, tenant := range r.tenants {
+		tenants = append(tenants, tenant)
+	}
+
+	sort.Slice(tenants, func(i, j int) bool {
+		return tenants[i].Id < tenants[j].Id
+	})
+
+	return tenants
+}
+
+// ResolveApiKey returns the tenant of the API key.
+// The keys are compared by their hashes, so the lookup time doesn't depend on the key content.
+func (r *Registry) ResolveApiKey(apiKey string) (*Tenant, bool) {
+	if apiKey == "" {
+		return nil, false
+	}
+
+	tenant, ok := r.apiKeys[sha256.Sum256([]byte(apiKey))]
+
+	return tenant, ok
+}
+
+// ObjectStore returns the store that keeps the object key.
+// The key is matched against the tenants key prefixes, the longest one wins.
+func (r *Registry) ObjectStore(objectKey string) storage.BlobStore {
+	store := r.defaultTenant.ObjectStore
+	matched := 0
+
+	for _, tenant := range r.tenants {
+		if tenant.KeyPrefix == "" || len(tenant.KeyPrefix) <= matched {
+			continue
+		}
+
+		if strings.HasPrefix(objectKey, tenant.KeyPrefix+"/") {
+			store = tenant.ObjectStore
+			matched = len(tenant.KeyPrefix)
+		}
+	}
+
+	return store
+}
+
+// SetContext sets the request tenant.
+func SetContext(c *gin.Context, tenant *Tenant) {
+	c.Set(contextKey, tenant)
+}
+
+// FromContext returns the request tenant, or nil when it wasn't resolved.
+func FromContext(c *gin.Context) *Tenant {
+	value, ok := c.Get(contextKey)
+	if !ok {
+		return nil
+	}
+
+	tenant, _ := value.(*Tenant)
+
+	return tenant
+}
@@ -0,0 +1,57 @@
+package tenants
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	base, err := storage.NewFileSystemStore(t.TempDir(), storage.NewHMACSigner("http://localhost/v1/files", "secret"))
+	assert.Nil(t, err)
+	other, err := storage.NewFileSystemStore(t.TempDir(), storage.NewHMACSigner("http://localhost/v1/files", "secret"))
+	assert.Nil(t, err)
+
+	registry := NewRegistry(&Tenant{BlobStore: base})
+	registry.Add(&Tenant{
+		Id:          "acme",
+		BlobStore:   storage.NewPrefixedStore(base, "acme"),
+		ObjectStore: base,
+		KeyPrefix:   "acme",
+		WebhookURL:  "http://acme.local/webhooks",
+	}, "acme-key")
+	registry.Add(&Tenant{Id: "globex", BlobStore: other, KeyPrefix: "acme/globex"}, "globex-key")
+
+	tenant, ok := registry.ResolveApiKey("acme-key")
+	assert.True(t, ok)
+	assert.Equal(t, "acme", tenant.Id)
+	assert.Equal(t, base, tenant.ObjectStore)
+
+	_, ok = registry.ResolveApiKey("invalid-key")
+	assert.False(t, ok)
+
+	tenant, ok = registry.Get("")
+	assert.True(t, ok)
+	assert.True(t, tenant.IsDefault())
+
+	_, ok = registry.Get("missing")
+	assert.False(t, ok)
+
+	assert.Len(t, registry.Tenants(), 3)
+	assert.Equal(t, base, registry.ObjectStore("acme/user/file/original.png"))
+	assert.Equal(t, other, registry.ObjectStore("acme/globex/user/file/original.png"))
+	assert.Equal(t, base, registry.ObjectStore("acmeuser/file/original.png"))
+
+	acme, _ := registry.Get("acme")
+	routeCfg := acme.RouteConfig(config.RouteConfig{WebhookURL: "http://default.local/webhooks"})
+	assert.Equal(t, "http://acme.local/webhooks", routeCfg.WebhookURL)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	assert.Nil(t, FromContext(c))
+	SetContext(c, acme)
+	assert.Equal(t, acme, FromContext(c))
+}
@@ -0,0 +1,39 @@
+// archive_type contains the archive upload implementations.
+// The archives aren't stored, their entries are extracted as individual files.
+package archive_type
+
+import (
+	"github.com/gearpoint/filepoint/internal/uploader/strategies"
+	"github.com/gearpoint/filepoint/pkg/utils"
+)
+
+const (
+	// The uploader event type key.
+	Key strategies.EventTypeKey = "archive"
+
+	// Defines the upload max size in bytes. Current: 200 mebibytes.
+	uploadMaxSize int64 = 200 << 20
+)
+
+// ArchiveUploader is the archive uploader implementation.
+type ArchiveUploader struct {
+	strategies.BaseUploader
+}
+
+// NewUploader returns a new Uploader instance.
+// It has no file definitions, as the archive entries are uploaded by their own strategies.
+func NewUploader() strategies.Uploader {
+	uploader := &ArchiveUploader{
+		BaseUploader: strategies.BaseUploader{
+			UploadMaxSize: uploadMaxSize,
+		},
+	}
+	uploader.SetContentTypes(utils.ContentTypeMapping{
+		"application/zip":              "zip",
+		"application/x-zip-compressed": "zip",
+		"application/x-tar":            "tar",
+	})
+	uploader.SetFileDefinitions(utils.FileDefinitionsMapping{})
+
+	return uploader
+}
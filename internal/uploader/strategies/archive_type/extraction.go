@@ -0,0 +1,248 @@
+package archive_type
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gearpoint/filepoint/internal/views"
+)
+
+var (
+	// ErrUnsupportedArchive is returned when the archive content type can't be extracted.
+	ErrUnsupportedArchive = errors.New("unsupported archive type")
+	// ErrArchiveLimits is returned when the whole archive breaks the extraction limits.
+	ErrArchiveLimits = errors.New("the archive exceeds the extraction limits")
+)
+
+// Limits are the extraction limits, against zip bombs.
+type Limits struct {
+	// MaxEntries is the max number of extracted files.
+	MaxEntries int
+	// MaxEntrySize is the max uncompressed size of a file.
+	MaxEntrySize int64
+	// MaxTotalSize is the max uncompressed size of all the files.
+	MaxTotalSize int64
+	// MaxRatio is the max compression ratio of a file (uncompressed / compressed size).
+	MaxRatio uint64
+}
+
+// DefaultLimits are the archive uploads extraction limits.
+var DefaultLimits = Limits{
+	MaxEntries:   1000,
+	MaxEntrySize: 100 << 20,
+	MaxTotalSize: 1 << 30,
+	MaxRatio:     100,
+}
+
+// Entry is an archive file.
+type Entry struct {
+	// Name is the cleaned path of the file in the archive.
+	Name string
+	Size int64
+}
+
+// EntryHandler handles an extracted file. The returned error rejects the entry.
+type EntryHandler func(entry *Entry, reader io.Reader) error
+
+// Extract reads the archive files, calling the handler with each one.
+// The unsafe or oversized entries are rejected and the extraction goes on,
+// until the entries count or the total size limit is reached.
+func Extract(filename string, contentType string, limits Limits, handler EntryHandler) ([]views.RejectedArchiveEntry, error) {
+	switch contentType {
+	case "application/zip", "application/x-zip-compressed":
+		return extractZip(filename, limits, handler)
+	case "application/x-tar":
+		return extractTar(filename, limits, handler)
+	default:
+		return nil, ErrUnsupportedArchive
+	}
+}
+
+// extractZip extracts the zip archive. The declared sizes are checked before any entry is read.
+func extractZip(filename string, limits Limits, handler EntryHandler) ([]views.RejectedArchiveEntry, error) {
+	archive, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	var totalSize uint64
+	for _, file := range archive.File {
+		totalSize += file.UncompressedSize64
+	}
+	if totalSize > uint64(limits.MaxTotalSize) {
+		return nil, fmt.Errorf("%w: the uncompressed size is %d bytes", ErrArchiveLimits, totalSize)
+	}
+
+	extractor := newExtractor(limits, handler)
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		if file.CompressedSize64 > 0 && file.UncompressedSize64/file.CompressedSize64 > limits.MaxRatio {
+			extractor.reject(file.Name, "the compression ratio exceeds the limit")
+			continue
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			extractor.reject(file.Name, "the entry can't be read")
+			continue
+		}
+
+		done := extractor.extract(file.Name, int64(file.UncompressedSize64), reader)
+		reader.Close()
+		if done {
+			break
+		}
+	}
+
+	return extractor.rejected, nil
+}
+
+// extractTar extracts the tar archive. Only regular files are extracted, links are rejected.
+func extractTar(filename string, limits Limits, handler EntryHandler) ([]views.RejectedArchiveEntry, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	archive := tar.NewReader(file)
+	extractor := newExtractor(limits, handler)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return extractor.rejected, err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			continue
+		case tar.TypeReg:
+			if extractor.extract(header.Name, header.Size, archive) {
+				return extractor.rejected, nil
+			}
+		default:
+			extractor.reject(header.Name, "only regular files are extracted")
+		}
+	}
+
+	return extractor.rejected, nil
+}
+
+// extractor applies the limits to the archive entries.
+type extractor struct {
+	limits    Limits
+	handler   EntryHandler
+	entries   int
+	totalSize int64
+	rejected  []views.RejectedArchiveEntry
+}
+
+// newExtractor returns a new extractor instance.
+func newExtractor(limits Limits, handler EntryHandler) *extractor {
+	return &extractor{
+		limits:   limits,
+		handler:  handler,
+		rejected: []views.RejectedArchiveEntry{},
+	}
+}
+
+// extract checks the entry limits and calls the handler.
+// It returns true when the extraction must stop.
+func (e *extractor) extract(name string, size int64, reader io.Reader) bool {
+	entryName, ok := cleanEntryName(name)
+	if !ok {
+		e.reject(name, "unsafe entry name")
+		return false
+	}
+
+	if e.entries >= e.limits.MaxEntries {
+		e.reject(name, fmt.Sprintf("the archive has more than %d files", e.limits.MaxEntries))
+		return true
+	}
+
+	if size > e.limits.MaxEntrySize {
+		e.reject(name, "the entry size exceeds the limit")
+		return false
+	}
+
+	if e.totalSize+size > e.limits.MaxTotalSize {
+		e.reject(name, "the archive size exceeds the limit")
+		return true
+	}
+
+	e.entries++
+	e.totalSize += size
+
+	// The declared size is checked by the archive readers, the limit is a safeguard.
+	limited := io.LimitReader(reader, size)
+	if err := e.handler(&Entry{Name: entryName, Size: size}, limited); err != nil {
+		e.reject(name, err.Error())
+	}
+
+	return false
+}
+
+// reject adds the entry to the rejected ones.
+func (e *extractor) reject(name string, reason string) {
+	e.rejected = append(e.rejected, views.RejectedArchiveEntry{
+		Name:   name,
+		Reason: reason,
+	})
+}
+
+// cleanEntryName returns the cleaned entry path.
+// Absolute paths and paths outside the archive root (zip slip) are unsafe.
+func cleanEntryName(name string) (string, bool) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if name == "" || path.IsAbs(name) || strings.Contains(name, ":") {
+		return "", false
+	}
+
+	for _, segment := range strings.Split(name, "/") {
+		if segment == ".." {
+			return "", false
+		}
+	}
+
+	return path.Clean(name), true
+}
+
+// ContentType returns the entry content type, from its name extension or sniffed from the extracted file.
+func ContentType(name string, filename string) string {
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+			return mediaType
+		}
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(file, head)
+
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if err != nil {
+		return ""
+	}
+
+	return mediaType
+}
@@ -0,0 +1,131 @@
+package archive_type
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEntry struct {
+	name    string
+	content string
+}
+
+func createZip(t *testing.T, entries []testEntry) string {
+	filename := filepath.Join(t.TempDir(), "archive.zip")
+	file, err := os.Create(filename)
+	assert.Nil(t, err)
+	defer file.Close()
+
+	writer := zip.NewWriter(file)
+	for _, entry := range entries {
+		w, err := writer.Create(entry.name)
+		assert.Nil(t, err)
+		_, err = w.Write([]byte(entry.content))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, writer.Close())
+
+	return filename
+}
+
+func collect(extracted map[string]string) EntryHandler {
+	return func(entry *Entry, reader io.Reader) error {
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		extracted[entry.Name] = string(content)
+
+		return nil
+	}
+}
+
+func TestExtractZip(t *testing.T) {
+	filename := createZip(t, []testEntry{
+		{"docs/readme.txt", "readme"},
+		{"docs/", ""},
+		{"../outside.txt", "outside"},
+		{"/etc/passwd", "root"},
+		{"bomb.txt", strings.Repeat("0", 1<<20)},
+	})
+
+	extracted := map[string]string{}
+	rejected, err := Extract(filename, "application/zip", DefaultLimits, collect(extracted))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"docs/readme.txt": "readme"}, extracted)
+
+	reasons := map[string]string{}
+	for _, entry := range rejected {
+		reasons[entry.Name] = entry.Reason
+	}
+	assert.Equal(t, map[string]string{
+		"../outside.txt": "unsafe entry name",
+		"/etc/passwd":    "unsafe entry name",
+		"bomb.txt":       "the compression ratio exceeds the limit",
+	}, reasons)
+}
+
+func TestExtractZipLimits(t *testing.T) {
+	filename := createZip(t, []testEntry{
+		{"a.txt", "aaaa"},
+		{"b.txt", "bbbbbbbbbbbb"},
+		{"c.txt", "cccc"},
+		{"d.txt", "dddd"},
+	})
+
+	limits := Limits{MaxEntries: 2, MaxEntrySize: 8, MaxTotalSize: 64, MaxRatio: 100}
+	extracted := map[string]string{}
+	rejected, err := Extract(filename, "application/zip", limits, collect(extracted))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"a.txt": "aaaa", "c.txt": "cccc"}, extracted)
+	assert.Len(t, rejected, 2)
+	assert.Equal(t, "the entry size exceeds the limit", rejected[0].Reason)
+	assert.Equal(t, "d.txt", rejected[1].Name)
+
+	limits.MaxTotalSize = 16
+	_, err = Extract(filename, "application/zip", limits, collect(map[string]string{}))
+	assert.ErrorIs(t, err, ErrArchiveLimits)
+}
+
+func TestExtractTar(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "archive.tar")
+	file, err := os.Create(filename)
+	assert.Nil(t, err)
+
+	writer := tar.NewWriter(file)
+	assert.Nil(t, writer.WriteHeader(&tar.Header{Name: "photo.txt", Typeflag: tar.TypeReg, Size: 5, Mode: 0644}))
+	_, err = writer.Write([]byte("photo"))
+	assert.Nil(t, err)
+	assert.Nil(t, writer.WriteHeader(&tar.Header{Name: "link.txt", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}))
+	assert.Nil(t, writer.WriteHeader(&tar.Header{Name: "a/../../slip.txt", Typeflag: tar.TypeReg, Size: 4, Mode: 0644}))
+	_, err = writer.Write([]byte("slip"))
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+	file.Close()
+
+	extracted := map[string]string{}
+	rejected, err := Extract(filename, "application/x-tar", DefaultLimits, collect(extracted))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"photo.txt": "photo"}, extracted)
+	assert.Len(t, rejected, 2)
+	assert.Equal(t, "only regular files are extracted", rejected[0].Reason)
+	assert.Equal(t, "unsafe entry name", rejected[1].Reason)
+
+	_, err = Extract(filename, "application/gzip", DefaultLimits, collect(extracted))
+	assert.ErrorIs(t, err, ErrUnsupportedArchive)
+}
+
+func TestContentType(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "entry")
+	assert.Nil(t, os.WriteFile(filename, []byte("plain text"), 0644))
+
+	assert.Equal(t, "image/png", ContentType("photos/photo.png", filename))
+	assert.Equal(t, "text/plain", ContentType("notes", filename))
+}
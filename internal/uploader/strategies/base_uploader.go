@@ -8,7 +8,9 @@ import (
 	"os"
 
 	"github.com/gearpoint/filepoint/internal/views"
-	"github.com/gearpoint/filepoint/pkg/aws_repository"
+	"github.com/gearpoint/filepoint/pkg/envelope"
+	"github.com/gearpoint/filepoint/pkg/labeler"
+	"github.com/gearpoint/filepoint/pkg/storage"
 	"github.com/gearpoint/filepoint/pkg/utils"
 )
 
@@ -98,12 +100,17 @@ func (u *BaseUploader) Upload(filename string, reader io.ReadCloser) (string, er
 		"filename": u.config.UploadView.Filename,
 	}
 
-	err := u.config.AWSRepository.UploadChunks(
+	content, tags, err := u.EncryptContent(reader, u.ObjectTags(u.config.UploadView.Tags))
+	if err != nil {
+		return "", err
+	}
+
+	err = u.config.BlobStore.UploadChunks(
 		s3Prefix,
-		reader,
+		content,
 		u.config.UploadView.ContentType,
 		metadata,
-		nil,
+		storage.EncodeTagging(tags),
 	)
 	if err != nil {
 		return "", err
@@ -112,12 +119,49 @@ func (u *BaseUploader) Upload(filename string, reader io.ReadCloser) (string, er
 	return s3Prefix, nil
 }
 
+// ObjectTags returns the uploaded objects tags, with the configured tags.
+func (u *BaseUploader) ObjectTags(tags map[string]string) map[string]string {
+	if len(u.config.Tags) == 0 {
+		return tags
+	}
+
+	objectTags := make(map[string]string, len(tags)+len(u.config.Tags))
+	for key, value := range tags {
+		objectTags[key] = value
+	}
+	for key, value := range u.config.Tags {
+		objectTags[key] = value
+	}
+
+	return objectTags
+}
+
+// EncryptContent encrypts the object content with the configured data key, adding the encrypted tag.
+// The content is returned unchanged without the data key.
+func (u *BaseUploader) EncryptContent(reader io.Reader, tags map[string]string) (io.Reader, map[string]string, error) {
+	if u.config.DataKey == nil {
+		return reader, tags, nil
+	}
+
+	encrypted, err := envelope.NewEncryptReader(u.config.DataKey, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	encryptedTags := map[string]string{storage.EncryptedTag: "true"}
+	for key, value := range tags {
+		encryptedTags[key] = value
+	}
+
+	return encrypted, encryptedTags, nil
+}
+
 // UploadTemp uploads the file to S3 with lifecycle.
 func (u *BaseUploader) UploadTemp(reader io.ReadCloser) (string, error) {
-	s3Prefix := u.FormatPrefix(aws_repository.TempFileRule)
-	tagging := aws_repository.TempFileRule
+	s3Prefix := u.FormatPrefix(storage.TempFileRule)
+	tagging := storage.TempFileRule
 
-	err := u.config.AWSRepository.PutObject(
+	err := u.config.BlobStore.PutObject(
 		s3Prefix,
 		reader,
 		u.config.UploadView.ContentType,
@@ -133,9 +177,17 @@ func (u *BaseUploader) UploadTemp(reader io.ReadCloser) (string, error) {
 
 // DownloadTemp downloads the temp file from S3.
 func (u *BaseUploader) DownloadTemp(tempPrefix string) (io.ReadCloser, error) {
-	return u.config.AWSRepository.DownloadFile(tempPrefix)
+	return u.config.BlobStore.DownloadFile(tempPrefix)
+}
+
+// DetectLabels returns the file labels from the given object prefix.
+// The base strategy doesn't detect labels.
+func (u *BaseUploader) DetectLabels(prefix string) (*labeler.FileLabels, error) {
+	return nil, nil
 }
 
-// SetLabels starts the file label detection.
-func (u *BaseUploader) SetLabels(filename string) {
+// ExtractText returns the file text, from the object prefix or the local temp file.
+// The base strategy doesn't extract text.
+func (u *BaseUploader) ExtractText(prefix string, tempFilename string) (*views.TextDocument, error) {
+	return nil, nil
 }
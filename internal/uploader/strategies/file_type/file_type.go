@@ -2,6 +2,7 @@ package file_type
 
 import (
 	"github.com/gearpoint/filepoint/internal/uploader/strategies"
+	"github.com/gearpoint/filepoint/internal/views"
 	"github.com/gearpoint/filepoint/pkg/utils"
 )
 
@@ -35,3 +36,16 @@ func NewUploader() strategies.Uploader {
 
 	return uploader
 }
+
+// ExtractText returns the document text, read from the local temp file.
+// PDFs are read by page, from their text layer.
+func (u *FileUploader) ExtractText(prefix string, tempFilename string) (*views.TextDocument, error) {
+	switch u.Config().UploadView.ContentType {
+	case "application/pdf":
+		return extractPDFText(tempFilename)
+	case "text/plain":
+		return extractPlainText(tempFilename)
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,137 @@
+package file_type
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/labeler"
+	"github.com/ledongthuc/pdf"
+)
+
+const (
+	// The max number of extracted PDF pages.
+	maxTextPages = 500
+
+	// The default PDF page size (US Letter), in points.
+	defaultPageWidth  = 612
+	defaultPageHeight = 792
+
+	// The confidence of text that is read, not detected.
+	textLayerConfidence float32 = 100
+)
+
+// extractPlainText returns the text file lines as a single page document.
+func extractPlainText(tempFilename string) (*views.TextDocument, error) {
+	file, err := os.Open(tempFilename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []labeler.TextDetection
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		lines = append(lines, labeler.TextDetection{
+			Text:       line,
+			Confidence: textLayerConfidence,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &views.TextDocument{
+		Pages: []views.TextPage{{Number: 1, Lines: lines}},
+	}, nil
+}
+
+// extractPDFText returns the PDF text layer, by page.
+// Scanned documents without a text layer return empty pages.
+// The line bounding boxes are approximated from the text positions.
+func extractPDFText(tempFilename string) (*views.TextDocument, error) {
+	file, reader, err := pdf.Open(tempFilename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	document := &views.TextDocument{}
+
+	numPages := min(reader.NumPage(), maxTextPages)
+	for number := 1; number <= numPages; number++ {
+		page := reader.Page(number)
+		if page.V.IsNull() {
+			continue
+		}
+
+		rows, err := page.GetTextByRow()
+		if err != nil {
+			return nil, err
+		}
+
+		width, height := pageSize(page)
+
+		var lines []labeler.TextDetection
+		for _, row := range rows {
+			if len(row.Content) == 0 {
+				continue
+			}
+
+			var builder strings.Builder
+			minX, maxX := row.Content[0].X, row.Content[0].X
+			for _, text := range row.Content {
+				builder.WriteString(text.S)
+				minX = min(minX, text.X)
+				maxX = max(maxX, text.X)
+			}
+
+			line := strings.Join(strings.Fields(builder.String()), " ")
+			if line == "" {
+				continue
+			}
+
+			lines = append(lines, labeler.TextDetection{
+				Text:       line,
+				Confidence: textLayerConfidence,
+				BoundingBox: labeler.BoundingBox{
+					Left:  float32(minX / width),
+					Top:   float32(1 - float64(row.Position)/height),
+					Width: float32((maxX - minX) / width),
+				},
+			})
+		}
+
+		document.Pages = append(document.Pages, views.TextPage{
+			Number: number,
+			Lines:  lines,
+		})
+	}
+
+	return document, nil
+}
+
+// pageSize returns the page width and height from its media box, in points.
+func pageSize(page pdf.Page) (float64, float64) {
+	for v := page.V; !v.IsNull(); v = v.Key("Parent") {
+		mediaBox := v.Key("MediaBox")
+		if mediaBox.Len() == 4 {
+			width := mediaBox.Index(2).Float64() - mediaBox.Index(0).Float64()
+			height := mediaBox.Index(3).Float64() - mediaBox.Index(1).Float64()
+			if width > 0 && height > 0 {
+				return width, height
+			}
+		}
+	}
+
+	return defaultPageWidth, defaultPageHeight
+}
@@ -0,0 +1,31 @@
+package file_type
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractPlainText(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "invoice.txt")
+	err := os.WriteFile(filename, []byte("Invoice 42\n\n  Total: 10.00  \n"), 0600)
+	assert.Nil(t, err)
+
+	document, err := extractPlainText(filename)
+	assert.Nil(t, err)
+	assert.Len(t, document.Pages, 1)
+	assert.Len(t, document.Pages[0].Lines, 2)
+	assert.Equal(t, "Total: 10.00", document.Pages[0].Lines[1].Text)
+	assert.Equal(t, []string{"invoice", "42", "total", "10", "00"}, document.Terms())
+}
+
+func TestExtractPDFTextInvalidFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "invalid.pdf")
+	err := os.WriteFile(filename, []byte("not a pdf"), 0600)
+	assert.Nil(t, err)
+
+	_, err = extractPDFText(filename)
+	assert.Error(t, err)
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 
 	"github.com/gearpoint/filepoint/internal/uploader/strategies"
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/labeler"
 	"github.com/gearpoint/filepoint/pkg/utils"
 	"github.com/h2non/bimg"
 )
@@ -18,6 +20,13 @@ const (
 	uploadMaxSize int64 = 15 << 20
 )
 
+// labelContentTypes are the content types supported by the labelers.
+var labelContentTypes = utils.ContentTypeMapping{
+	"image/png":  "png",
+	"image/jpeg": "jpeg",
+	"image/jpg":  "jpg",
+}
+
 // ImageUploader is the image uploader implementation.
 type ImageUploader struct {
 	strategies.BaseUploader
@@ -118,10 +127,30 @@ func (u *ImageUploader) getProccessingOptions(definition utils.FileDefinitions)
 	return ruleset[definition]()
 }
 
-// SetLabels starts the image image rekognition labels.
-func (u *ImageUploader) SetLabels(filename string) {
-	// todo: save labels in DynamoDB
-	// todo: make sure it's jpeg or png
-	// s3Prefix := u.FormatPrefix(filename)
-	// u.Config().AWSRepository.GetImageLabels(s3Prefix)
+// DetectLabels returns the image labels, moderation labels and detected text.
+// Only JPEG and PNG files are labelled, as these are supported by every labeler.
+func (u *ImageUploader) DetectLabels(prefix string) (*labeler.FileLabels, error) {
+	imageLabeler := u.Config().Labeler
+	if imageLabeler == nil || !utils.CheckAllowedContentType(labelContentTypes, u.Config().UploadView.ContentType) {
+		return nil, nil
+	}
+
+	return labeler.DetectAll(imageLabeler, prefix)
+}
+
+// ExtractText returns the image detected text lines as a single page document.
+func (u *ImageUploader) ExtractText(prefix string, tempFilename string) (*views.TextDocument, error) {
+	imageLabeler := u.Config().Labeler
+	if imageLabeler == nil || !utils.CheckAllowedContentType(labelContentTypes, u.Config().UploadView.ContentType) {
+		return nil, nil
+	}
+
+	lines, err := imageLabeler.DetectText(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return &views.TextDocument{
+		Pages: []views.TextPage{{Number: 1, Lines: lines}},
+	}, nil
 }
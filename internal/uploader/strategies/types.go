@@ -6,6 +6,8 @@ import (
 
 	"github.com/gearpoint/filepoint/internal/views"
 	"github.com/gearpoint/filepoint/pkg/aws_repository"
+	"github.com/gearpoint/filepoint/pkg/labeler"
+	"github.com/gearpoint/filepoint/pkg/storage"
 	"github.com/gearpoint/filepoint/pkg/utils"
 )
 
@@ -28,12 +30,19 @@ type Uploader interface {
 	Upload(filename string, reader io.ReadCloser) (string, error)
 	DownloadTemp(tempPrefix string) (io.ReadCloser, error)
 	UploadTemp(reader io.ReadCloser) (string, error)
-	SetLabels(filename string)
+	DetectLabels(prefix string) (*labeler.FileLabels, error)
+	ExtractText(prefix string, tempFilename string) (*views.TextDocument, error)
 }
 
 // UploaderConfig contains the uploader strategy configuration.
 type UploaderConfig struct {
 	UploadView    *views.UploadPubSub
 	AWSRepository *aws_repository.AWSRepository
+	BlobStore     storage.BlobStore
+	Labeler       labeler.Labeler
 	Prefix        string
+	// DataKey encrypts the uploaded objects when it's set.
+	DataKey []byte
+	// Tags are added to the uploaded objects tags, like the quarantine tag.
+	Tags map[string]string
 }
@@ -6,7 +6,8 @@ import (
 	"os"
 
 	"github.com/gearpoint/filepoint/internal/uploader/strategies"
-	"github.com/gearpoint/filepoint/pkg/aws_repository"
+	"github.com/gearpoint/filepoint/pkg/labeler"
+	"github.com/gearpoint/filepoint/pkg/storage"
 	"github.com/gearpoint/filepoint/pkg/utils"
 )
 
@@ -64,12 +65,17 @@ func (u *VideoUploader) Upload(filename string, reader io.ReadCloser) (string, e
 		"filename": u.Config().UploadView.Filename,
 	}
 
-	err := u.Config().AWSRepository.UploadChunks(
+	content, tags, err := u.EncryptContent(reader, u.ObjectTags(nil))
+	if err != nil {
+		return "", err
+	}
+
+	err = u.Config().BlobStore.UploadChunks(
 		s3Prefix,
-		reader,
+		content,
 		u.Config().UploadView.ContentType,
 		metadata,
-		nil,
+		storage.EncodeTagging(tags),
 	)
 	if err != nil {
 		return "", err
@@ -80,10 +86,10 @@ func (u *VideoUploader) Upload(filename string, reader io.ReadCloser) (string, e
 
 // UploadTemp uploads the file to S3 with lifecycle.
 func (u *VideoUploader) UploadTemp(reader io.ReadCloser) (string, error) {
-	s3Prefix := u.FormatPrefix(aws_repository.TempFileRule)
-	tagging := aws_repository.TempFileRule
+	s3Prefix := u.FormatPrefix(storage.TempFileRule)
+	tagging := storage.TempFileRule
 
-	err := u.Config().AWSRepository.UploadChunks(
+	err := u.Config().BlobStore.UploadChunks(
 		s3Prefix,
 		reader,
 		u.Config().UploadView.ContentType,
@@ -97,9 +103,10 @@ func (u *VideoUploader) UploadTemp(reader io.ReadCloser) (string, error) {
 	return s3Prefix, nil
 }
 
-// SetLabels starts the video label detection.
-func (u *VideoUploader) SetLabels(filename string) {
-	// todo: save labels in DynamoDB
-	// s3Prefix := u.FormatPrefix(filename)
-	// u.Config().AWSRepository.StartVideoLabelsDetection(s3Prefix)
+// DetectLabels starts the video label detection.
+// The detection is asynchronous, so no labels are returned.
+func (u *VideoUploader) DetectLabels(prefix string) (*labeler.FileLabels, error) {
+	// todo: receive the video detection results from the notification channel.
+	// u.Config().AWSRepository.StartVideoLabelsDetection(prefix)
+	return nil, nil
 }
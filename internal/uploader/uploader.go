@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/gearpoint/filepoint/internal/uploader/strategies"
+	"github.com/gearpoint/filepoint/internal/uploader/strategies/archive_type"
 	"github.com/gearpoint/filepoint/internal/uploader/strategies/file_type"
 	"github.com/gearpoint/filepoint/internal/uploader/strategies/image_type"
 	"github.com/gearpoint/filepoint/internal/uploader/strategies/video_type"
@@ -13,9 +14,10 @@ import (
 type initUploader func() strategies.Uploader
 
 var uploadersMap = map[strategies.EventTypeKey]initUploader{
-	file_type.Key:  file_type.NewUploader,
-	image_type.Key: image_type.NewUploader,
-	video_type.Key: video_type.NewUploader,
+	archive_type.Key: archive_type.NewUploader,
+	file_type.Key:    file_type.NewUploader,
+	image_type.Key:   image_type.NewUploader,
+	video_type.Key:   video_type.NewUploader,
 }
 
 // GetUploaderByEventType returns the uploader type mapping by the event type.
@@ -0,0 +1,2 @@
+// Package usage contains the storage usage accounting and the quotas of the users and tenants.
+package usage
@@ -0,0 +1,144 @@
+package usage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+)
+
+// TenantUserId is the counters user of the tenant totals. The users ids are UUIDs, so it's never a user.
+const TenantUserId = "tenant"
+
+// QuotaError is returned when the upload exceeds a quota. It contains the quota left.
+type QuotaError struct {
+	// Scope is "user" or "tenant".
+	Scope string
+	// RemainingBytes and RemainingFiles are the quota left, -1 when not limited.
+	RemainingBytes int64
+	RemainingFiles int64
+}
+
+// Error is the errors interface method.
+func (e *QuotaError) Error() string {
+	var remaining []string
+	if e.RemainingBytes >= 0 {
+		remaining = append(remaining, fmt.Sprintf("%d bytes", e.RemainingBytes))
+	}
+	if e.RemainingFiles >= 0 {
+		remaining = append(remaining, fmt.Sprintf("%d files", e.RemainingFiles))
+	}
+
+	return fmt.Sprintf("the %s storage quota has %s left", e.Scope, strings.Join(remaining, " and "))
+}
+
+// Add adds the bytes and files to the user and tenant counters of the strategy. They're negative when removed.
+func Add(store metadata.MetadataStore, userId string, strategy string, bytes int64, files int64) error {
+	for _, counterId := range []string{userId, TenantUserId} {
+		err := store.AddUsage(&views.DynamoDBUsageSchema{
+			UserId:   counterId,
+			Strategy: strategy,
+			Bytes:    bytes,
+			Files:    files,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddFile counts the file stored contents. The files that weren't processed aren't counted.
+func AddFile(store metadata.MetadataStore, schema *views.DynamoDBUploadSchema) error {
+	if schema.Strategy == "" {
+		return nil
+	}
+
+	return Add(store, schema.UserId, schema.Strategy, schema.StoredSize(), 1)
+}
+
+// RemoveFile removes the file stored contents from the counters.
+func RemoveFile(store metadata.MetadataStore, schema *views.DynamoDBUploadSchema) error {
+	if schema.Strategy == "" {
+		return nil
+	}
+
+	return Add(store, schema.UserId, schema.Strategy, -schema.StoredSize(), -1)
+}
+
+// Get returns the user usage, or the tenant usage with the TenantUserId.
+func Get(store metadata.MetadataStore, userId string) (*views.UsageResponse, error) {
+	counters, err := store.ListUsage(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &views.UsageResponse{
+		Strategies: make(map[string]*views.StrategyUsage, len(counters)),
+	}
+	if userId != TenantUserId {
+		usage.UserId = userId
+	}
+
+	for _, strategyCounters := range counters {
+		usage.Bytes += strategyCounters.Bytes
+		usage.Files += strategyCounters.Files
+		usage.Strategies[strategyCounters.Strategy] = &views.StrategyUsage{
+			Bytes: strategyCounters.Bytes,
+			Files: strategyCounters.Files,
+		}
+	}
+
+	return usage, nil
+}
+
+// Check returns a QuotaError when the bytes and files exceed the user or tenant quota.
+// The usage is only read for the configured quotas. The check and the counting aren't atomic,
+// so the quotas are soft limits that the concurrent uploads can exceed.
+func Check(store metadata.MetadataStore, quota config.QuotaConfig, userId string, bytes int64, files int64) error {
+	scopes := []struct {
+		scope    string
+		userId   string
+		maxBytes int64
+		maxFiles int64
+	}{
+		{"user", userId, quota.UserMaxBytes, quota.UserMaxFiles},
+		{"tenant", TenantUserId, quota.TenantMaxBytes, quota.TenantMaxFiles},
+	}
+
+	for _, scope := range scopes {
+		if scope.maxBytes <= 0 && scope.maxFiles <= 0 {
+			continue
+		}
+
+		usage, err := Get(store, scope.userId)
+		if err != nil {
+			return err
+		}
+
+		remainingBytes := remaining(scope.maxBytes, usage.Bytes)
+		remainingFiles := remaining(scope.maxFiles, usage.Files)
+
+		if (remainingBytes >= 0 && bytes > remainingBytes) || (remainingFiles >= 0 && files > remainingFiles) {
+			return &QuotaError{
+				Scope:          scope.scope,
+				RemainingBytes: remainingBytes,
+				RemainingFiles: remainingFiles,
+			}
+		}
+	}
+
+	return nil
+}
+
+// remaining returns the quota left, or -1 when the limit is zero.
+func remaining(limit int64, used int64) int64 {
+	if limit <= 0 {
+		return -1
+	}
+
+	return max(limit-used, 0)
+}
@@ -0,0 +1,60 @@
+package usage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsage(t *testing.T) {
+	store := metadata.NewMemoryStore()
+	userId := uuid.NewString()
+
+	image := &views.DynamoDBUploadSchema{
+		UserId:   userId,
+		Size:     100,
+		Strategy: "image",
+		Versions: []views.FileVersion{{Version: 1, Size: 50}},
+	}
+	assert.Nil(t, AddFile(store, image))
+	assert.Nil(t, AddFile(store, &views.DynamoDBUploadSchema{UserId: userId, Size: 10, Strategy: "file"}))
+	assert.Nil(t, AddFile(store, &views.DynamoDBUploadSchema{UserId: userId, Size: 10}))
+	assert.Nil(t, AddFile(store, &views.DynamoDBUploadSchema{UserId: uuid.NewString(), Size: 40, Strategy: "file"}))
+
+	usage, err := Get(store, userId)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(160), usage.Bytes)
+	assert.Equal(t, int64(2), usage.Files)
+	assert.Equal(t, &views.StrategyUsage{Bytes: 150, Files: 1}, usage.Strategies["image"])
+
+	usage, err = Get(store, TenantUserId)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(200), usage.Bytes)
+	assert.Equal(t, int64(3), usage.Files)
+	assert.Empty(t, usage.UserId)
+
+	assert.Nil(t, Check(store, config.QuotaConfig{}, userId, 1000, 1))
+	assert.Nil(t, Check(store, config.QuotaConfig{UserMaxBytes: 200}, userId, 40, 1))
+
+	err = Check(store, config.QuotaConfig{UserMaxBytes: 200, UserMaxFiles: 2}, userId, 10, 1)
+	quotaErr := &QuotaError{}
+	assert.True(t, errors.As(err, &quotaErr))
+	assert.Equal(t, &QuotaError{Scope: "user", RemainingBytes: 40, RemainingFiles: 0}, quotaErr)
+	assert.Equal(t, "the user storage quota has 40 bytes and 0 files left", err.Error())
+
+	err = Check(store, config.QuotaConfig{TenantMaxBytes: 250}, userId, 60, 1)
+	assert.Equal(t, "the tenant storage quota has 50 bytes left", err.Error())
+
+	assert.Nil(t, RemoveFile(store, image))
+
+	usage, err = Get(store, userId)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), usage.Bytes)
+	assert.Equal(t, int64(1), usage.Files)
+	assert.Equal(t, &views.StrategyUsage{}, usage.Strategies["image"])
+}
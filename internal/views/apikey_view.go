@@ -0,0 +1,116 @@
+package views
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoDBApiKeySchema is the DynamoDB API key schema view.
+// Only the key hash is stored. The zero ExpiresOn and RevokedOn mean that the key never expires and isn't revoked.
+type DynamoDBApiKeySchema struct {
+	KeyId      string    `dynamodbav:"keyId"`
+	KeyHash    string    `dynamodbav:"keyHash"`
+	Name       string    `dynamodbav:"name"`
+	TenantId   string    `dynamodbav:"tenantId"`
+	Scopes     []string  `dynamodbav:"scopes"`
+	ExpiresOn  time.Time `dynamodbav:"expiresOn"`
+	CreatedOn  time.Time `dynamodbav:"createdOn"`
+	LastUsedOn time.Time `dynamodbav:"lastUsedOn"`
+	RevokedOn  time.Time `dynamodbav:"revokedOn"`
+}
+
+func (d DynamoDBApiKeySchema) GetKey() (map[string]types.AttributeValue, error) {
+	return apiKeyKey(d.KeyId)
+}
+
+func (d DynamoDBApiKeySchema) GetUpdateFields() expression.UpdateBuilder {
+	return getUpdateFields(d, "keyId")
+}
+
+// IsActive checks if the key isn't revoked or expired.
+func (d DynamoDBApiKeySchema) IsActive(now time.Time) bool {
+	return d.RevokedOn.IsZero() && (d.ExpiresOn.IsZero() || now.Before(d.ExpiresOn))
+}
+
+// ToApiKeyResponse returns the API key response view, without the key.
+func (d DynamoDBApiKeySchema) ToApiKeyResponse() *ApiKeyResponse {
+	return &ApiKeyResponse{
+		KeyId:      d.KeyId,
+		Name:       d.Name,
+		TenantId:   d.TenantId,
+		Scopes:     d.Scopes,
+		ExpiresOn:  optionalTime(d.ExpiresOn),
+		CreatedOn:  d.CreatedOn,
+		LastUsedOn: optionalTime(d.LastUsedOn),
+		RevokedOn:  optionalTime(d.RevokedOn),
+		Active:     d.IsActive(time.Now()),
+	}
+}
+
+// DynamoDBApiKeyUsageSchema is the DynamoDB API key usage update.
+// It only sets the last used time, so it doesn't overwrite a concurrent key change.
+type DynamoDBApiKeyUsageSchema struct {
+	KeyId      string    `dynamodbav:"keyId"`
+	LastUsedOn time.Time `dynamodbav:"lastUsedOn"`
+}
+
+func (d DynamoDBApiKeyUsageSchema) GetKey() (map[string]types.AttributeValue, error) {
+	return apiKeyKey(d.KeyId)
+}
+
+func (d DynamoDBApiKeyUsageSchema) GetUpdateFields() expression.UpdateBuilder {
+	return getUpdateFields(d, "keyId")
+}
+
+// apiKeyKey returns the API keys table key.
+func apiKeyKey(keyId string) (map[string]types.AttributeValue, error) {
+	value, err := attributevalue.Marshal(keyId)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]types.AttributeValue{
+		"keyId": value,
+	}, nil
+}
+
+// optionalTime returns nil for the zero time.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// CreateApiKeyRequest contains the new API key attributes.
+// The key never expires without ExpiresOn. The tenant is the caller tenant when empty.
+type CreateApiKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=64"`
+	TenantId  string     `json:"tenantId" validate:"omitempty,max=64"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=upload read delete admin"`
+	ExpiresOn *time.Time `json:"expiresOn"`
+}
+
+// RotateApiKeyRequest contains the rotation options.
+// The replaced key keeps working for the grace period, in seconds, so the clients can switch keys.
+type RotateApiKeyRequest struct {
+	GracePeriod int64 `json:"gracePeriod" validate:"min=0,max=2592000"`
+}
+
+// ApiKeyResponse contains the API key information. The key itself is only returned when created.
+type ApiKeyResponse struct {
+	KeyId      string     `json:"keyId"`
+	Key        string     `json:"key,omitempty"`
+	Name       string     `json:"name"`
+	TenantId   string     `json:"tenantId"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresOn  *time.Time `json:"expiresOn,omitempty"`
+	CreatedOn  time.Time  `json:"createdOn"`
+	LastUsedOn *time.Time `json:"lastUsedOn,omitempty"`
+	RevokedOn  *time.Time `json:"revokedOn,omitempty"`
+	Active     bool       `json:"active"`
+}
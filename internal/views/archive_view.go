@@ -0,0 +1,96 @@
+package views
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gearpoint/filepoint/pkg/utils"
+)
+
+const (
+	// ArchiveFolder is the storage folder of the archives built asynchronously.
+	// It's kept apart from the user folders, so the archives are not listed as files.
+	ArchiveFolder = "_archives"
+
+	// MaxArchiveFiles is the max number of prefixes per archive request.
+	MaxArchiveFiles = 1000
+)
+
+// ArchiveRequest contains the files to archive, selected by prefixes or by folder.
+// The async archives are built in the storage and the signed link is sent by webhook.
+type ArchiveRequest struct {
+	UserId     string                `json:"userId" validate:"required,uuid"`
+	Prefixes   []string              `json:"prefixes" validate:"required_without=FolderId,max=1000,dive,required"`
+	FolderId   string                `json:"folderId" validate:"omitempty,uuid"`
+	Definition utils.FileDefinitions `json:"definition"`
+	Async      bool                  `json:"async"`
+}
+
+// ArchiveResponse is the response of the async archive requests.
+// The location is the archive object, sent again in the archive webhook.
+type ArchiveResponse struct {
+	Location string `json:"location"`
+}
+
+// ArchiveData is the archive events data.
+// The URL is empty when the archive failed.
+type ArchiveData struct {
+	Url     string    `json:"url,omitempty"`
+	Expires time.Time `json:"expires,omitempty"`
+	Files   int       `json:"files"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// ArchiveExtractionData is the archive extraction event data, with the prefixes of the created files.
+// The error is set when the archive couldn't be extracted.
+type ArchiveExtractionData struct {
+	Created  []string               `json:"created"`
+	Rejected []RejectedArchiveEntry `json:"rejected"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// RejectedArchiveEntry is an uploaded archive entry that wasn't extracted.
+type RejectedArchiveEntry struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// ArchiveEntry is a file to write in the archive.
+// The filename is used when the object has no filename metadata.
+type ArchiveEntry struct {
+	ObjectName string
+	Filename   string
+	Encryption *FileEncryption
+}
+
+// ArchiveObjectPrefix returns an unique storage prefix for the user archive.
+func ArchiveObjectPrefix(userId string) string {
+	return utils.GetUniquePrefix(utils.CreatePrefix(ArchiveFolder, userId)) + ".zip"
+}
+
+// ArchiveNames returns unique names for the archive entries.
+// The names are kept in lowercase to detect duplicates, like case insensitive file systems.
+type ArchiveNames map[string]bool
+
+// Name returns the entry name of the file, with the object extension.
+// The object extension is used as the stored definition may be converted.
+// Duplicated names get a counter, i.e. photo (1).jpg.
+func (n ArchiveNames) Name(filename string, objectName string) string {
+	filename = path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	if filename == "." || filename == "/" {
+		filename = path.Base(objectName)
+	}
+
+	extension := path.Ext(objectName)
+	base := strings.TrimSuffix(filename, path.Ext(filename))
+
+	name := base + extension
+	for i := 1; n[strings.ToLower(name)]; i++ {
+		name = fmt.Sprintf("%s (%d)%s", base, i, extension)
+	}
+	n[strings.ToLower(name)] = true
+
+	return name
+}
@@ -0,0 +1,16 @@
+package views
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveNames(t *testing.T) {
+	names := ArchiveNames{}
+
+	assert.Equal(t, "photo.webp", names.Name("photo.jpg", "user/file/low.webp"))
+	assert.Equal(t, "Photo (1).webp", names.Name("Photo.JPG", "user/other/low.webp"))
+	assert.Equal(t, "passwd.txt", names.Name("../../etc/passwd", "user/file/medium.txt"))
+	assert.Equal(t, "medium.txt", names.Name("", "user/empty/medium.txt"))
+}
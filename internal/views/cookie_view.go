@@ -0,0 +1,11 @@
+package views
+
+import "time"
+
+// SignedCookiesResponse is the response used in GetSignedCookies calls.
+// The cookies are also set in the response headers.
+type SignedCookiesResponse struct {
+	Prefix  string            `json:"prefix"`
+	Cookies map[string]string `json:"cookies"`
+	Expires time.Time         `json:"expires"`
+}
@@ -1,16 +1,31 @@
 package views
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gearpoint/filepoint/pkg/labeler"
 	"github.com/gearpoint/filepoint/pkg/utils"
 )
 
-type FileLabelling []map[string][]string
+// FileStatus defines the file availability state.
+type FileStatus string
+
+const (
+	// StatusActive is the default state of a processed file.
+	StatusActive FileStatus = "active"
+	// StatusQuarantined is the state of a file flagged by the moderation policy.
+	// Quarantined files can't be signed until approved.
+	StatusQuarantined FileStatus = "quarantined"
+	// StatusTrashed is the state of a file deleted by the user.
+	// Trashed files are hidden until restored, and purged after the retention period.
+	StatusTrashed FileStatus = "trashed"
+)
 
 type DynamoDBSchema interface {
 	GetKey() (map[string]types.AttributeValue, error)
@@ -23,11 +38,32 @@ type DynamoDBUploadSchema struct {
 	Prefix         string                       `dynamodbav:"prefix"`
 	Author         string                       `dynamodbav:"author"`
 	Title          string                       `dynamodbav:"title"`
+	Filename       string                       `dynamodbav:"filename"`
+	FolderId       string                       `dynamodbav:"folderId"`
 	RequestId      string                       `dynamodbav:"requestId"`
 	CorrelationId  string                       `dynamodbav:"correlationId"`
+	Metadata       map[string]string            `dynamodbav:"metadata"`
 	DefinitionsMap utils.FileDefinitionsMapping `dynamodbav:"definitionsMap"`
-	FileLabels     FileLabelling                `dynamodbav:"fileLabels"`
+	FileLabels     *labeler.FileLabels          `dynamodbav:"fileLabels"`
+	ContentType    string                       `dynamodbav:"contentType"`
+	TextObject     string                       `dynamodbav:"textObject"`
+	TextTerms      []string                     `dynamodbav:"textTerms"`
+	Status         FileStatus                   `dynamodbav:"status"`
+	FlaggedLabels  []labeler.ModerationLabel    `dynamodbav:"flaggedLabels"`
 	OccurredOn     time.Time                    `dynamodbav:"occurredOn"`
+	Version        int64                        `dynamodbav:"version"`
+	TrashedOn      time.Time                    `dynamodbav:"trashedOn"`
+	PreviousStatus FileStatus                   `dynamodbav:"previousStatus"`
+	// ContentVersion is the current content number, the previous contents are kept in the versions.
+	ContentVersion     int64         `dynamodbav:"contentVersion"`
+	LastContentVersion int64         `dynamodbav:"lastContentVersion"`
+	Versions           []FileVersion `dynamodbav:"versions"`
+	// Encryption is set when the current content objects are encrypted.
+	Encryption *FileEncryption `dynamodbav:"encryption"`
+	// Size is the current content original size. Strategy is the upload strategy of the first content.
+	// They're set when the content is processed, and counted in the storage usage.
+	Size     int64  `dynamodbav:"size"`
+	Strategy string `dynamodbav:"strategy"`
 }
 
 func (d DynamoDBUploadSchema) GetKey() (map[string]types.AttributeValue, error) {
@@ -48,12 +84,236 @@ func (d DynamoDBUploadSchema) GetKey() (map[string]types.AttributeValue, error)
 }
 
 func (d DynamoDBUploadSchema) GetUpdateFields() expression.UpdateBuilder {
-	uploadType := reflect.TypeOf(d)
-	uploadValue := reflect.ValueOf(d)
+	return getUpdateFields(d, "userId", "prefix")
+}
+
+// ToFileResponse returns the file response view.
+func (d DynamoDBUploadSchema) ToFileResponse() *FileResponse {
+	return &FileResponse{
+		UserId:         d.UserId,
+		Prefix:         d.Prefix,
+		Author:         d.Author,
+		Title:          d.Title,
+		Filename:       d.Filename,
+		FolderId:       d.FolderId,
+		CorrelationId:  d.CorrelationId,
+		Metadata:       d.Metadata,
+		DefinitionsMap: d.DefinitionsMap,
+		FileLabels:     d.FileLabels,
+		ContentType:    d.ContentType,
+		Status:         d.Status,
+		FlaggedLabels:  d.FlaggedLabels,
+		OccurredOn:     d.OccurredOn,
+		Version:        d.Version,
+		ContentVersion: d.CurrentContentVersion(),
+		Encrypted:      d.Encryption != nil,
+		TrashedOn:      d.trashedOn(),
+	}
+}
+
+// Trash moves the file to the trash, keeping the status to restore.
+func (d *DynamoDBUploadSchema) Trash(trashedOn time.Time) {
+	if d.Status == StatusTrashed {
+		return
+	}
+
+	d.PreviousStatus = d.Status
+	d.Status = StatusTrashed
+	d.TrashedOn = trashedOn
+}
+
+// Restore restores the file from the trash.
+func (d *DynamoDBUploadSchema) Restore() {
+	if d.Status != StatusTrashed {
+		return
+	}
+
+	d.Status = d.PreviousStatus
+	if d.Status == "" {
+		d.Status = StatusActive
+	}
+	d.PreviousStatus = ""
+	d.TrashedOn = time.Time{}
+}
+
+// Transfer returns a copy of the file in the given user prefix, with the objects names moved to the new prefix.
+// The copy starts in the first version, without the previous contents, and in the user root if it's sent to another user.
+func (d DynamoDBUploadSchema) Transfer(userId string, prefix string) *DynamoDBUploadSchema {
+	file := d
+	file.UserId = userId
+	file.Prefix = prefix
+	file.Version = 0
+	file.Versions = nil
+	if userId != d.UserId {
+		file.FolderId = ""
+	}
+
+	file.DefinitionsMap = make(utils.FileDefinitionsMapping, len(d.DefinitionsMap))
+	for definition, objectName := range d.DefinitionsMap {
+		file.DefinitionsMap[definition] = TransferObjectName(objectName, d.Prefix, prefix)
+	}
+
+	if d.TextObject != "" {
+		file.TextObject = TextObjectPrefix(prefix)
+	}
+
+	if d.Metadata != nil {
+		file.Metadata = make(map[string]string, len(d.Metadata))
+		for key, value := range d.Metadata {
+			file.Metadata[key] = value
+		}
+	}
+
+	return &file
+}
+
+// Move returns the file moved to the given user prefix, keeping its previous contents in the moved version sub-keys.
+func (d DynamoDBUploadSchema) Move(userId string, prefix string) *DynamoDBUploadSchema {
+	file := d.Transfer(userId, prefix)
+	if d.TextObject != "" {
+		file.TextObject = TextObjectPrefix(VersionPrefix(prefix, d.CurrentContentVersion()))
+	}
+
+	file.Versions = make([]FileVersion, len(d.Versions))
+	for i, fileVersion := range d.Versions {
+		definitionsMap := make(utils.FileDefinitionsMapping, len(fileVersion.DefinitionsMap))
+		for definition, objectName := range fileVersion.DefinitionsMap {
+			definitionsMap[definition] = TransferObjectName(objectName, d.Prefix, prefix)
+		}
+		fileVersion.DefinitionsMap = definitionsMap
+
+		if fileVersion.TextObject != "" {
+			fileVersion.TextObject = TextObjectPrefix(VersionPrefix(prefix, fileVersion.Version))
+		}
+
+		file.Versions[i] = fileVersion
+	}
+
+	return file
+}
+
+// TransferObjectName returns the object name moved from the source prefix to the destination prefix.
+func TransferObjectName(objectName string, srcPrefix string, dstPrefix string) string {
+	return dstPrefix + strings.TrimPrefix(objectName, srcPrefix)
+}
+
+// trashedOn returns the trash date, or nil if the file isn't trashed.
+func (d DynamoDBUploadSchema) trashedOn() *time.Time {
+	if d.Status != StatusTrashed {
+		return nil
+	}
+
+	return &d.TrashedOn
+}
+
+// ToListFilesItem returns the files listing item view, without the signed URL.
+func (d DynamoDBUploadSchema) ToListFilesItem() *ListFilesItem {
+	return &ListFilesItem{
+		Prefix:         d.Prefix,
+		Title:          d.Title,
+		Author:         d.Author,
+		Filename:       d.Filename,
+		FolderId:       d.FolderId,
+		ContentType:    d.ContentType,
+		Status:         d.Status,
+		Metadata:       d.Metadata,
+		DefinitionsMap: d.DefinitionsMap,
+		OccurredOn:     d.OccurredOn,
+		Encrypted:      d.Encryption != nil,
+	}
+}
+
+// DynamoDBLabelIndexSchema is the DynamoDB inverted index schema view.
+// Each item maps a file label (or text term) to the file prefix, sorted by label in the user partition.
+type DynamoDBLabelIndexSchema struct {
+	UserId   string `dynamodbav:"userId"`
+	LabelKey string `dynamodbav:"labelKey"`
+	Label    string `dynamodbav:"label"`
+	Prefix   string `dynamodbav:"prefix"`
+}
+
+// NewLabelIndexSchemas returns the label index items of the file.
+func NewLabelIndexSchemas(userId string, prefix string, fileLabels *labeler.FileLabels) []*DynamoDBLabelIndexSchema {
+	if fileLabels == nil {
+		return nil
+	}
+
+	var schemas []*DynamoDBLabelIndexSchema
+	for _, label := range fileLabels.Labels {
+		schemas = append(schemas, &DynamoDBLabelIndexSchema{
+			UserId:   userId,
+			LabelKey: LabelIndexKey(label.Name, prefix),
+			Label:    label.Name,
+			Prefix:   prefix,
+		})
+	}
+
+	return schemas
+}
+
+// NewTextIndexSchemas returns the text terms index items of the file.
+func NewTextIndexSchemas(userId string, prefix string, terms []string) []*DynamoDBLabelIndexSchema {
+	var schemas []*DynamoDBLabelIndexSchema
+	for _, term := range terms {
+		schemas = append(schemas, &DynamoDBLabelIndexSchema{
+			UserId:   userId,
+			LabelKey: TextIndexKey(term, prefix),
+			Label:    term,
+			Prefix:   prefix,
+		})
+	}
+
+	return schemas
+}
+
+// LabelIndexKey returns the label index sort key.
+// The label is lowercased, so the index lookup is case insensitive.
+func LabelIndexKey(label string, prefix string) string {
+	return fmt.Sprintf("%s#%s", strings.ToLower(label), prefix)
+}
+
+// TextIndexKey returns the text term index sort key.
+// The terms are namespaced, so they don't collide with labels.
+func TextIndexKey(term string, prefix string) string {
+	return fmt.Sprintf("text:%s#%s", strings.ToLower(term), prefix)
+}
+
+// IndexSchemas returns all the index items of the file.
+func (d DynamoDBUploadSchema) IndexSchemas() []*DynamoDBLabelIndexSchema {
+	return append(
+		NewLabelIndexSchemas(d.UserId, d.Prefix, d.FileLabels),
+		NewTextIndexSchemas(d.UserId, d.Prefix, d.TextTerms)...,
+	)
+}
+
+func (d DynamoDBLabelIndexSchema) GetKey() (map[string]types.AttributeValue, error) {
+	userId, err := attributevalue.Marshal(d.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	labelKey, err := attributevalue.Marshal(d.LabelKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]types.AttributeValue{
+		"userId":   userId,
+		"labelKey": labelKey,
+	}, nil
+}
+
+func (d DynamoDBLabelIndexSchema) GetUpdateFields() expression.UpdateBuilder {
+	return getUpdateFields(d, "userId", "labelKey")
+}
+
+// getUpdateFields returns the update expression of all the schema fields, except the key fields.
+func getUpdateFields(schema interface{}, keyFields ...string) expression.UpdateBuilder {
+	uploadType := reflect.TypeOf(schema)
+	uploadValue := reflect.ValueOf(schema)
 
 	isKeyField := func(a string) bool {
-		list := []string{"userId", "prefix"}
-		for _, b := range list {
+		for _, b := range keyFields {
 			if b == a {
 				return true
 			}
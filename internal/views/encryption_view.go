@@ -0,0 +1,9 @@
+package views
+
+// FileEncryption contains the file data key, wrapped with the master key.
+// The encrypted files objects can't be signed, they are decrypted by the download endpoint.
+type FileEncryption struct {
+	Algorithm  string `dynamodbav:"algorithm"`
+	KeyId      string `dynamodbav:"keyId"`
+	WrappedKey []byte `dynamodbav:"wrappedKey"`
+}
@@ -0,0 +1,135 @@
+package views
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoDBFolderSchema is the DynamoDB folder schema view.
+// Folders only group the files records, the files objects aren't moved.
+// The root folders have no parent.
+type DynamoDBFolderSchema struct {
+	UserId    string    `dynamodbav:"userId"`
+	FolderId  string    `dynamodbav:"folderId"`
+	ParentId  string    `dynamodbav:"parentId"`
+	Name      string    `dynamodbav:"name"`
+	CreatedOn time.Time `dynamodbav:"createdOn"`
+	UpdatedOn time.Time `dynamodbav:"updatedOn"`
+}
+
+func (d DynamoDBFolderSchema) GetKey() (map[string]types.AttributeValue, error) {
+	userId, err := attributevalue.Marshal(d.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	folderId, err := attributevalue.Marshal(d.FolderId)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]types.AttributeValue{
+		"userId":   userId,
+		"folderId": folderId,
+	}, nil
+}
+
+func (d DynamoDBFolderSchema) GetUpdateFields() expression.UpdateBuilder {
+	return getUpdateFields(d, "userId", "folderId")
+}
+
+// ToFolderResponse returns the folder response view.
+func (d DynamoDBFolderSchema) ToFolderResponse() *FolderResponse {
+	return &FolderResponse{
+		FolderId:  d.FolderId,
+		ParentId:  d.ParentId,
+		Name:      d.Name,
+		CreatedOn: d.CreatedOn,
+		UpdatedOn: d.UpdatedOn,
+	}
+}
+
+// CreateFolderRequest contains the new folder attributes.
+// The parent is optional, the folder is created in the root without it.
+type CreateFolderRequest struct {
+	UserId   string `json:"userId" validate:"required,uuid"`
+	Name     string `json:"name" validate:"required,max=255,excludesall=/\\"`
+	ParentId string `json:"parentId" validate:"omitempty,uuid"`
+}
+
+// UpdateFolderRequest contains the folder changes. The omitted fields are kept.
+// An empty parent moves the folder to the root.
+type UpdateFolderRequest struct {
+	Name     *string `json:"name" validate:"omitempty,max=255,excludesall=/\\"`
+	ParentId *string `json:"parentId" validate:"omitempty,uuid|eq="`
+}
+
+// FolderResponse contains the folder information.
+type FolderResponse struct {
+	FolderId  string    `json:"folderId"`
+	ParentId  string    `json:"parentId"`
+	Name      string    `json:"name"`
+	CreatedOn time.Time `json:"createdOn"`
+	UpdatedOn time.Time `json:"updatedOn"`
+}
+
+// FolderContentsResponse contains the folder children.
+// The folder is nil in the user root.
+type FolderContentsResponse struct {
+	Folder  *FolderResponse   `json:"folder,omitempty"`
+	Folders []*FolderResponse `json:"folders"`
+	Files   []*FileResponse   `json:"files"`
+}
+
+// FolderChildren returns the folders whose parent is the given folder, or the root folders without it.
+func FolderChildren(folders []*DynamoDBFolderSchema, parentId string) []*DynamoDBFolderSchema {
+	children := []*DynamoDBFolderSchema{}
+	for _, folder := range folders {
+		if folder.ParentId == parentId {
+			children = append(children, folder)
+		}
+	}
+
+	return children
+}
+
+// FolderDescendants returns the folder and all the folders nested in it.
+func FolderDescendants(folders []*DynamoDBFolderSchema, folderId string) []*DynamoDBFolderSchema {
+	var descendants []*DynamoDBFolderSchema
+	for _, folder := range folders {
+		if folder.FolderId == folderId {
+			descendants = append(descendants, folder)
+		}
+	}
+
+	for i := 0; i < len(descendants); i++ {
+		descendants = append(descendants, FolderChildren(folders, descendants[i].FolderId)...)
+	}
+
+	return descendants
+}
+
+// IsFolderDescendant checks if the folder is the ancestor folder or is nested in it.
+func IsFolderDescendant(folders []*DynamoDBFolderSchema, folderId string, ancestorId string) bool {
+	for _, folder := range FolderDescendants(folders, ancestorId) {
+		if folder.FolderId == folderId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FolderNameExists checks if the parent folder has a child with the name, other than the excluded folder.
+func FolderNameExists(folders []*DynamoDBFolderSchema, parentId string, name string, excludedId string) bool {
+	for _, folder := range FolderChildren(folders, parentId) {
+		if folder.Name == name && folder.FolderId != excludedId {
+			return true
+		}
+	}
+
+	return false
+}
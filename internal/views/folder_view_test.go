@@ -0,0 +1,47 @@
+package views
+
+import (
+	"testing"
+
+	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFolderTree(t *testing.T) {
+	folders := []*DynamoDBFolderSchema{
+		{FolderId: "photos", Name: "Photos"},
+		{FolderId: "trips", ParentId: "photos", Name: "Trips"},
+		{FolderId: "rome", ParentId: "trips", Name: "Rome"},
+		{FolderId: "docs", Name: "Docs"},
+	}
+
+	children := FolderChildren(folders, "")
+	assert.Len(t, children, 2)
+	assert.Equal(t, "photos", children[0].FolderId)
+	assert.Equal(t, "docs", children[1].FolderId)
+
+	descendants := FolderDescendants(folders, "photos")
+	assert.Len(t, descendants, 3)
+	assert.Empty(t, FolderDescendants(folders, "missing"))
+
+	assert.True(t, IsFolderDescendant(folders, "rome", "photos"))
+	assert.True(t, IsFolderDescendant(folders, "photos", "photos"))
+	assert.False(t, IsFolderDescendant(folders, "docs", "photos"))
+
+	assert.True(t, FolderNameExists(folders, "photos", "Trips", ""))
+	assert.False(t, FolderNameExists(folders, "photos", "Trips", "trips"))
+	assert.False(t, FolderNameExists(folders, "", "Trips", ""))
+}
+
+func TestUpdateFolderRequest(t *testing.T) {
+	root := ""
+	parent := "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	invalid := "parent"
+	name := "a/b"
+
+	assert.Nil(t, utils.Validate.Struct(&UpdateFolderRequest{}))
+	assert.Nil(t, utils.Validate.Struct(&UpdateFolderRequest{ParentId: &root}))
+	assert.Nil(t, utils.Validate.Struct(&UpdateFolderRequest{ParentId: &parent}))
+	assert.Error(t, utils.Validate.Struct(&UpdateFolderRequest{ParentId: &invalid}))
+	assert.Error(t, utils.Validate.Struct(&UpdateFolderRequest{Name: &name}))
+}
@@ -7,4 +7,6 @@ const (
 	S3Prefix = "s3-prefix"
 	// The temporary object prefix
 	TempObjectPrefix = "s3-temp-prefix"
+	// The header used to get the tenant that uploaded the file. It's empty for the default tenant.
+	TenantId = "tenant-id"
 )
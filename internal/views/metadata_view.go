@@ -0,0 +1,74 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// MetadataTagPrefix is the prefix of the object tags mirrored from the custom metadata.
+	MetadataTagPrefix = "meta:"
+
+	// MaxMetadataTags is the max number of mirrored object tags.
+	// S3 allows 10 tags per object and two of them are used internally.
+	MaxMetadataTags = 8
+)
+
+// ErrInvalidTagValue is returned when a mirrored metadata value isn't a valid tag value.
+var ErrInvalidTagValue = errors.New("invalid tag value")
+
+// tagValueRegex defines the object tag values charset.
+var tagValueRegex = regexp.MustCompile(`^[\p{L}\p{Z}\p{N}_.:/=+\-@]*$`)
+
+// UpdateMetadataRequest is the request used in custom metadata updates.
+// The metadata is merged into the file metadata and null values remove the keys.
+type UpdateMetadataRequest struct {
+	Metadata map[string]*string `json:"metadata" validate:"required"`
+}
+
+// Merge returns the file metadata with the request changes.
+func (r *UpdateMetadataRequest) Merge(metadata map[string]string) map[string]string {
+	merged := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		merged[key] = value
+	}
+
+	for key, value := range r.Metadata {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = *value
+	}
+
+	return merged
+}
+
+// MetadataTags returns the object tags mirrored from the given metadata keys.
+func MetadataTags(metadata map[string]string, tagKeys []string) (map[string]string, error) {
+	tags := map[string]string{}
+	for _, key := range tagKeys {
+		value, ok := metadata[key]
+		if !ok {
+			continue
+		}
+
+		if !tagValueRegex.MatchString(value) {
+			return nil, fmt.Errorf("%w: metadata key '%s'", ErrInvalidTagValue, key)
+		}
+
+		if len(tags) == MaxMetadataTags {
+			break
+		}
+		tags[MetadataTagPrefix+key] = value
+	}
+
+	return tags, nil
+}
+
+// IsMetadataTag checks if the object tag is mirrored from the custom metadata.
+func IsMetadataTag(tagKey string) bool {
+	return strings.HasPrefix(tagKey, MetadataTagPrefix)
+}
@@ -0,0 +1,33 @@
+package views
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateMetadataRequestMerge(t *testing.T) {
+	value := "new"
+	request := &UpdateMetadataRequest{
+		Metadata: map[string]*string{"changed": &value, "removed": nil},
+	}
+
+	metadata := map[string]string{"kept": "old", "changed": "old", "removed": "old"}
+	merged := request.Merge(metadata)
+
+	assert.Equal(t, map[string]string{"kept": "old", "changed": "new"}, merged)
+	assert.Equal(t, "old", metadata["changed"])
+}
+
+func TestMetadataTags(t *testing.T) {
+	metadata := map[string]string{"project": "filepoint", "owner": "team@gearpoint", "notes": "untagged"}
+
+	tags, err := MetadataTags(metadata, []string{"project", "owner", "missing"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"meta:project": "filepoint", "meta:owner": "team@gearpoint"}, tags)
+	assert.True(t, IsMetadataTag("meta:project"))
+	assert.False(t, IsMetadataTag("quarantined"))
+
+	_, err = MetadataTags(map[string]string{"project": "a&b"}, []string{"project"})
+	assert.ErrorIs(t, err, ErrInvalidTagValue)
+}
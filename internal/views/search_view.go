@@ -0,0 +1,108 @@
+package views
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gearpoint/filepoint/pkg/utils"
+)
+
+// SearchRequest contains the search query parameters.
+type SearchRequest struct {
+	UserId      string    `form:"userId" validate:"required,uuid"`
+	Labels      []string  `form:"label" validate:"max=10"`
+	Text        string    `form:"text" validate:"max=200"`
+	Title       string    `form:"title"`
+	Author      string    `form:"author"`
+	ContentType string    `form:"contentType"`
+	From        time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To          time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit       int32     `form:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor      string    `form:"cursor"`
+}
+
+// SearchResponse is the response used in search calls.
+// The cursor is empty when there are no more results.
+type SearchResponse struct {
+	Items  []*FileResponse `json:"items"`
+	Cursor string          `json:"cursor"`
+}
+
+// Matches checks if the file matches every search filter.
+// Labels must all be present, title and author are case insensitive substrings.
+func (s *SearchRequest) Matches(schema *DynamoDBUploadSchema) bool {
+	if schema.UserId != s.UserId {
+		return false
+	}
+
+	if !containsFold(schema.Title, s.Title) || !containsFold(schema.Author, s.Author) {
+		return false
+	}
+
+	if s.ContentType != "" && schema.ContentType != s.ContentType {
+		return false
+	}
+
+	if !s.From.IsZero() && schema.OccurredOn.Before(s.From) {
+		return false
+	}
+
+	if !s.To.IsZero() && schema.OccurredOn.After(s.To) {
+		return false
+	}
+
+	return s.hasLabels(schema) && s.hasTextTerms(schema)
+}
+
+// TextTerms returns the searched text terms.
+func (s *SearchRequest) TextTerms() []string {
+	return utils.TextTerms(s.Text, 0)
+}
+
+// hasTextTerms checks if the file text has all the searched terms.
+func (s *SearchRequest) hasTextTerms(schema *DynamoDBUploadSchema) bool {
+	fileTerms := map[string]bool{}
+	for _, term := range schema.TextTerms {
+		fileTerms[term] = true
+	}
+
+	for _, term := range s.TextTerms() {
+		if !fileTerms[term] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasLabels checks if the file has all the searched labels.
+func (s *SearchRequest) hasLabels(schema *DynamoDBUploadSchema) bool {
+	if len(s.Labels) == 0 {
+		return true
+	}
+
+	if schema.FileLabels == nil {
+		return false
+	}
+
+	for _, searched := range s.Labels {
+		found := false
+		for _, label := range schema.FileLabels.Labels {
+			if strings.EqualFold(label.Name, searched) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// containsFold checks if substr is within s, ignoring case.
+func containsFold(s string, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
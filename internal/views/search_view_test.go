@@ -0,0 +1,45 @@
+package views
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gearpoint/filepoint/pkg/labeler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchRequestMatches(t *testing.T) {
+	occurredOn := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	schema := &DynamoDBUploadSchema{
+		UserId:      "user",
+		Prefix:      "user/prefix",
+		Title:       "Summer Holidays",
+		Author:      "Luan",
+		ContentType: "image/png",
+		FileLabels: &labeler.FileLabels{
+			Labels: []labeler.Label{{Name: "Beach"}, {Name: "Sea"}},
+		},
+		TextTerms:  []string{"invoice", "number", "42"},
+		OccurredOn: occurredOn,
+	}
+
+	assert.True(t, (&SearchRequest{UserId: "user"}).Matches(schema))
+	assert.True(t, (&SearchRequest{
+		UserId:      "user",
+		Labels:      []string{"beach", "SEA"},
+		Text:        "Invoice number",
+		Title:       "holiday",
+		Author:      "lu",
+		ContentType: "image/png",
+		From:        occurredOn.Add(-time.Hour),
+		To:          occurredOn.Add(time.Hour),
+	}).Matches(schema))
+
+	assert.False(t, (&SearchRequest{UserId: "other"}).Matches(schema))
+	assert.False(t, (&SearchRequest{UserId: "user", Labels: []string{"beach", "dog"}}).Matches(schema))
+	assert.False(t, (&SearchRequest{UserId: "user", Title: "winter"}).Matches(schema))
+	assert.False(t, (&SearchRequest{UserId: "user", Text: "invoice 43"}).Matches(schema))
+	assert.False(t, (&SearchRequest{UserId: "user", ContentType: "video/mp4"}).Matches(schema))
+	assert.False(t, (&SearchRequest{UserId: "user", From: occurredOn.Add(time.Hour)}).Matches(schema))
+	assert.False(t, (&SearchRequest{UserId: "user", To: occurredOn.Add(-time.Hour)}).Matches(schema))
+}
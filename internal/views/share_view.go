@@ -0,0 +1,126 @@
+package views
+
+import (
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gearpoint/filepoint/pkg/utils"
+)
+
+// DynamoDBShareSchema is the DynamoDB share link schema view.
+// Only the token hash is stored. The share has a file prefix or a folder.
+// The zero MaxDownloads and ExpiresOn don't limit the downloads and the lifetime.
+type DynamoDBShareSchema struct {
+	TokenHash    string                  `dynamodbav:"tokenHash"`
+	TenantId     string                  `dynamodbav:"tenantId"`
+	UserId       string                  `dynamodbav:"userId"`
+	Prefix       string                  `dynamodbav:"prefix"`
+	FolderId     string                  `dynamodbav:"folderId"`
+	Definitions  []utils.FileDefinitions `dynamodbav:"definitions"`
+	PasswordHash string                  `dynamodbav:"passwordHash"`
+	MaxDownloads int64                   `dynamodbav:"maxDownloads"`
+	Downloads    int64                   `dynamodbav:"downloads"`
+	ExpiresOn    time.Time               `dynamodbav:"expiresOn"`
+	CreatedOn    time.Time               `dynamodbav:"createdOn"`
+}
+
+func (d DynamoDBShareSchema) GetKey() (map[string]types.AttributeValue, error) {
+	return shareKey(d.TokenHash)
+}
+
+func (d DynamoDBShareSchema) GetUpdateFields() expression.UpdateBuilder {
+	return getUpdateFields(d, "tokenHash")
+}
+
+// IsExpired checks if the share lifetime ended.
+func (d DynamoDBShareSchema) IsExpired(now time.Time) bool {
+	return !d.ExpiresOn.IsZero() && !now.Before(d.ExpiresOn)
+}
+
+// DefinitionsMap returns the file definitions allowed by the share. All the definitions are allowed when empty.
+func (d DynamoDBShareSchema) DefinitionsMap(definitionsMap utils.FileDefinitionsMapping) utils.FileDefinitionsMapping {
+	if len(d.Definitions) == 0 {
+		return definitionsMap
+	}
+
+	allowed := utils.FileDefinitionsMapping{}
+	for definition, objectName := range definitionsMap {
+		if slices.Contains(d.Definitions, definition) {
+			allowed[definition] = objectName
+		}
+	}
+
+	return allowed
+}
+
+// DynamoDBShareDownloadSchema is the DynamoDB share downloads counter update.
+// It only increments the downloads, so the concurrent downloads are all counted.
+type DynamoDBShareDownloadSchema struct {
+	TokenHash string `dynamodbav:"tokenHash"`
+}
+
+func (d DynamoDBShareDownloadSchema) GetKey() (map[string]types.AttributeValue, error) {
+	return shareKey(d.TokenHash)
+}
+
+func (d DynamoDBShareDownloadSchema) GetUpdateFields() expression.UpdateBuilder {
+	return expression.Add(expression.Name("downloads"), expression.Value(1))
+}
+
+// shareKey returns the shares table key.
+func shareKey(tokenHash string) (map[string]types.AttributeValue, error) {
+	value, err := attributevalue.Marshal(tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]types.AttributeValue{
+		"tokenHash": value,
+	}, nil
+}
+
+// CreateShareRequest contains the share link attributes, for a file prefix or a folder.
+// The omitted options don't limit the share.
+type CreateShareRequest struct {
+	UserId       string                  `json:"userId" validate:"required,uuid"`
+	Prefix       string                  `json:"prefix" validate:"required_without=FolderId,excluded_with=FolderId"`
+	FolderId     string                  `json:"folderId" validate:"omitempty,uuid"`
+	Definitions  []utils.FileDefinitions `json:"definitions" validate:"omitempty,dive,min=0,max=2"`
+	Password     string                  `json:"password" validate:"omitempty,min=6,max=72"`
+	MaxDownloads int64                   `json:"maxDownloads" validate:"min=0"`
+	ExpiresOn    *time.Time              `json:"expiresOn"`
+}
+
+// ShareResponse contains the share link. The token is only returned when created.
+type ShareResponse struct {
+	Token        string     `json:"token"`
+	Url          string     `json:"url"`
+	Prefix       string     `json:"prefix,omitempty"`
+	FolderId     string     `json:"folderId,omitempty"`
+	MaxDownloads int64      `json:"maxDownloads,omitempty"`
+	ExpiresOn    *time.Time `json:"expiresOn,omitempty"`
+	Protected    bool       `json:"protected"`
+}
+
+// ToShareResponse returns the share response view.
+func (d DynamoDBShareSchema) ToShareResponse(token string, url string) *ShareResponse {
+	return &ShareResponse{
+		Token:        token,
+		Url:          url,
+		Prefix:       d.Prefix,
+		FolderId:     d.FolderId,
+		MaxDownloads: d.MaxDownloads,
+		ExpiresOn:    optionalTime(d.ExpiresOn),
+		Protected:    d.PasswordHash != "",
+	}
+}
+
+// SharedFileResponse is a file of a shared folder.
+type SharedFileResponse struct {
+	Prefix      string `json:"prefix"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+}
@@ -0,0 +1,66 @@
+package views
+
+import (
+	"strings"
+
+	"github.com/gearpoint/filepoint/pkg/labeler"
+	"github.com/gearpoint/filepoint/pkg/utils"
+)
+
+const (
+	// TextFolder is the storage folder of the extracted text documents.
+	// It's kept apart from the user folders, so the documents are not listed as files.
+	TextFolder = "_text"
+
+	// MaxTextTerms is the max number of searchable terms per file.
+	MaxTextTerms = 1000
+)
+
+// TextDocument contains the text extracted from a file (OCR or text layer), by page.
+type TextDocument struct {
+	Pages []TextPage `json:"pages"`
+}
+
+// TextPage contains the text lines of a page. Pages are numbered from 1.
+type TextPage struct {
+	Number int                     `json:"number"`
+	Lines  []labeler.TextDetection `json:"lines"`
+}
+
+// TextObjectPrefix returns the storage prefix of the file text document.
+func TextObjectPrefix(prefix string) string {
+	return utils.CreatePrefix(TextFolder, prefix) + ".json"
+}
+
+// IsEmpty checks if the document has no text.
+func (d *TextDocument) IsEmpty() bool {
+	if d == nil {
+		return true
+	}
+
+	for _, page := range d.Pages {
+		if len(page.Lines) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Text returns the document plain text, one line per row.
+func (d *TextDocument) Text() string {
+	var builder strings.Builder
+	for _, page := range d.Pages {
+		for _, line := range page.Lines {
+			builder.WriteString(line.Text)
+			builder.WriteString("\n")
+		}
+	}
+
+	return builder.String()
+}
+
+// Terms returns the document searchable terms.
+func (d *TextDocument) Terms() []string {
+	return utils.TextTerms(d.Text(), MaxTextTerms)
+}
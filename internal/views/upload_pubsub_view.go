@@ -4,14 +4,18 @@ import "time"
 
 // UploadPubSub contains the view used in pub/sub.
 type UploadPubSub struct {
-	Id            string    `validate:"required,uuid" json:"id"`
-	UserId        string    `validate:"required,uuid" json:"userId"`
-	Author        string    `validate:"omitempty,min=4,max=30" json:"author"`
-	Title         string    `validate:"omitempty,min=4,max=100" json:"title"`
-	CorrelationId string    `validate:"omitempty" json:"correlationId"`
-	Filename      string    `validate:"required" json:"filename"`
-	ContentType   string    `validate:"required" json:"contentType"`
-	Size          int64     `validate:"required,max-file-size" json:"size"`
-	IpAddress     string    `validate:"required" json:"ip"`
-	OccurredOn    time.Time `validate:"required" json:"occurredOn"`
+	Id            string            `validate:"required,uuid" json:"id"`
+	UserId        string            `validate:"required,uuid" json:"userId"`
+	Author        string            `validate:"omitempty,min=4,max=30" json:"author"`
+	Title         string            `validate:"omitempty,min=4,max=100" json:"title"`
+	CorrelationId string            `validate:"omitempty" json:"correlationId"`
+	Metadata      map[string]string `validate:"custom-metadata" json:"metadata"`
+	Tags          map[string]string `json:"tags"`
+	Filename      string            `validate:"required" json:"filename"`
+	ContentType   string            `validate:"required" json:"contentType"`
+	Size          int64             `validate:"required,max-file-size" json:"size"`
+	IpAddress     string            `validate:"required" json:"ip"`
+	OccurredOn    time.Time         `validate:"required" json:"occurredOn"`
+	// ContentVersion is set when the content of an existing file is replaced.
+	ContentVersion int64 `json:"contentVersion,omitempty"`
 }
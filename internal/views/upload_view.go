@@ -3,6 +3,7 @@ package views
 import (
 	"time"
 
+	"github.com/gearpoint/filepoint/pkg/labeler"
 	"github.com/gearpoint/filepoint/pkg/utils"
 )
 
@@ -12,15 +13,68 @@ type UploadRequest struct {
 	Title         string `form:"title"`
 	Author        string `form:"author"`
 	CorrelationId string `form:"correlationId"`
+	FolderId      string `form:"folderId"`
+	// Metadata is the file custom metadata, sent as a JSON object.
+	Metadata map[string]string `form:"metadata"`
+}
+
+// UpdateFileRequest contains the file attributes changes. The omitted fields are kept.
+// An empty folder moves the file to the user root.
+// The version is optional. When sent, the file is only updated if it's still in that version.
+type UpdateFileRequest struct {
+	Title         *string `json:"title"`
+	Author        *string `json:"author"`
+	CorrelationId *string `json:"correlationId"`
+	FolderId      *string `json:"folderId"`
+	Version       *int64  `json:"version"`
+}
+
+// Apply applies the changes to the file schema.
+func (r *UpdateFileRequest) Apply(schema *DynamoDBUploadSchema) {
+	if r.Title != nil {
+		schema.Title = *r.Title
+	}
+	if r.Author != nil {
+		schema.Author = *r.Author
+	}
+	if r.CorrelationId != nil {
+		schema.CorrelationId = *r.CorrelationId
+	}
+	if r.FolderId != nil {
+		schema.FolderId = *r.FolderId
+	}
+}
+
+// Validate validates the file attributes with the upload rules.
+func (r *UpdateFileRequest) Validate(schema *DynamoDBUploadSchema) error {
+	return utils.Validate.StructPartial(&UploadPubSub{
+		Title:         schema.Title,
+		Author:        schema.Author,
+		CorrelationId: schema.CorrelationId,
+	}, "Title", "Author", "CorrelationId")
+}
+
+// TransferFileRequest contains the destination user of the file copy or move.
+type TransferFileRequest struct {
+	UserId string `json:"userId" validate:"required,uuid"`
+}
+
+// RenameFileRequest contains the new file display name.
+type RenameFileRequest struct {
+	Filename string `json:"filename" validate:"required,max=255,excludesall=/\\"`
 }
 
 // GetSignedURLResponse is the response used in GetSignedURL calls.
 type GetSignedURLResponse struct {
-	Url       string            `json:"url"`
-	Metadata  map[string]string `json:"metadata"`
-	Tagging   map[string]string `json:"tagging"`
-	Expires   time.Time         `json:"expires"`
-	Temporary bool              `json:"temporary"`
+	Url            string            `json:"url"`
+	Metadata       map[string]string `json:"metadata"`
+	CustomMetadata map[string]string `json:"customMetadata,omitempty"`
+	Tagging        map[string]string `json:"tagging"`
+	Expires        time.Time         `json:"expires"`
+	Temporary      bool              `json:"temporary"`
+	Quarantined    bool              `json:"quarantined"`
+	Trashed        bool              `json:"trashed"`
+	Encrypted      bool              `json:"encrypted"`
 }
 
 // ListSignedURLResponse is the response for many GetSignedURLResponse fields
@@ -31,3 +85,59 @@ type ListObjectsRequest struct {
 	Prefixes   []string              `json:"prefixes"`
 	Definition utils.FileDefinitions `json:"definition"`
 }
+
+// FileResponse contains the file information saved in the DB.
+type FileResponse struct {
+	UserId         string                       `json:"userId"`
+	Prefix         string                       `json:"prefix"`
+	Author         string                       `json:"author"`
+	Title          string                       `json:"title"`
+	Filename       string                       `json:"filename"`
+	FolderId       string                       `json:"folderId"`
+	CorrelationId  string                       `json:"correlationId"`
+	Metadata       map[string]string            `json:"metadata"`
+	DefinitionsMap utils.FileDefinitionsMapping `json:"definitionsMap"`
+	FileLabels     *labeler.FileLabels          `json:"fileLabels"`
+	ContentType    string                       `json:"contentType"`
+	Status         FileStatus                   `json:"status"`
+	FlaggedLabels  []labeler.ModerationLabel    `json:"flaggedLabels"`
+	OccurredOn     time.Time                    `json:"occurredOn"`
+	Version        int64                        `json:"version"`
+	ContentVersion int64                        `json:"contentVersion"`
+	Encrypted      bool                         `json:"encrypted"`
+	TrashedOn      *time.Time                   `json:"trashedOn,omitempty"`
+}
+
+// ListFilesRequest contains the files listing query parameters.
+type ListFilesRequest struct {
+	UserId     string                `form:"userId" validate:"required,uuid"`
+	Definition utils.FileDefinitions `form:"definition"`
+	Limit      int32                 `form:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor     string                `form:"cursor"`
+	Order      string                `form:"order" validate:"omitempty,oneof=asc desc"`
+}
+
+// ListFilesResponse is the response used in files listing calls.
+// The cursor is empty when there are no more files.
+type ListFilesResponse struct {
+	Items  []*ListFilesItem `json:"items"`
+	Cursor string           `json:"cursor"`
+}
+
+// ListFilesItem contains the file information and the requested definition signed URL.
+// The signed URL is empty for quarantined and temporary files, and for the encrypted files,
+// which are downloaded from the download proxy.
+type ListFilesItem struct {
+	Prefix         string                       `json:"prefix"`
+	Title          string                       `json:"title"`
+	Author         string                       `json:"author"`
+	Filename       string                       `json:"filename"`
+	FolderId       string                       `json:"folderId"`
+	ContentType    string                       `json:"contentType"`
+	Status         FileStatus                   `json:"status"`
+	Metadata       map[string]string            `json:"metadata"`
+	DefinitionsMap utils.FileDefinitionsMapping `json:"definitionsMap"`
+	OccurredOn     time.Time                    `json:"occurredOn"`
+	Encrypted      bool                         `json:"encrypted"`
+	SignedURL      *GetSignedURLResponse        `json:"signedUrl,omitempty"`
+}
@@ -0,0 +1,88 @@
+package views
+
+import (
+	"testing"
+
+	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateFileRequest(t *testing.T) {
+	title := "Renamed file"
+	schema := &DynamoDBUploadSchema{Title: "File title", Author: "Luan", CorrelationId: "correlation"}
+
+	request := &UpdateFileRequest{Title: &title}
+	request.Apply(schema)
+
+	assert.Equal(t, "Renamed file", schema.Title)
+	assert.Equal(t, "Luan", schema.Author)
+	assert.Equal(t, "correlation", schema.CorrelationId)
+	assert.Nil(t, request.Validate(schema))
+
+	short := "abc"
+	request = &UpdateFileRequest{Title: &short}
+	request.Apply(schema)
+	assert.Error(t, request.Validate(schema))
+
+	empty := ""
+	request = &UpdateFileRequest{Title: &empty}
+	request.Apply(schema)
+	assert.Nil(t, request.Validate(schema))
+}
+
+func TestFileTransfer(t *testing.T) {
+	schema := &DynamoDBUploadSchema{
+		UserId:     "user",
+		Prefix:     "user/file",
+		Version:    3,
+		FolderId:   "folder",
+		Metadata:   map[string]string{"project": "apollo"},
+		TextObject: TextObjectPrefix("user/file"),
+		DefinitionsMap: utils.FileDefinitionsMapping{
+			utils.LowDef:  "user/file/low.webp",
+			utils.HighDef: "user/file/high.webp",
+		},
+	}
+
+	file := schema.Transfer("other", "other/copy")
+
+	assert.Equal(t, "other", file.UserId)
+	assert.Equal(t, "other/copy", file.Prefix)
+	assert.Equal(t, int64(0), file.Version)
+	assert.Empty(t, file.FolderId)
+	assert.Equal(t, "folder", schema.Transfer("user", "user/copy").FolderId)
+	assert.Equal(t, "_text/other/copy.json", file.TextObject)
+	assert.Equal(t, "other/copy/low.webp", file.DefinitionsMap[utils.LowDef])
+	assert.Equal(t, "other/copy/high.webp", file.DefinitionsMap[utils.HighDef])
+
+	file.Metadata["project"] = "gemini"
+	assert.Equal(t, "apollo", schema.Metadata["project"])
+	assert.Equal(t, "user/file/low.webp", schema.DefinitionsMap[utils.LowDef])
+}
+
+func TestFileMove(t *testing.T) {
+	schema := &DynamoDBUploadSchema{
+		UserId:             "user",
+		Prefix:             "user/file",
+		ContentVersion:     2,
+		LastContentVersion: 2,
+		TextObject:         TextObjectPrefix("user/file/v2"),
+		DefinitionsMap:     utils.FileDefinitionsMapping{utils.LowDef: "user/file/v2/low.webp"},
+		Versions: []FileVersion{{
+			Version:        1,
+			TextObject:     TextObjectPrefix("user/file"),
+			DefinitionsMap: utils.FileDefinitionsMapping{utils.LowDef: "user/file/low.webp"},
+		}},
+	}
+
+	file := schema.Move("other", "other/file")
+
+	assert.Equal(t, int64(2), file.CurrentContentVersion())
+	assert.Equal(t, int64(2), file.LastContentVersion)
+	assert.Equal(t, "_text/other/file/v2.json", file.TextObject)
+	assert.Equal(t, "other/file/v2/low.webp", file.DefinitionsMap[utils.LowDef])
+	assert.Len(t, file.Versions, 1)
+	assert.Equal(t, "_text/other/file.json", file.Versions[0].TextObject)
+	assert.Equal(t, "other/file/low.webp", file.Versions[0].DefinitionsMap[utils.LowDef])
+	assert.Equal(t, "user/file/low.webp", schema.Versions[0].DefinitionsMap[utils.LowDef])
+}
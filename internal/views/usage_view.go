@@ -0,0 +1,56 @@
+package views
+
+import (
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoDBUsageSchema is the DynamoDB storage usage counters schema view, of a user and upload strategy.
+// The updates add the bytes and files to the stored counters, so the concurrent updates are all counted.
+type DynamoDBUsageSchema struct {
+	UserId   string `dynamodbav:"userId"`
+	Strategy string `dynamodbav:"strategy"`
+	Bytes    int64  `dynamodbav:"bytes"`
+	Files    int64  `dynamodbav:"files"`
+}
+
+func (d DynamoDBUsageSchema) GetKey() (map[string]types.AttributeValue, error) {
+	userId, err := attributevalue.Marshal(d.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	strategy, err := attributevalue.Marshal(d.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]types.AttributeValue{
+		"userId":   userId,
+		"strategy": strategy,
+	}, nil
+}
+
+func (d DynamoDBUsageSchema) GetUpdateFields() expression.UpdateBuilder {
+	return expression.
+		Add(expression.Name("bytes"), expression.Value(d.Bytes)).
+		Add(expression.Name("files"), expression.Value(d.Files))
+}
+
+// StrategyUsage contains the stored bytes and files of an upload strategy.
+type StrategyUsage struct {
+	Bytes int64 `json:"bytes"`
+	Files int64 `json:"files"`
+}
+
+// UsageResponse contains the storage usage, with its breakdown by upload strategy.
+// The zero max bytes and files don't limit the usage.
+type UsageResponse struct {
+	UserId     string                    `json:"userId,omitempty"`
+	Bytes      int64                     `json:"bytes"`
+	Files      int64                     `json:"files"`
+	MaxBytes   int64                     `json:"maxBytes,omitempty"`
+	MaxFiles   int64                     `json:"maxFiles,omitempty"`
+	Strategies map[string]*StrategyUsage `json:"strategies"`
+}
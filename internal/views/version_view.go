@@ -0,0 +1,212 @@
+package views
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gearpoint/filepoint/pkg/labeler"
+	"github.com/gearpoint/filepoint/pkg/utils"
+)
+
+// FileVersion is a previous content of the file, kept in the file record history.
+type FileVersion struct {
+	Version        int64                        `dynamodbav:"version"`
+	Filename       string                       `dynamodbav:"filename"`
+	ContentType    string                       `dynamodbav:"contentType"`
+	DefinitionsMap utils.FileDefinitionsMapping `dynamodbav:"definitionsMap"`
+	FileLabels     *labeler.FileLabels          `dynamodbav:"fileLabels"`
+	TextObject     string                       `dynamodbav:"textObject"`
+	TextTerms      []string                     `dynamodbav:"textTerms"`
+	Status         FileStatus                   `dynamodbav:"status"`
+	FlaggedLabels  []labeler.ModerationLabel    `dynamodbav:"flaggedLabels"`
+	OccurredOn     time.Time                    `dynamodbav:"occurredOn"`
+	Encryption     *FileEncryption              `dynamodbav:"encryption"`
+	Size           int64                        `dynamodbav:"size"`
+}
+
+// ToFileVersionResponse returns the file version response view.
+func (v FileVersion) ToFileVersionResponse(current bool) *FileVersionResponse {
+	return &FileVersionResponse{
+		Version:     v.Version,
+		Filename:    v.Filename,
+		ContentType: v.ContentType,
+		Status:      v.Status,
+		OccurredOn:  v.OccurredOn,
+		Current:     current,
+	}
+}
+
+// VersionPrefix returns the objects prefix of the file content version.
+// The first version objects are stored in the file prefix, the next ones in numbered sub-keys.
+func VersionPrefix(prefix string, version int64) string {
+	if version <= 1 {
+		return prefix
+	}
+
+	return utils.CreatePrefix(prefix, fmt.Sprintf("v%d", version))
+}
+
+// CurrentContentVersion returns the number of the current file content. The files start in the first version.
+func (d DynamoDBUploadSchema) CurrentContentVersion() int64 {
+	if d.ContentVersion < 1 {
+		return 1
+	}
+
+	return d.ContentVersion
+}
+
+// NextContentVersion reserves the number of a new file content.
+func (d *DynamoDBUploadSchema) NextContentVersion() int64 {
+	d.LastContentVersion = max(d.LastContentVersion, d.CurrentContentVersion()) + 1
+
+	return d.LastContentVersion
+}
+
+// CurrentFileVersion returns the current file content as a version.
+func (d DynamoDBUploadSchema) CurrentFileVersion() FileVersion {
+	return FileVersion{
+		Version:        d.CurrentContentVersion(),
+		Filename:       d.Filename,
+		ContentType:    d.ContentType,
+		DefinitionsMap: d.DefinitionsMap,
+		FileLabels:     d.FileLabels,
+		TextObject:     d.TextObject,
+		TextTerms:      d.TextTerms,
+		Status:         d.Status,
+		FlaggedLabels:  d.FlaggedLabels,
+		OccurredOn:     d.OccurredOn,
+		Encryption:     d.Encryption,
+		Size:           d.Size,
+	}
+}
+
+// GetFileVersion returns the file content version, current or previous.
+func (d DynamoDBUploadSchema) GetFileVersion(version int64) (FileVersion, bool) {
+	if version == d.CurrentContentVersion() {
+		return d.CurrentFileVersion(), true
+	}
+
+	for _, fileVersion := range d.Versions {
+		if fileVersion.Version == version {
+			return fileVersion, true
+		}
+	}
+
+	return FileVersion{}, false
+}
+
+// AddFileVersion adds the version to the file history, replacing the version with the same number.
+func (d *DynamoDBUploadSchema) AddFileVersion(fileVersion FileVersion) {
+	versions := []FileVersion{fileVersion}
+	for _, previous := range d.Versions {
+		if previous.Version != fileVersion.Version {
+			versions = append(versions, previous)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+
+	d.Versions = versions
+}
+
+// SetFileVersion makes the version the current file content, keeping the replaced content in the history.
+// The trashed files keep their status.
+func (d *DynamoDBUploadSchema) SetFileVersion(fileVersion FileVersion) {
+	current := d.CurrentFileVersion()
+
+	versions := []FileVersion{}
+	for _, previous := range d.Versions {
+		if previous.Version != fileVersion.Version {
+			versions = append(versions, previous)
+		}
+	}
+	d.Versions = versions
+	d.AddFileVersion(current)
+
+	d.ContentVersion = fileVersion.Version
+	d.LastContentVersion = max(d.LastContentVersion, fileVersion.Version)
+	d.Filename = fileVersion.Filename
+	d.ContentType = fileVersion.ContentType
+	d.DefinitionsMap = fileVersion.DefinitionsMap
+	d.FileLabels = fileVersion.FileLabels
+	d.TextObject = fileVersion.TextObject
+	d.TextTerms = fileVersion.TextTerms
+	d.FlaggedLabels = fileVersion.FlaggedLabels
+	d.OccurredOn = fileVersion.OccurredOn
+	d.Encryption = fileVersion.Encryption
+	d.Size = fileVersion.Size
+
+	if d.Status == StatusTrashed {
+		d.PreviousStatus = fileVersion.Status
+	} else {
+		d.Status = fileVersion.Status
+	}
+}
+
+// StoredSize returns the bytes of the current and previous contents, counted in the storage usage.
+func (d DynamoDBUploadSchema) StoredSize() int64 {
+	size := d.Size
+	for _, fileVersion := range d.Versions {
+		size += fileVersion.Size
+	}
+
+	return size
+}
+
+// VersionsObjects returns the objects of the previous versions, with their text documents.
+func (d DynamoDBUploadSchema) VersionsObjects() []string {
+	var objects []string
+	for _, fileVersion := range d.Versions {
+		for _, objectName := range fileVersion.DefinitionsMap {
+			objects = append(objects, objectName)
+		}
+		if fileVersion.TextObject != "" {
+			objects = append(objects, fileVersion.TextObject)
+		}
+	}
+
+	return objects
+}
+
+// ReplaceContentResponse is the response of the content replacement, with the reserved version number.
+type ReplaceContentResponse struct {
+	Version int64 `json:"version"`
+}
+
+// FileVersionResponse contains the file version information.
+type FileVersionResponse struct {
+	Version     int64      `json:"version"`
+	Filename    string     `json:"filename"`
+	ContentType string     `json:"contentType"`
+	Status      FileStatus `json:"status"`
+	OccurredOn  time.Time  `json:"occurredOn"`
+	Current     bool       `json:"current"`
+}
+
+// FileVersionsResponse contains the file versions, sorted by number.
+type FileVersionsResponse struct {
+	Current  int64                  `json:"current"`
+	Versions []*FileVersionResponse `json:"versions"`
+}
+
+// ToFileVersionsResponse returns the file versions response view.
+func (d DynamoDBUploadSchema) ToFileVersionsResponse() *FileVersionsResponse {
+	current := d.CurrentFileVersion()
+
+	versions := append([]FileVersion{current}, d.Versions...)
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+
+	response := &FileVersionsResponse{
+		Current:  current.Version,
+		Versions: make([]*FileVersionResponse, len(versions)),
+	}
+	for i, fileVersion := range versions {
+		response.Versions[i] = fileVersion.ToFileVersionResponse(fileVersion.Version == current.Version)
+	}
+
+	return response
+}
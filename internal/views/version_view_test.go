@@ -0,0 +1,68 @@
+package views
+
+import (
+	"testing"
+
+	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionPrefix(t *testing.T) {
+	assert.Equal(t, "user/file", VersionPrefix("user/file", 0))
+	assert.Equal(t, "user/file", VersionPrefix("user/file", 1))
+	assert.Equal(t, "user/file/v2", VersionPrefix("user/file", 2))
+}
+
+func TestFileVersions(t *testing.T) {
+	schema := &DynamoDBUploadSchema{
+		Prefix:         "user/file",
+		Filename:       "first.png",
+		ContentType:    "image/png",
+		Status:         StatusActive,
+		DefinitionsMap: utils.FileDefinitionsMapping{utils.MediumDef: "user/file/medium.webp"},
+	}
+
+	assert.Equal(t, int64(1), schema.CurrentContentVersion())
+	assert.Equal(t, int64(2), schema.NextContentVersion())
+	assert.Equal(t, int64(3), schema.NextContentVersion())
+
+	// The third version is processed before the second one.
+	schema.SetFileVersion(FileVersion{
+		Version:        3,
+		Filename:       "third.png",
+		Status:         StatusQuarantined,
+		DefinitionsMap: utils.FileDefinitionsMapping{utils.MediumDef: "user/file/v3/medium.webp"},
+	})
+	schema.AddFileVersion(FileVersion{
+		Version:        2,
+		Filename:       "second.png",
+		Status:         StatusActive,
+		DefinitionsMap: utils.FileDefinitionsMapping{utils.MediumDef: "user/file/v2/medium.webp"},
+	})
+
+	assert.Equal(t, int64(3), schema.CurrentContentVersion())
+	assert.Equal(t, "third.png", schema.Filename)
+	assert.Equal(t, StatusQuarantined, schema.Status)
+	assert.Equal(t, []string{"user/file/medium.webp", "user/file/v2/medium.webp"}, schema.VersionsObjects())
+
+	response := schema.ToFileVersionsResponse()
+	assert.Equal(t, int64(3), response.Current)
+	assert.Len(t, response.Versions, 3)
+	assert.Equal(t, "first.png", response.Versions[0].Filename)
+	assert.True(t, response.Versions[2].Current)
+
+	fileVersion, ok := schema.GetFileVersion(1)
+	assert.True(t, ok)
+	schema.Trash(schema.OccurredOn)
+	schema.SetFileVersion(fileVersion)
+
+	assert.Equal(t, int64(1), schema.CurrentContentVersion())
+	assert.Equal(t, "user/file/medium.webp", schema.DefinitionsMap[utils.MediumDef])
+	assert.Equal(t, StatusTrashed, schema.Status)
+	assert.Equal(t, StatusActive, schema.PreviousStatus)
+	assert.Len(t, schema.Versions, 2)
+	assert.Equal(t, int64(4), schema.NextContentVersion())
+
+	_, ok = schema.GetFileVersion(5)
+	assert.False(t, ok)
+}
@@ -1,5 +1,26 @@
 package views
 
+const (
+	// ModerationFlaggedEvent is sent when a file is quarantined by the moderation policy.
+	ModerationFlaggedEvent = "moderation.flagged"
+	// FileUpdatedEvent is sent when the file attributes are edited.
+	FileUpdatedEvent = "file.updated"
+	// FileCopiedEvent is sent when the file is copied to another prefix.
+	FileCopiedEvent = "file.copied"
+	// FileMovedEvent is sent when the file is moved to another user.
+	FileMovedEvent = "file.moved"
+	// FileRenamedEvent is sent when the file display name is changed.
+	FileRenamedEvent = "file.renamed"
+	// FileVersionRestoredEvent is sent when a previous content version is made current.
+	FileVersionRestoredEvent = "file.version_restored"
+	// ArchiveReadyEvent is sent when an async archive is built, with its signed link.
+	ArchiveReadyEvent = "archive.ready"
+	// ArchiveFailedEvent is sent when an async archive can't be built.
+	ArchiveFailedEvent = "archive.failed"
+	// ArchiveExtractedEvent is sent when an uploaded archive is extracted, with the created and rejected files.
+	ArchiveExtractedEvent = "archive.extracted"
+)
+
 // WebhookPayload contains the webhook request body.
 type WebhookPayload struct {
 	Id            string `json:"id"`
@@ -8,3 +29,18 @@ type WebhookPayload struct {
 	Location      string `json:"location"`
 	Error         string `json:"error"`
 }
+
+// EventWebhookPayload contains the webhook request body of the file events.
+type EventWebhookPayload struct {
+	Event         string `json:"event"`
+	Id            string `json:"id"`
+	CorrelationId string `json:"correlationId"`
+	Location      string `json:"location"`
+	Data          any    `json:"data"`
+}
+
+// FileTransferData is the file events data of copied and moved files.
+type FileTransferData struct {
+	Source string        `json:"source"`
+	File   *FileResponse `json:"file"`
+}
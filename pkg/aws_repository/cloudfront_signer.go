@@ -0,0 +1,132 @@
+package aws_repository
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/cloudfront/sign"
+	"github.com/gearpoint/filepoint/pkg/logger"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"go.uber.org/zap"
+)
+
+// CloudfrontSigner signs the Cloudfront distribution URLs and cookies.
+type CloudfrontSigner struct {
+	distribution string
+	keyId        string
+	privateKey   *rsa.PrivateKey
+}
+
+var _ storage.URLSigner = (*CloudfrontSigner)(nil)
+
+// NewCloudfrontSigner returns a CloudfrontSigner instance, loading the PEM private key file.
+func NewCloudfrontSigner(distribution string, keyId string, keyFile string) (*CloudfrontSigner, error) {
+	privateKey, err := sign.LoadPEMPrivKeyFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CloudfrontSigner{
+		distribution: distribution,
+		keyId:        keyId,
+		privateKey:   privateKey,
+	}, nil
+}
+
+// SignURL returns the Cloudfront signed URL of the prefix.
+// Policies with IP range or start time conditions are signed as custom policies.
+func (s *CloudfrontSigner) SignURL(prefix string, expires time.Time, policy storage.SignPolicy) (string, error) {
+	objectUrl := withDisposition(fmt.Sprintf("%s/%s", s.distribution, prefix), policy)
+
+	signer := sign.NewURLSigner(s.keyId, s.privateKey)
+
+	var signedUrl string
+	var err error
+	if policy.IsCustom() {
+		signedUrl, err = signer.SignWithPolicy(objectUrl, customPolicy(objectUrl, expires, policy))
+	} else {
+		signedUrl, err = signer.Sign(objectUrl, expires)
+	}
+	if err != nil {
+		logger.Error("error signing url", zap.String("prefix", prefix), zap.Error(err))
+		return "", errors.New("an internal error occured")
+	}
+
+	return signedUrl, nil
+}
+
+// ChecksTags is false, as Cloudfront serves the objects without checking their tags.
+func (s *CloudfrontSigner) ChecksTags() bool {
+	return false
+}
+
+// SignCookies returns the Cloudfront signed cookies of the folder prefix, with a wildcard custom policy.
+func (s *CloudfrontSigner) SignCookies(prefix string, expires time.Time, options storage.CookieOptions) ([]*http.Cookie, error) {
+	policy := &sign.Policy{
+		Statements: []sign.Statement{
+			{
+				Resource: fmt.Sprintf("%s/%s/*", s.distribution, strings.Trim(prefix, "/")),
+				Condition: sign.Condition{
+					DateLessThan: sign.NewAWSEpochTime(expires),
+				},
+			},
+		},
+	}
+
+	signer := sign.NewCookieSigner(s.keyId, s.privateKey, func(o *sign.CookieOptions) {
+		o.Domain = options.Domain
+		o.Path = options.Path
+		o.Secure = options.Secure
+	})
+
+	cookies, err := signer.SignWithPolicy(policy)
+	if err != nil {
+		logger.Error("error signing cookies", zap.String("prefix", prefix), zap.Error(err))
+		return nil, errors.New("an internal error occured")
+	}
+
+	for _, cookie := range cookies {
+		cookie.HttpOnly = true
+		cookie.Expires = expires
+	}
+
+	return cookies, nil
+}
+
+// customPolicy returns the Cloudfront custom policy of the url.
+func customPolicy(objectUrl string, expires time.Time, policy storage.SignPolicy) *sign.Policy {
+	condition := sign.Condition{
+		DateLessThan: sign.NewAWSEpochTime(expires),
+	}
+	if !policy.NotBefore.IsZero() {
+		condition.DateGreaterThan = sign.NewAWSEpochTime(policy.NotBefore)
+	}
+	if policy.IPRange != "" {
+		condition.IPAddress = &sign.IPAddress{SourceIP: policy.IPRange}
+	}
+
+	return &sign.Policy{
+		Statements: []sign.Statement{
+			{
+				Resource:  objectUrl,
+				Condition: condition,
+			},
+		},
+	}
+}
+
+// withDisposition adds the policy forced content disposition to the url.
+// S3 overrides the response Content-Disposition header with this query param.
+func withDisposition(objectUrl string, policy storage.SignPolicy) string {
+	disposition := policy.Disposition()
+	if disposition == "" {
+		return objectUrl
+	}
+
+	return fmt.Sprintf("%s?response-content-disposition=%s", objectUrl, url.QueryEscape(disposition))
+}
@@ -1,8 +1,11 @@
 package aws_repository
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -11,9 +14,13 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/gearpoint/filepoint/internal/views"
 	"github.com/gearpoint/filepoint/pkg/logger"
+	"github.com/gearpoint/filepoint/pkg/metadata"
 	"go.uber.org/zap"
 )
 
+// The max number of requests in a DynamoDB batch write.
+const maxBatchWriteItems = 25
+
 // TableExists determines whether a DynamoDB table exists.
 func (r *AWSRepository) TableExists(tableName string) (bool, error) {
 	_, err := r.dynamoClient.DescribeTable(
@@ -50,7 +57,7 @@ func (r *AWSRepository) GetTableRow(tableName string, schema views.DynamoDBSchem
 	}
 
 	if response.Item == nil {
-		return errors.New("table row not found")
+		return metadata.ErrNotFound
 	}
 
 	err = attributevalue.UnmarshalMap(response.Item, &schema)
@@ -77,15 +84,30 @@ func (r *AWSRepository) AddTableRow(tableName string, schema views.DynamoDBSchem
 
 // UpdateTableRow adds a new row to the DynamoDB table.
 func (r *AWSRepository) UpdateTableRow(tableName string, schema views.DynamoDBSchema) error {
+	return r.updateTableRow(tableName, schema, nil)
+}
+
+// UpdateTableRowIf updates the DynamoDB table row if the condition is met.
+// Use IsConditionFailed to check the error when it's not.
+func (r *AWSRepository) UpdateTableRowIf(tableName string, schema views.DynamoDBSchema, condition expression.ConditionBuilder) error {
+	return r.updateTableRow(tableName, schema, &condition)
+}
+
+// updateTableRow updates the DynamoDB table row. The condition is optional.
+func (r *AWSRepository) updateTableRow(tableName string, schema views.DynamoDBSchema, condition *expression.ConditionBuilder) error {
 	key, err := schema.GetKey()
 	if err != nil {
 		return err
 	}
 
-	expr, err := expression.NewBuilder().WithUpdate(
+	builder := expression.NewBuilder().WithUpdate(
 		schema.GetUpdateFields(),
-	).Build()
+	)
+	if condition != nil {
+		builder = builder.WithCondition(*condition)
+	}
 
+	expr, err := builder.Build()
 	if err != nil {
 		return err
 	}
@@ -96,12 +118,19 @@ func (r *AWSRepository) UpdateTableRow(tableName string, schema views.DynamoDBSc
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
 		ReturnValues:              types.ReturnValueUpdatedNew,
 	})
 
 	return err
 }
 
+// IsConditionFailed checks if the error is a failed DynamoDB condition check.
+func IsConditionFailed(err error) bool {
+	var conditionErr *types.ConditionalCheckFailedException
+	return errors.As(err, &conditionErr)
+}
+
 // DelTableRow removes a row from the DynamoDB table.
 func (r *AWSRepository) DelTableRow(tableName string, schema views.DynamoDBSchema) error {
 	key, err := schema.GetKey()
@@ -151,14 +180,19 @@ func (r *AWSRepository) DelTablePartition(tableName string, partitionKey string,
 				)
 				continue
 			}
+			// The key is read before the next unmarshal, as the schema is reused.
+			key, err := itemSchema.GetKey()
+			if err != nil {
+				logger.Error("Unable to get item key for deletion",
+					zap.Error(err),
+				)
+				continue
+			}
+
 			wg.Add(1)
-			go func(itemSchema views.DynamoDBSchema) {
-				key, err := itemSchema.GetKey()
-				if err != nil {
-					return
-				}
+			go func(key map[string]types.AttributeValue) {
 				defer wg.Done()
-				_, err = r.dynamoClient.DeleteItem(r.ctx, &dynamodb.DeleteItemInput{
+				_, err := r.dynamoClient.DeleteItem(r.ctx, &dynamodb.DeleteItemInput{
 					Key: key, TableName: aws.String(tableName),
 				})
 				if err != nil {
@@ -168,10 +202,210 @@ func (r *AWSRepository) DelTablePartition(tableName string, partitionKey string,
 						zap.Error(err),
 					)
 				}
-			}(itemSchema)
+			}(key)
 		}
 	}
 	wg.Wait()
 
 	return nil
 }
+
+// QueryTableRows gets all rows from a partition of the DynamoDB table.
+// The rows are unmarshalled into out, that must be a pointer to a slice of schemas.
+// The filter is optional.
+func (r *AWSRepository) QueryTableRows(tableName string, partitionKey string, partitionValue string, filter *expression.ConditionBuilder, out interface{}) error {
+	keyEx := expression.Key(partitionKey).Equal(expression.Value(partitionValue))
+	builder := expression.NewBuilder().WithKeyCondition(keyEx)
+	if filter != nil {
+		builder = builder.WithFilter(*filter)
+	}
+
+	expr, err := builder.Build()
+	if err != nil {
+		return err
+	}
+
+	queryPaginator := dynamodb.NewQueryPaginator(r.dynamoClient, &dynamodb.QueryInput{
+		TableName:                 aws.String(tableName),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+	})
+
+	var items []map[string]types.AttributeValue
+	for queryPaginator.HasMorePages() {
+		res, err := queryPaginator.NextPage(r.ctx)
+		if err != nil {
+			return err
+		}
+		items = append(items, res.Items...)
+	}
+
+	return attributevalue.UnmarshalListOfMaps(items, out)
+}
+
+// ScanTableRows gets all rows from the DynamoDB table that match the filter.
+// The rows are unmarshalled into out, that must be a pointer to a slice of schemas.
+// It reads the whole table, so avoid it in user facing requests.
+func (r *AWSRepository) ScanTableRows(tableName string, filter expression.ConditionBuilder, out interface{}) error {
+	expr, err := expression.NewBuilder().WithFilter(filter).Build()
+	if err != nil {
+		return err
+	}
+
+	scanPaginator := dynamodb.NewScanPaginator(r.dynamoClient, &dynamodb.ScanInput{
+		TableName:                 aws.String(tableName),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		FilterExpression:          expr.Filter(),
+	})
+
+	var items []map[string]types.AttributeValue
+	for scanPaginator.HasMorePages() {
+		res, err := scanPaginator.NextPage(r.ctx)
+		if err != nil {
+			return err
+		}
+		items = append(items, res.Items...)
+	}
+
+	return attributevalue.UnmarshalListOfMaps(items, out)
+}
+
+// QueryTablePage gets a page of rows from the DynamoDB table.
+// The rows are unmarshalled into out, that must be a pointer to a slice of schemas.
+// The cursor is the opaque position of the page, empty for the first one.
+// It returns the cursor of the next page, or empty if it's the last one.
+func (r *AWSRepository) QueryTablePage(tableName string, keyCondition expression.KeyConditionBuilder, limit int32, forward bool, cursor string, out interface{}) (string, error) {
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return "", err
+	}
+
+	startKey, err := decodeCursor(cursor)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := r.dynamoClient.Query(r.ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(tableName),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExclusiveStartKey:         startKey,
+		ScanIndexForward:          aws.Bool(forward),
+		Limit:                     aws.Int32(limit),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	err = attributevalue.UnmarshalListOfMaps(res.Items, out)
+	if err != nil {
+		return "", err
+	}
+
+	return encodeCursor(res.LastEvaluatedKey)
+}
+
+// encodeCursor encodes the DynamoDB last evaluated key as an opaque cursor.
+func encodeCursor(lastKey map[string]types.AttributeValue) (string, error) {
+	if len(lastKey) == 0 {
+		return "", nil
+	}
+
+	var key map[string]interface{}
+	err := attributevalue.UnmarshalMap(lastKey, &key)
+	if err != nil {
+		return "", err
+	}
+
+	keyBytes, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(keyBytes), nil
+}
+
+// decodeCursor decodes the opaque cursor to the DynamoDB exclusive start key.
+func decodeCursor(cursor string) (map[string]types.AttributeValue, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	keyBytes, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, metadata.ErrInvalidCursor
+	}
+
+	var key map[string]interface{}
+	err = json.Unmarshal(keyBytes, &key)
+	if err != nil {
+		return nil, metadata.ErrInvalidCursor
+	}
+
+	return attributevalue.MarshalMap(key)
+}
+
+// BatchAddTableRows adds many rows to the DynamoDB table.
+func (r *AWSRepository) BatchAddTableRows(tableName string, schemas []views.DynamoDBSchema) error {
+	var requests []types.WriteRequest
+	for _, schema := range schemas {
+		item, err := attributevalue.MarshalMap(schema)
+		if err != nil {
+			return errors.New("error reading table row info")
+		}
+
+		requests = append(requests, types.WriteRequest{
+			PutRequest: &types.PutRequest{Item: item},
+		})
+	}
+
+	return r.batchWrite(tableName, requests)
+}
+
+// BatchDelTableRows removes many rows from the DynamoDB table.
+func (r *AWSRepository) BatchDelTableRows(tableName string, schemas []views.DynamoDBSchema) error {
+	var requests []types.WriteRequest
+	for _, schema := range schemas {
+		key, err := schema.GetKey()
+		if err != nil {
+			return err
+		}
+
+		requests = append(requests, types.WriteRequest{
+			DeleteRequest: &types.DeleteRequest{Key: key},
+		})
+	}
+
+	return r.batchWrite(tableName, requests)
+}
+
+// batchWrite sends the write requests in batches, retrying the unprocessed items.
+func (r *AWSRepository) batchWrite(tableName string, requests []types.WriteRequest) error {
+	for start := 0; start < len(requests); start += maxBatchWriteItems {
+		end := min(start+maxBatchWriteItems, len(requests))
+
+		pending := map[string][]types.WriteRequest{
+			tableName: requests[start:end],
+		}
+
+		for attempt := 1; len(pending) > 0; attempt++ {
+			res, err := r.dynamoClient.BatchWriteItem(r.ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems: pending,
+			})
+			if err != nil {
+				return err
+			}
+
+			pending = res.UnprocessedItems
+			if len(pending) > 0 {
+				time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,412 @@
+package aws_repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/metadata"
+)
+
+// DynamoDBMetadataStore implements the metadata store.
+var _ metadata.MetadataStore = (*DynamoDBMetadataStore)(nil)
+
+// DynamoDBMetadataStore stores the file records and the labels index in DynamoDB tables.
+type DynamoDBMetadataStore struct {
+	repository      *AWSRepository
+	tableName       string
+	indexTableName  string
+	folderTableName string
+	apiKeyTableName string
+	shareTableName  string
+	usageTableName  string
+}
+
+// NewDynamoDBMetadataStore returns a DynamoDBMetadataStore instance.
+// The index table is optional. Without it, the index queries return metadata.ErrIndexNotConfigured.
+// The folder table is optional too. Without it, the folder methods return metadata.ErrFoldersNotConfigured.
+// The API keys and shares tables are only used by the main store.
+// Without them, the API key and share methods return metadata.ErrApiKeysNotConfigured and metadata.ErrSharesNotConfigured.
+// The usage table is optional. Without it, the usage methods return metadata.ErrUsageNotConfigured.
+func NewDynamoDBMetadataStore(
+	repository *AWSRepository, tableName string, indexTableName string, folderTableName string, apiKeyTableName string,
+	shareTableName string, usageTableName string,
+) *DynamoDBMetadataStore {
+	return &DynamoDBMetadataStore{
+		repository:      repository,
+		tableName:       tableName,
+		indexTableName:  indexTableName,
+		folderTableName: folderTableName,
+		apiKeyTableName: apiKeyTableName,
+		shareTableName:  shareTableName,
+		usageTableName:  usageTableName,
+	}
+}
+
+// GetFile returns the file record.
+func (s *DynamoDBMetadataStore) GetFile(userId string, prefix string) (*views.DynamoDBUploadSchema, error) {
+	file := &views.DynamoDBUploadSchema{
+		UserId: userId,
+		Prefix: prefix,
+	}
+
+	err := s.repository.GetTableRow(s.tableName, file)
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// PutFile adds or replaces the file record.
+func (s *DynamoDBMetadataStore) PutFile(file *views.DynamoDBUploadSchema) error {
+	return s.repository.AddTableRow(s.tableName, file)
+}
+
+// UpdateFile updates the file record.
+func (s *DynamoDBMetadataStore) UpdateFile(file *views.DynamoDBUploadSchema) error {
+	condition := expression.AttributeExists(expression.Name("prefix"))
+
+	err := s.repository.UpdateTableRowIf(s.tableName, file, condition)
+	if IsConditionFailed(err) {
+		return metadata.ErrNotFound
+	}
+
+	return err
+}
+
+// UpdateFileIfVersion updates the file record if the stored version matches.
+// The records written before the versioning have no version, which matches the version 0.
+func (s *DynamoDBMetadataStore) UpdateFileIfVersion(file *views.DynamoDBUploadSchema, version int64) error {
+	condition := expression.Name("version").Equal(expression.Value(version))
+	if version == 0 {
+		condition = expression.AttributeExists(expression.Name("prefix")).And(
+			expression.Or(expression.AttributeNotExists(expression.Name("version")), condition),
+		)
+	}
+
+	updated := *file
+	updated.Version = version + 1
+
+	err := s.repository.UpdateTableRowIf(s.tableName, &updated, condition)
+	if IsConditionFailed(err) {
+		return metadata.ErrVersionConflict
+	}
+	if err != nil {
+		return err
+	}
+
+	file.Version = updated.Version
+
+	return nil
+}
+
+// DeleteFile deletes the file record.
+func (s *DynamoDBMetadataStore) DeleteFile(userId string, prefix string) error {
+	return s.repository.DelTableRow(s.tableName, &views.DynamoDBUploadSchema{
+		UserId: userId,
+		Prefix: prefix,
+	})
+}
+
+// DeleteUserFiles deletes all the user files and index items.
+func (s *DynamoDBMetadataStore) DeleteUserFiles(userId string) error {
+	err := s.repository.DelTablePartition(s.tableName, "userId", userId, &views.DynamoDBUploadSchema{})
+	if err != nil || s.indexTableName == "" {
+		return err
+	}
+
+	return s.repository.DelTablePartition(s.indexTableName, "userId", userId, &views.DynamoDBLabelIndexSchema{})
+}
+
+// ListFiles returns a page of the user files.
+func (s *DynamoDBMetadataStore) ListFiles(userId string, limit int32, cursor string, forward bool) ([]*views.DynamoDBUploadSchema, string, error) {
+	userKey := expression.Key("userId").Equal(expression.Value(userId))
+
+	files := []*views.DynamoDBUploadSchema{}
+	nextCursor, err := s.repository.QueryTablePage(s.tableName, userKey, limit, forward, cursor, &files)
+
+	return files, nextCursor, err
+}
+
+// ListFilesByStatus returns the files with the status.
+// Without the userId, the whole table is scanned.
+func (s *DynamoDBMetadataStore) ListFilesByStatus(userId string, status views.FileStatus) ([]*views.DynamoDBUploadSchema, error) {
+	filter := expression.Name("status").Equal(expression.Value(status))
+
+	files := []*views.DynamoDBUploadSchema{}
+
+	var err error
+	if userId != "" {
+		err = s.repository.QueryTableRows(s.tableName, "userId", userId, &filter, &files)
+	} else {
+		err = s.repository.ScanTableRows(s.tableName, filter, &files)
+	}
+
+	return files, err
+}
+
+// ListFolderFiles returns the files in the folder.
+func (s *DynamoDBMetadataStore) ListFolderFiles(userId string, folderId string) ([]*views.DynamoDBUploadSchema, error) {
+	filter := expression.Name("folderId").Equal(expression.Value(folderId))
+	if folderId == "" {
+		filter = expression.Or(expression.AttributeNotExists(expression.Name("folderId")), filter)
+	}
+
+	files := []*views.DynamoDBUploadSchema{}
+	err := s.repository.QueryTableRows(s.tableName, "userId", userId, &filter, &files)
+
+	return files, err
+}
+
+// QueryIndex returns a page of the user index items whose key begins with keyPrefix.
+func (s *DynamoDBMetadataStore) QueryIndex(userId string, keyPrefix string, limit int32, cursor string) ([]*views.DynamoDBLabelIndexSchema, string, error) {
+	if s.indexTableName == "" {
+		return nil, "", metadata.ErrIndexNotConfigured
+	}
+
+	keyCondition := expression.Key("userId").Equal(expression.Value(userId)).And(
+		expression.Key("labelKey").BeginsWith(keyPrefix),
+	)
+
+	items := []*views.DynamoDBLabelIndexSchema{}
+	nextCursor, err := s.repository.QueryTablePage(s.indexTableName, keyCondition, limit, true, cursor, &items)
+
+	return items, nextCursor, err
+}
+
+// PutIndex adds the index items. It's a no-op without the index table.
+func (s *DynamoDBMetadataStore) PutIndex(items []*views.DynamoDBLabelIndexSchema) error {
+	if s.indexTableName == "" {
+		return nil
+	}
+
+	return s.repository.BatchAddTableRows(s.indexTableName, indexSchemas(items))
+}
+
+// DeleteIndex deletes the index items. It's a no-op without the index table.
+func (s *DynamoDBMetadataStore) DeleteIndex(items []*views.DynamoDBLabelIndexSchema) error {
+	if s.indexTableName == "" {
+		return nil
+	}
+
+	return s.repository.BatchDelTableRows(s.indexTableName, indexSchemas(items))
+}
+
+// GetFolder returns the folder.
+func (s *DynamoDBMetadataStore) GetFolder(userId string, folderId string) (*views.DynamoDBFolderSchema, error) {
+	if s.folderTableName == "" {
+		return nil, metadata.ErrFoldersNotConfigured
+	}
+
+	folder := &views.DynamoDBFolderSchema{
+		UserId:   userId,
+		FolderId: folderId,
+	}
+
+	err := s.repository.GetTableRow(s.folderTableName, folder)
+	if errors.Is(err, metadata.ErrNotFound) {
+		return nil, metadata.ErrFolderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return folder, nil
+}
+
+// PutFolder adds or replaces the folder.
+func (s *DynamoDBMetadataStore) PutFolder(folder *views.DynamoDBFolderSchema) error {
+	if s.folderTableName == "" {
+		return metadata.ErrFoldersNotConfigured
+	}
+
+	return s.repository.AddTableRow(s.folderTableName, folder)
+}
+
+// DeleteFolder deletes the folder.
+func (s *DynamoDBMetadataStore) DeleteFolder(userId string, folderId string) error {
+	if s.folderTableName == "" {
+		return metadata.ErrFoldersNotConfigured
+	}
+
+	return s.repository.DelTableRow(s.folderTableName, &views.DynamoDBFolderSchema{
+		UserId:   userId,
+		FolderId: folderId,
+	})
+}
+
+// ListFolders returns all the user folders.
+func (s *DynamoDBMetadataStore) ListFolders(userId string) ([]*views.DynamoDBFolderSchema, error) {
+	if s.folderTableName == "" {
+		return nil, metadata.ErrFoldersNotConfigured
+	}
+
+	folders := []*views.DynamoDBFolderSchema{}
+	err := s.repository.QueryTableRows(s.folderTableName, "userId", userId, nil, &folders)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata.SortFolders(folders)
+
+	return folders, nil
+}
+
+// GetApiKey returns the API key.
+func (s *DynamoDBMetadataStore) GetApiKey(keyId string) (*views.DynamoDBApiKeySchema, error) {
+	if s.apiKeyTableName == "" {
+		return nil, metadata.ErrApiKeysNotConfigured
+	}
+
+	apiKey := &views.DynamoDBApiKeySchema{KeyId: keyId}
+
+	err := s.repository.GetTableRow(s.apiKeyTableName, apiKey)
+	if errors.Is(err, metadata.ErrNotFound) {
+		return nil, metadata.ErrApiKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+// PutApiKey adds or replaces the API key.
+func (s *DynamoDBMetadataStore) PutApiKey(apiKey *views.DynamoDBApiKeySchema) error {
+	if s.apiKeyTableName == "" {
+		return metadata.ErrApiKeysNotConfigured
+	}
+
+	return s.repository.AddTableRow(s.apiKeyTableName, apiKey)
+}
+
+// ListApiKeys returns the tenant API keys. The keys are few, so the table is scanned.
+func (s *DynamoDBMetadataStore) ListApiKeys(tenantId string) ([]*views.DynamoDBApiKeySchema, error) {
+	if s.apiKeyTableName == "" {
+		return nil, metadata.ErrApiKeysNotConfigured
+	}
+
+	filter := expression.AttributeExists(expression.Name("keyId"))
+	if tenantId != "" {
+		filter = expression.Name("tenantId").Equal(expression.Value(tenantId))
+	}
+
+	apiKeys := []*views.DynamoDBApiKeySchema{}
+	err := s.repository.ScanTableRows(s.apiKeyTableName, filter, &apiKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata.SortApiKeys(apiKeys)
+
+	return apiKeys, nil
+}
+
+// SetApiKeyLastUsed updates the key last used time.
+func (s *DynamoDBMetadataStore) SetApiKeyLastUsed(keyId string, lastUsedOn time.Time) error {
+	if s.apiKeyTableName == "" {
+		return metadata.ErrApiKeysNotConfigured
+	}
+
+	condition := expression.AttributeExists(expression.Name("keyId"))
+
+	err := s.repository.UpdateTableRowIf(s.apiKeyTableName, &views.DynamoDBApiKeyUsageSchema{
+		KeyId:      keyId,
+		LastUsedOn: lastUsedOn,
+	}, condition)
+	if IsConditionFailed(err) {
+		return metadata.ErrApiKeyNotFound
+	}
+
+	return err
+}
+
+// GetShare returns the share.
+func (s *DynamoDBMetadataStore) GetShare(tokenHash string) (*views.DynamoDBShareSchema, error) {
+	if s.shareTableName == "" {
+		return nil, metadata.ErrSharesNotConfigured
+	}
+
+	share := &views.DynamoDBShareSchema{TokenHash: tokenHash}
+
+	err := s.repository.GetTableRow(s.shareTableName, share)
+	if errors.Is(err, metadata.ErrNotFound) {
+		return nil, metadata.ErrShareNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return share, nil
+}
+
+// PutShare adds or replaces the share.
+func (s *DynamoDBMetadataStore) PutShare(share *views.DynamoDBShareSchema) error {
+	if s.shareTableName == "" {
+		return metadata.ErrSharesNotConfigured
+	}
+
+	return s.repository.AddTableRow(s.shareTableName, share)
+}
+
+// IncrementShareDownloads counts a share download, conditioned on the share downloads left.
+func (s *DynamoDBMetadataStore) IncrementShareDownloads(tokenHash string) error {
+	if s.shareTableName == "" {
+		return metadata.ErrSharesNotConfigured
+	}
+
+	condition := expression.AttributeExists(expression.Name("tokenHash")).And(
+		expression.Or(
+			expression.Name("maxDownloads").Equal(expression.Value(0)),
+			expression.Name("downloads").LessThan(expression.Name("maxDownloads")),
+		),
+	)
+
+	err := s.repository.UpdateTableRowIf(s.shareTableName, &views.DynamoDBShareDownloadSchema{
+		TokenHash: tokenHash,
+	}, condition)
+	if IsConditionFailed(err) {
+		if _, err := s.GetShare(tokenHash); err != nil {
+			return err
+		}
+		return metadata.ErrShareLimitReached
+	}
+
+	return err
+}
+
+// AddUsage adds the bytes and files to the user strategy counters. The counters are created by the first update.
+func (s *DynamoDBMetadataStore) AddUsage(usage *views.DynamoDBUsageSchema) error {
+	if s.usageTableName == "" {
+		return metadata.ErrUsageNotConfigured
+	}
+
+	return s.repository.UpdateTableRow(s.usageTableName, usage)
+}
+
+// ListUsage returns the user counters of each strategy, sorted by the table sort key.
+func (s *DynamoDBMetadataStore) ListUsage(userId string) ([]*views.DynamoDBUsageSchema, error) {
+	if s.usageTableName == "" {
+		return nil, metadata.ErrUsageNotConfigured
+	}
+
+	usage := []*views.DynamoDBUsageSchema{}
+	err := s.repository.QueryTableRows(s.usageTableName, "userId", userId, nil, &usage)
+	if err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// indexSchemas returns the index items as DynamoDB schemas.
+func indexSchemas(items []*views.DynamoDBLabelIndexSchema) []views.DynamoDBSchema {
+	schemas := make([]views.DynamoDBSchema, len(items))
+	for i, item := range items {
+		schemas[i] = item
+	}
+
+	return schemas
+}
@@ -1,79 +1,133 @@
 package aws_repository
 
 import (
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/rekognition"
 	"github.com/aws/aws-sdk-go-v2/service/rekognition/types"
-	"go.uber.org/zap"
 
-	"github.com/gearpoint/filepoint/pkg/logger"
+	"github.com/gearpoint/filepoint/pkg/labeler"
 	"github.com/gearpoint/filepoint/pkg/utils"
 )
 
 const (
-	// The max number of labels returned.
-	maxRekognitionLabels int32 = 10
+	// The default min confidence of the detections.
+	defaultMinConfidence float32 = 97
+
+	// The default max number of labels returned.
+	defaultMaxRekognitionLabels int32 = 10
 )
 
-// GetImageLabels returns the image labels. Suports only JPEG and PNG, up to 15MB.
-func (r *AWSRepository) GetImageLabels(prefix string) error {
+// SetLabelerConfig sets the Rekognition detections thresholds.
+func (r *AWSRepository) SetLabelerConfig(minConfidence float32, maxLabels int32) {
+	r.minConfidence = minConfidence
+	r.maxLabels = maxLabels
+}
+
+// rekognitionImage returns the Rekognition image input from the given prefix.
+func (r *AWSRepository) rekognitionImage(prefix string) *types.Image {
+	return &types.Image{
+		S3Object: &types.S3Object{
+			Bucket: &r.config.Bucket,
+			Name:   &prefix,
+		},
+	}
+}
+
+// DetectLabels returns the image labels. Suports only JPEG and PNG, up to 15MB.
+func (r *AWSRepository) DetectLabels(prefix string) ([]labeler.Label, error) {
 	if utils.IsDevEnvironment() {
-		return nil
+		return nil, nil
 	}
 
-	var minConfidence float32 = 97
-	var maxLabels = maxRekognitionLabels
-	var labels []string
+	minConfidence := r.minConfidence
+	maxLabels := r.maxLabels
 
 	result, err := r.rekoClient.DetectLabels(r.ctx, &rekognition.DetectLabelsInput{
-		Image: &types.Image{
-			S3Object: &types.S3Object{
-				Bucket: &r.config.Bucket,
-				Name:   &prefix,
-			},
-		},
+		Image:         r.rekognitionImage(prefix),
 		MaxLabels:     &maxLabels,
 		MinConfidence: &minConfidence,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	var labels []labeler.Label
 	for _, label := range result.Labels {
-		labels = append(labels, *label.Name)
+		labels = append(labels, labeler.Label{
+			Name:       aws.ToString(label.Name),
+			Confidence: aws.ToFloat32(label.Confidence),
+		})
 	}
 
-	moderation, _ := r.rekoClient.DetectModerationLabels(r.ctx, &rekognition.DetectModerationLabelsInput{
-		Image: &types.Image{
-			S3Object: &types.S3Object{
-				Bucket: &r.config.Bucket,
-				Name:   &prefix,
-			},
-		},
+	return labels, nil
+}
+
+// DetectModerationLabels returns the image moderation labels.
+func (r *AWSRepository) DetectModerationLabels(prefix string) ([]labeler.ModerationLabel, error) {
+	if utils.IsDevEnvironment() {
+		return nil, nil
+	}
+
+	minConfidence := r.minConfidence
+
+	result, err := r.rekoClient.DetectModerationLabels(r.ctx, &rekognition.DetectModerationLabelsInput{
+		Image:         r.rekognitionImage(prefix),
 		MinConfidence: &minConfidence,
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	for _, label := range moderation.ModerationLabels {
-		labels = append(labels, *label.Name)
+	var labels []labeler.ModerationLabel
+	for _, label := range result.ModerationLabels {
+		labels = append(labels, labeler.ModerationLabel{
+			Name:       aws.ToString(label.Name),
+			ParentName: aws.ToString(label.ParentName),
+			Confidence: aws.ToFloat32(label.Confidence),
+		})
 	}
 
-	text, _ := r.rekoClient.DetectText(r.ctx, &rekognition.DetectTextInput{
-		Image: &types.Image{
-			S3Object: &types.S3Object{
-				Bucket: &r.config.Bucket,
-				Name:   &prefix,
-			},
-		},
+	return labels, nil
+}
+
+// DetectText returns the image detected text lines.
+func (r *AWSRepository) DetectText(prefix string) ([]labeler.TextDetection, error) {
+	if utils.IsDevEnvironment() {
+		return nil, nil
+	}
+
+	result, err := r.rekoClient.DetectText(r.ctx, &rekognition.DetectTextInput{
+		Image: r.rekognitionImage(prefix),
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	for _, label := range text.TextDetections {
-		if *label.Confidence >= minConfidence {
-			labels = append(labels, *label.DetectedText)
+	var text []labeler.TextDetection
+	for _, detection := range result.TextDetections {
+		if detection.Type != types.TextTypesLine || aws.ToFloat32(detection.Confidence) < r.minConfidence {
+			continue
 		}
-	}
 
-	logger.Info("Done labelling image", zap.Any("labels", labels))
+		var box labeler.BoundingBox
+		if detection.Geometry != nil && detection.Geometry.BoundingBox != nil {
+			b := detection.Geometry.BoundingBox
+			box = labeler.BoundingBox{
+				Left:   aws.ToFloat32(b.Left),
+				Top:    aws.ToFloat32(b.Top),
+				Width:  aws.ToFloat32(b.Width),
+				Height: aws.ToFloat32(b.Height),
+			}
+		}
+
+		text = append(text, labeler.TextDetection{
+			Text:        aws.ToString(detection.DetectedText),
+			Confidence:  aws.ToFloat32(detection.Confidence),
+			BoundingBox: box,
+		})
+	}
 
-	return err
+	return text, nil
 }
 
 // StartVideoLabelsDetection starts the video label and moderation detection.
@@ -82,7 +136,7 @@ func (r *AWSRepository) StartVideoLabelsDetection(prefix string) error {
 		return nil
 	}
 
-	var minConfidence float32 = 97
+	minConfidence := r.minConfidence
 
 	r.rekoClient.StartLabelDetection(r.ctx, &rekognition.StartLabelDetectionInput{
 		Video: &types.Video{
@@ -2,18 +2,18 @@ package aws_repository
 
 import (
 	"context"
-	"crypto/rsa"
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/feature/cloudfront/sign"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/rekognition"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	cfg "github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/pkg/storage"
 	"github.com/gearpoint/filepoint/pkg/utils"
 )
 
@@ -24,13 +24,14 @@ var unsupportedRekoLocations = map[string]bool{
 
 // AWSRepository contains the aws config and implementations.
 type AWSRepository struct {
-	ctx                  context.Context
-	config               *cfg.AWSConfig
-	s3Client             *s3.Client
-	rekoClient           *rekognition.Client
-	dynamoClient         *dynamodb.Client
-	cloudfrontDist       string
-	cloudfrontPrivateKey rsa.PrivateKey
+	ctx           context.Context
+	config        *cfg.AWSConfig
+	s3Client      *s3.Client
+	rekoClient    *rekognition.Client
+	dynamoClient  *dynamodb.Client
+	signer        storage.URLSigner
+	minConfidence float32
+	maxLabels     int32
 }
 
 // NewAWSRepository returns a AWSRepository instance.
@@ -51,22 +52,14 @@ func NewAWSRepository(awsConfig *cfg.AWSConfig, ctx context.Context) (*AWSReposi
 
 	dynamoClient := dynamodb.NewFromConfig(sdkConfig)
 
-	var rsaKey rsa.PrivateKey
-	if !utils.IsDevEnvironment() {
-		rsaKey, err = getPrivateKey(awsConfig.CloudfrontCrtFile)
-		if err != nil {
-			return nil, err
-		}
-	}
-
 	return &AWSRepository{
-		ctx:                  ctx,
-		config:               awsConfig,
-		s3Client:             s3Client,
-		rekoClient:           rekoClient,
-		dynamoClient:         dynamoClient,
-		cloudfrontDist:       awsConfig.CloudfrontDist,
-		cloudfrontPrivateKey: rsaKey,
+		ctx:           ctx,
+		config:        awsConfig,
+		s3Client:      s3Client,
+		rekoClient:    rekoClient,
+		dynamoClient:  dynamoClient,
+		minConfidence: defaultMinConfidence,
+		maxLabels:     defaultMaxRekognitionLabels,
 	}, nil
 }
 
@@ -98,10 +91,9 @@ func getEndpointResolver(awsConfig *cfg.AWSConfig) config.LoadOptionsFunc {
 	return config.WithEndpointResolverWithOptions(customResolver)
 }
 
-// getPrivateKey returns a private key from a file path.
-func getPrivateKey(filepath string) (rsa.PrivateKey, error) {
-	key, err := sign.LoadPEMPrivKeyFile(filepath)
-	return *key, err
+// SetSigner sets the URL signer of the S3 objects.
+func (r *AWSRepository) SetSigner(signer storage.URLSigner) {
+	r.signer = signer
 }
 
 // CheckIsNotFoundError checks if the aws error is not found.
@@ -113,3 +105,12 @@ func CheckIsNotFoundError(err error) bool {
 
 	return false
 }
+
+// storageError wraps the not found errors with storage.ErrNotFound.
+func storageError(prefix string, err error) error {
+	if CheckIsNotFoundError(err) {
+		return fmt.Errorf("%w: %s", storage.ErrNotFound, prefix)
+	}
+
+	return err
+}
@@ -5,27 +5,19 @@ import (
 	"fmt"
 	"io"
 	"sync"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/feature/cloudfront/sign"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/gearpoint/filepoint/internal/views"
 	"github.com/gearpoint/filepoint/pkg/logger"
-	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/gearpoint/filepoint/pkg/storage"
 	"go.uber.org/zap"
 )
 
-const (
-	// Temporary file rule. This rule must be configured at the defined bucket.
-	// You can set, for example, a 1 day exclusion policy.
-	TempFileRule = "temporary-file"
-
-	// Signed url expiration time. The cache time will be based in this value also.
-	SignExpiration = 12 * time.Hour
-)
+// AWSRepository implements the S3 blob store.
+var _ storage.BlobStore = (*AWSRepository)(nil)
 
 // PutObject puts a new object in the given prefix.
 // Do not use it for large files.
@@ -64,53 +56,39 @@ func (r *AWSRepository) DownloadFile(prefix string) (io.ReadCloser, error) {
 		Key:    &prefix,
 	})
 	if err != nil {
-		return nil, err
+		return nil, storageError(prefix, err)
 	}
 
 	return result.Body, nil
 }
 
-// GetSignedObject returns a Signed object from the given prefix.
-func (r *AWSRepository) GetSignedObject(prefix string) (*views.GetSignedURLResponse, error) {
-	defaultErr := errors.New("an internal error occured")
-
-	obj, err := r.headObject(prefix)
-	if err != nil {
-		return nil, err
+// DownloadRange returns the object bytes from the offset. A negative length reads until the end.
+func (r *AWSRepository) DownloadRange(prefix string, offset int64, length int64) (io.ReadCloser, error) {
+	byteRange := fmt.Sprintf("bytes=%d-", offset)
+	if length >= 0 {
+		byteRange = fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
 	}
 
-	tagging, temp, err := r.GetObjectTagging(prefix)
+	result, err := r.s3Client.GetObject(r.ctx, &s3.GetObjectInput{
+		Bucket: &r.config.Bucket,
+		Key:    &prefix,
+		Range:  &byteRange,
+	})
 	if err != nil {
-		return nil, err
+		return nil, storageError(prefix, err)
 	}
 
-	url := fmt.Sprintf("%s/%s", r.cloudfrontDist, prefix)
-	expires := time.Now().Add(SignExpiration)
-
-	if utils.IsDevEnvironment() {
-		url := fmt.Sprintf("%s/%s/%s", r.config.CloudfrontDist, r.config.Bucket, prefix)
-		return &views.GetSignedURLResponse{
-			Url:       url,
-			Metadata:  obj.Metadata,
-			Tagging:   tagging,
-			Expires:   expires,
-			Temporary: temp,
-		}, nil
-	}
+	return result.Body, nil
+}
 
-	signer := sign.NewURLSigner(r.config.CloudfrontKeyId, &r.cloudfrontPrivateKey)
-	signedUrl, err := signer.Sign(url, expires)
-	if err != nil {
-		return nil, defaultErr
-	}
+// GetSignedObject returns a Signed object from the given prefix, with the repository URL signer.
+func (r *AWSRepository) GetSignedObject(prefix string, policy storage.SignPolicy) (*views.GetSignedURLResponse, error) {
+	return storage.SignObject(r, prefix, policy)
+}
 
-	return &views.GetSignedURLResponse{
-		Url:       signedUrl,
-		Metadata:  obj.Metadata,
-		Tagging:   tagging,
-		Expires:   expires,
-		Temporary: temp,
-	}, nil
+// Signer returns the repository URL signer.
+func (r *AWSRepository) Signer() storage.URLSigner {
+	return r.signer
 }
 
 // ListObjects lists all objects in the given prefix.
@@ -179,6 +157,50 @@ func (r *AWSRepository) DeleteMany(prefixes []string) error {
 	return err
 }
 
+// HeadObject returns the object attributes.
+func (r *AWSRepository) HeadObject(prefix string) (*storage.ObjectInfo, error) {
+	obj, err := r.headObject(prefix)
+	if err != nil {
+		return nil, storageError(prefix, err)
+	}
+
+	return &storage.ObjectInfo{
+		ContentType:   aws.ToString(obj.ContentType),
+		ContentLength: aws.ToInt64(obj.ContentLength),
+		LastModified:  aws.ToTime(obj.LastModified),
+		ETag:          aws.ToString(obj.ETag),
+		Metadata:      obj.Metadata,
+	}, nil
+}
+
+// CopyObject copies the object and its tags, replacing the metadata when it isn't nil.
+func (r *AWSRepository) CopyObject(srcPrefix string, dstPrefix string, metadata map[string]string) error {
+	input := &s3.CopyObjectInput{
+		Bucket:     &r.config.Bucket,
+		Key:        &dstPrefix,
+		CopySource: aws.String(fmt.Sprintf("%s/%s", r.config.Bucket, srcPrefix)),
+	}
+
+	if metadata != nil {
+		obj, err := r.headObject(srcPrefix)
+		if err != nil {
+			return storageError(srcPrefix, err)
+		}
+
+		// Replacing the metadata also replaces the content type, so it's copied.
+		input.MetadataDirective = s3types.MetadataDirectiveReplace
+		input.Metadata = metadata
+		input.ContentType = obj.ContentType
+	}
+
+	_, err := r.s3Client.CopyObject(r.ctx, input)
+	if err != nil {
+		return storageError(srcPrefix, err)
+	}
+
+	return nil
+}
+
 // headObject returns the object infos.
 func (r *AWSRepository) headObject(prefix string) (*s3.HeadObjectOutput, error) {
 	return r.s3Client.HeadObject(r.ctx, &s3.HeadObjectInput{
@@ -227,7 +249,7 @@ func (r *AWSRepository) GetObjectTagging(prefix string) (map[string]string, bool
 	var temporary bool
 	tags := make(map[string]string)
 	for _, tag := range response.TagSet {
-		if *tag.Key == TempFileRule {
+		if *tag.Key == storage.TempFileRule {
 			temporary = true
 		}
 
@@ -235,3 +257,31 @@ func (r *AWSRepository) GetObjectTagging(prefix string) (map[string]string, bool
 	}
 	return tags, temporary, nil
 }
+
+// AddObjectTags adds the tags to the object, keeping the existing ones.
+func (r *AWSRepository) AddObjectTags(prefix string, tagging map[string]string) error {
+	tags, _, err := r.GetObjectTagging(prefix)
+	if err != nil {
+		return err
+	}
+
+	for tagKey, tagValue := range tagging {
+		tags[tagKey] = tagValue
+	}
+
+	return r.PutObjectTagging(prefix, tags)
+}
+
+// RemoveObjectTags removes the tags from the object, keeping the other ones.
+func (r *AWSRepository) RemoveObjectTags(prefix string, tagKeys ...string) error {
+	tags, _, err := r.GetObjectTagging(prefix)
+	if err != nil {
+		return err
+	}
+
+	for _, tagKey := range tagKeys {
+		delete(tags, tagKey)
+	}
+
+	return r.PutObjectTagging(prefix, tags)
+}
@@ -0,0 +1,73 @@
+package aws_repository
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/gearpoint/filepoint/pkg/logger"
+	"github.com/gearpoint/filepoint/pkg/storage"
+	"go.uber.org/zap"
+)
+
+// The S3 presigned URLs max expiration.
+const maxPresignExpiration = 7 * 24 * time.Hour
+
+// S3Presigner presigns the S3 GET requests, for deployments without Cloudfront.
+// The presigned URLs don't support IP range and start time conditions, nor cookies.
+type S3Presigner struct {
+	ctx           context.Context
+	bucket        string
+	presignClient *s3.PresignClient
+}
+
+var _ storage.URLSigner = (*S3Presigner)(nil)
+
+// NewS3Presigner returns a S3Presigner instance of the repository bucket.
+func NewS3Presigner(r *AWSRepository) *S3Presigner {
+	return &S3Presigner{
+		ctx:           r.ctx,
+		bucket:        r.config.Bucket,
+		presignClient: s3.NewPresignClient(r.s3Client),
+	}
+}
+
+// SignURL returns the presigned GET URL of the prefix.
+func (s *S3Presigner) SignURL(prefix string, expires time.Time, policy storage.SignPolicy) (string, error) {
+	if policy.IsCustom() {
+		return "", storage.ErrUnsupportedPolicy
+	}
+
+	expiration := time.Until(expires)
+	if expiration > maxPresignExpiration {
+		return "", storage.ErrUnsupportedPolicy
+	}
+
+	input := &s3.GetObjectInput{
+		Bucket: &s.bucket,
+		Key:    &prefix,
+	}
+	if disposition := policy.Disposition(); disposition != "" {
+		input.ResponseContentDisposition = &disposition
+	}
+
+	request, err := s.presignClient.PresignGetObject(s.ctx, input, s3.WithPresignExpires(expiration))
+	if err != nil {
+		logger.Error("error presigning url", zap.String("prefix", prefix), zap.Error(err))
+		return "", errors.New("an internal error occured")
+	}
+
+	return request.URL, nil
+}
+
+// ChecksTags is false, as S3 serves the presigned objects without checking their tags.
+func (s *S3Presigner) ChecksTags() bool {
+	return false
+}
+
+// SignCookies isn't supported by the S3 presigned URLs.
+func (s *S3Presigner) SignCookies(prefix string, expires time.Time, options storage.CookieOptions) ([]*http.Cookie, error) {
+	return nil, storage.ErrUnsupportedPolicy
+}
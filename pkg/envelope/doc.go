@@ -0,0 +1,3 @@
+// Package envelope contains the files envelope encryption: the objects are encrypted with per-file data keys,
+// which are wrapped with a master key and stored with the file records.
+package envelope
@@ -0,0 +1,117 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encrypt(t *testing.T, dataKey []byte, plaintext []byte) []byte {
+	reader, err := NewEncryptReader(dataKey, bytes.NewReader(plaintext))
+	assert.Nil(t, err)
+
+	encrypted, err := io.ReadAll(reader)
+	assert.Nil(t, err)
+
+	return encrypted
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	dataKey, err := NewDataKey()
+	assert.Nil(t, err)
+
+	for _, size := range []int{0, 10, ChunkSize, 2*ChunkSize + 100} {
+		plaintext := make([]byte, size)
+		rand.Read(plaintext)
+
+		encrypted := encrypt(t, dataKey, plaintext)
+		plainSize, err := PlaintextSize(int64(len(encrypted)))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(size), plainSize)
+
+		reader, err := NewDecryptReader(dataKey, bytes.NewReader(encrypted), int64(len(encrypted)))
+		assert.Nil(t, err)
+		decrypted, err := io.ReadAll(reader)
+		assert.Nil(t, err)
+		assert.Equal(t, plaintext, append([]byte{}, decrypted...))
+	}
+}
+
+func TestDecryptRange(t *testing.T) {
+	dataKey, err := NewDataKey()
+	assert.Nil(t, err)
+
+	plaintext := make([]byte, 3*ChunkSize)
+	rand.Read(plaintext)
+	encrypted := encrypt(t, dataKey, plaintext)
+
+	reader, err := NewDecryptReader(dataKey, bytes.NewReader(encrypted), int64(len(encrypted)))
+	assert.Nil(t, err)
+
+	_, err = reader.Seek(ChunkSize-10, io.SeekStart)
+	assert.Nil(t, err)
+	content := make([]byte, 20)
+	_, err = io.ReadFull(reader, content)
+	assert.Nil(t, err)
+	assert.Equal(t, plaintext[ChunkSize-10:ChunkSize+10], content)
+}
+
+func TestDecryptTampered(t *testing.T) {
+	dataKey, err := NewDataKey()
+	assert.Nil(t, err)
+
+	plaintext := make([]byte, 2*ChunkSize)
+	encrypted := encrypt(t, dataKey, plaintext)
+
+	// The objects truncated in the chunks boundaries have an invalid size.
+	truncated := encrypted[:headerSize+2*sealedChunkSize]
+	_, err = NewDecryptReader(dataKey, bytes.NewReader(truncated), int64(len(truncated)))
+	assert.ErrorIs(t, err, ErrInvalidObject)
+
+	// The second chunk is truncated, so it's read as the final one.
+	truncated = encrypted[:headerSize+sealedChunkSize+100]
+	reader, err := NewDecryptReader(dataKey, bytes.NewReader(truncated), int64(len(truncated)))
+	assert.Nil(t, err)
+	_, err = io.ReadAll(reader)
+	assert.ErrorIs(t, err, ErrInvalidObject)
+
+	otherKey, err := NewDataKey()
+	assert.Nil(t, err)
+	reader, err = NewDecryptReader(otherKey, bytes.NewReader(encrypted), int64(len(encrypted)))
+	assert.Nil(t, err)
+	_, err = io.ReadAll(reader)
+	assert.ErrorIs(t, err, ErrInvalidObject)
+
+	_, err = PlaintextSize(int64(headerSize + 5))
+	assert.ErrorIs(t, err, ErrInvalidObject)
+}
+
+func TestLocalKeyWrapper(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "kek")
+	assert.Nil(t, os.WriteFile(keyFile, []byte("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=\n"), 0600))
+
+	wrapper, err := NewLocalKeyWrapper(keyFile)
+	assert.Nil(t, err)
+
+	dataKey, err := NewDataKey()
+	assert.Nil(t, err)
+
+	wrappedKey, err := wrapper.WrapKey(dataKey)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(wrappedKey), string(dataKey))
+
+	unwrappedKey, err := wrapper.UnwrapKey(wrapper.KeyId(), wrappedKey)
+	assert.Nil(t, err)
+	assert.Equal(t, dataKey, unwrappedKey)
+
+	_, err = wrapper.UnwrapKey("local:other", wrappedKey)
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+
+	_, err = newLocalKeyWrapper([]byte("short"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,128 @@
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Backend defines the master key implementation.
+type Backend string
+
+const (
+	// Local wraps the data keys with a key encryption key (KEK) read from a local file.
+	Local Backend = "local"
+)
+
+// DataKeySize is the size of the files data keys (AES-256).
+const DataKeySize = 32
+
+// ErrKeyMismatch is returned when the data key was wrapped by another master key.
+var ErrKeyMismatch = errors.New("the data key was wrapped by another master key")
+
+// KeyWrapper wraps the files data keys with a master key.
+// It can be implemented by a KMS, so the master key never leaves it.
+type KeyWrapper interface {
+	// KeyId identifies the master key, it's stored with the wrapped keys.
+	KeyId() string
+	WrapKey(dataKey []byte) ([]byte, error)
+	UnwrapKey(keyId string, wrappedKey []byte) ([]byte, error)
+}
+
+// NewDataKey returns a new random data key.
+func NewDataKey() ([]byte, error) {
+	dataKey := make([]byte, DataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+
+	return dataKey, nil
+}
+
+// LocalKeyWrapper wraps the data keys with AES-GCM, using a local KEK.
+type LocalKeyWrapper struct {
+	keyId string
+	aead  cipher.AEAD
+}
+
+// NewLocalKeyWrapper returns a new LocalKeyWrapper instance.
+// The key file contains the base64 encoded 32 bytes KEK, i.e. generated with "openssl rand -base64 32".
+func NewLocalKeyWrapper(keyFile string) (*LocalKeyWrapper, error) {
+	content, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	kek, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid key file: %w", err)
+	}
+
+	return newLocalKeyWrapper(kek)
+}
+
+// newLocalKeyWrapper returns a new LocalKeyWrapper instance with the given KEK.
+func newLocalKeyWrapper(kek []byte) (*LocalKeyWrapper, error) {
+	if len(kek) != DataKeySize {
+		return nil, fmt.Errorf("invalid key size: the key must have %d bytes", DataKeySize)
+	}
+
+	aead, err := newAEAD(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	// The key id is a fingerprint, so the keys wrapped by a rotated KEK are detected.
+	fingerprint := sha256.Sum256(kek)
+
+	return &LocalKeyWrapper{
+		keyId: "local:" + hex.EncodeToString(fingerprint[:8]),
+		aead:  aead,
+	}, nil
+}
+
+// KeyId returns the KEK fingerprint.
+func (w *LocalKeyWrapper) KeyId() string {
+	return w.keyId
+}
+
+// WrapKey encrypts the data key. The random nonce is prepended to the wrapped key.
+func (w *LocalKeyWrapper) WrapKey(dataKey []byte) ([]byte, error) {
+	nonce := make([]byte, w.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return w.aead.Seal(nonce, nonce, dataKey, []byte(w.keyId)), nil
+}
+
+// UnwrapKey decrypts the data key.
+func (w *LocalKeyWrapper) UnwrapKey(keyId string, wrappedKey []byte) ([]byte, error) {
+	if keyId != w.keyId {
+		return nil, ErrKeyMismatch
+	}
+
+	nonceSize := w.aead.NonceSize()
+	if len(wrappedKey) < nonceSize {
+		return nil, errors.New("invalid wrapped key")
+	}
+
+	return w.aead.Open(nil, wrappedKey[:nonceSize], wrappedKey[nonceSize:], []byte(w.keyId))
+}
+
+// newAEAD returns the AES-GCM cipher of the key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
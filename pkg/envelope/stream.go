@@ -0,0 +1,296 @@
+package envelope
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// The encrypted objects start with a header, with the format version and the nonce prefix,
+// followed by the plaintext chunks sealed with AES-GCM. Each chunk nonce is the prefix and the chunk index,
+// and the last chunk is authenticated as final, so the truncated objects are detected.
+const (
+	// Algorithm identifies the encryption format, stored with the wrapped keys.
+	Algorithm = "AES-256-GCM-CHUNKED"
+
+	// ChunkSize is the size of the plaintext chunks.
+	ChunkSize = 64 << 10
+
+	magic           = "FPE1"
+	noncePrefixSize = 8
+	headerSize      = len(magic) + noncePrefixSize
+	tagSize         = 16
+	sealedChunkSize = ChunkSize + tagSize
+)
+
+// ErrInvalidObject is returned when the encrypted object is malformed or was changed.
+var ErrInvalidObject = errors.New("invalid encrypted object")
+
+var (
+	chunkData  = []byte{0}
+	finalChunk = []byte{1}
+)
+
+// EncryptReader encrypts the plaintext reader.
+type EncryptReader struct {
+	source      io.Reader
+	chunk       *chunkCipher
+	plaintext   []byte
+	buffer      []byte
+	index       uint32
+	done        bool
+	wroteHeader bool
+}
+
+// NewEncryptReader returns a reader of the encrypted source.
+func NewEncryptReader(dataKey []byte, source io.Reader) (*EncryptReader, error) {
+	noncePrefix := make([]byte, noncePrefixSize)
+	if _, err := rand.Read(noncePrefix); err != nil {
+		return nil, err
+	}
+
+	chunk, err := newChunkCipher(dataKey, noncePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncryptReader{
+		source:    source,
+		chunk:     chunk,
+		plaintext: make([]byte, ChunkSize),
+	}, nil
+}
+
+// Read reads the encrypted content.
+func (r *EncryptReader) Read(p []byte) (int, error) {
+	if !r.wroteHeader {
+		r.buffer = append([]byte(magic), r.chunk.noncePrefix...)
+		r.wroteHeader = true
+	}
+
+	for len(r.buffer) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+
+		// A full chunk is never the final one, an empty final chunk follows it at the end.
+		n, err := io.ReadFull(r.source, r.plaintext)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return 0, err
+		}
+
+		final := n < ChunkSize
+		r.buffer = r.chunk.seal(r.index, r.plaintext[:n], final)
+		r.index++
+		r.done = final
+	}
+
+	n := copy(p, r.buffer)
+	r.buffer = r.buffer[n:]
+
+	return n, nil
+}
+
+// PlaintextSize returns the plaintext size of the encrypted object size.
+func PlaintextSize(size int64) (int64, error) {
+	body := size - int64(headerSize)
+	if body < tagSize {
+		return 0, ErrInvalidObject
+	}
+
+	last := body % sealedChunkSize
+	if last < tagSize {
+		return 0, ErrInvalidObject
+	}
+
+	return body/sealedChunkSize*ChunkSize + last - tagSize, nil
+}
+
+// DecryptReader decrypts the encrypted object as an io.ReadSeeker, so it can be served with HTTP ranges.
+// Only the chunks of the read ranges are read from the source.
+type DecryptReader struct {
+	dataKey    []byte
+	source     io.ReadSeeker
+	size       int64
+	plainSize  int64
+	chunks     int64
+	offset     int64
+	sourcePos  int64
+	chunk      *chunkCipher
+	sealed     []byte
+	plaintext  []byte
+	chunkIndex int64
+}
+
+// NewDecryptReader returns a reader of the decrypted source. The size is the encrypted object size.
+func NewDecryptReader(dataKey []byte, source io.ReadSeeker, size int64) (*DecryptReader, error) {
+	plainSize, err := PlaintextSize(size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DecryptReader{
+		dataKey:    dataKey,
+		source:     source,
+		size:       size,
+		plainSize:  plainSize,
+		chunks:     (size-int64(headerSize))/sealedChunkSize + 1,
+		sourcePos:  -1,
+		sealed:     make([]byte, sealedChunkSize),
+		chunkIndex: -1,
+	}, nil
+}
+
+// Size returns the plaintext size.
+func (r *DecryptReader) Size() int64 {
+	return r.plainSize
+}
+
+// Read reads the plaintext from the current offset.
+func (r *DecryptReader) Read(p []byte) (int, error) {
+	if r.offset >= r.plainSize {
+		return 0, io.EOF
+	}
+
+	index := r.offset / ChunkSize
+	if index != r.chunkIndex {
+		if err := r.readChunk(index); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.plaintext[r.offset-index*ChunkSize:])
+	r.offset += int64(n)
+
+	return n, nil
+}
+
+// Seek sets the plaintext offset of the next read.
+func (r *DecryptReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.plainSize
+	}
+
+	if offset < 0 {
+		return 0, errors.New("negative plaintext offset")
+	}
+	r.offset = offset
+
+	return offset, nil
+}
+
+// readChunk reads and decrypts the chunk. The header is read with the first chunk.
+func (r *DecryptReader) readChunk(index int64) error {
+	if r.chunk == nil {
+		if err := r.readHeader(); err != nil {
+			return err
+		}
+	}
+
+	position := int64(headerSize) + index*sealedChunkSize
+	if position != r.sourcePos {
+		if _, err := r.source.Seek(position, io.SeekStart); err != nil {
+			return err
+		}
+	}
+
+	sealed := r.sealed
+	final := index == r.chunks-1
+	if final {
+		sealed = sealed[:r.size-position]
+	}
+
+	if _, err := io.ReadFull(r.source, sealed); err != nil {
+		r.sourcePos = -1
+		return err
+	}
+	r.sourcePos = position + int64(len(sealed))
+
+	plaintext, err := r.chunk.open(uint32(index), sealed, final)
+	if err != nil {
+		return ErrInvalidObject
+	}
+
+	r.plaintext = plaintext
+	r.chunkIndex = index
+
+	return nil
+}
+
+// readHeader reads the format version and the nonce prefix.
+func (r *DecryptReader) readHeader() error {
+	if _, err := r.source.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r.source, header); err != nil {
+		return err
+	}
+	r.sourcePos = int64(headerSize)
+
+	if string(header[:len(magic)]) != magic {
+		return ErrInvalidObject
+	}
+
+	chunk, err := newChunkCipher(r.dataKey, header[len(magic):])
+	if err != nil {
+		return err
+	}
+	r.chunk = chunk
+
+	return nil
+}
+
+// chunkCipher seals and opens the object chunks.
+type chunkCipher struct {
+	aead        cipher.AEAD
+	noncePrefix []byte
+}
+
+// newChunkCipher returns a new chunkCipher instance.
+func newChunkCipher(dataKey []byte, noncePrefix []byte) (*chunkCipher, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chunkCipher{
+		aead:        aead,
+		noncePrefix: noncePrefix,
+	}, nil
+}
+
+// nonce returns the chunk nonce.
+func (c *chunkCipher) nonce(index uint32) []byte {
+	nonce := make([]byte, noncePrefixSize+4)
+	copy(nonce, c.noncePrefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], index)
+
+	return nonce
+}
+
+// seal encrypts the chunk.
+func (c *chunkCipher) seal(index uint32, plaintext []byte, final bool) []byte {
+	additionalData := chunkData
+	if final {
+		additionalData = finalChunk
+	}
+
+	return c.aead.Seal(nil, c.nonce(index), plaintext, additionalData)
+}
+
+// open decrypts the chunk.
+func (c *chunkCipher) open(index uint32, sealed []byte, final bool) ([]byte, error) {
+	additionalData := chunkData
+	if final {
+		additionalData = finalChunk
+	}
+
+	return c.aead.Open(nil, c.nonce(index), sealed, additionalData)
+}
@@ -87,6 +87,39 @@ func NewForbiddenError(message string, description ...string) RestErr {
 	)
 }
 
+// NewConflictError is the default 409 error.
+func NewConflictError(message string, description ...string) RestErr {
+	status := http.StatusConflict
+
+	return NewRestError(
+		status,
+		message,
+		description,
+	)
+}
+
+// NewGoneError is the default 410 error.
+func NewGoneError(message string, description ...string) RestErr {
+	status := http.StatusGone
+
+	return NewRestError(
+		status,
+		message,
+		description,
+	)
+}
+
+// NewRequestEntityTooLargeError is the default 413 error.
+func NewRequestEntityTooLargeError(message string, description ...string) RestErr {
+	status := http.StatusRequestEntityTooLarge
+
+	return NewRestError(
+		status,
+		message,
+		description,
+	)
+}
+
 // NewInternalServerError is the default 500 error.
 func NewInternalServerError(message string, description ...string) RestErr {
 	status := http.StatusInternalServerError
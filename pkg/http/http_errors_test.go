@@ -62,3 +62,25 @@ func TestNewInternalServerError(t *testing.T) {
 	assert.Equal(t, description, err.GetDescription())
 	assert.Equal(t, http.StatusInternalServerError, err.Status())
 }
+
+func TestNewConflictError(t *testing.T) {
+	msg := "message"
+	description := []string{"description"}
+	err := NewConflictError(msg, description...)
+
+	assert.Implements(t, (*RestErr)(nil), err)
+	assert.Equal(t, fmt.Sprintf("%d %s", err.Status(), msg), err.Error())
+	assert.Equal(t, description, err.GetDescription())
+	assert.Equal(t, http.StatusConflict, err.Status())
+}
+
+func TestNewGoneError(t *testing.T) {
+	msg := "message"
+	description := []string{"description"}
+	err := NewGoneError(msg, description...)
+
+	assert.Implements(t, (*RestErr)(nil), err)
+	assert.Equal(t, fmt.Sprintf("%d %s", err.Status(), msg), err.Error())
+	assert.Equal(t, description, err.GetDescription())
+	assert.Equal(t, http.StatusGone, err.Status())
+}
@@ -0,0 +1,204 @@
+package http_utils
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	// The min interval between the JWKS fetches caused by unknown key ids.
+	minJWKSRefreshInterval = time.Minute
+
+	// The JWKS request timeout.
+	jwksFetchTimeout = 10 * time.Second
+)
+
+// ErrUnknownKey is returned when the token key id isn't in the key set.
+var ErrUnknownKey = errors.New("unknown token key")
+
+// NewHS256KeyFunc returns the key callback of the HS256 tokens, signed with the shared secret.
+func NewHS256KeyFunc(secret []byte) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+
+		return secret, nil
+	}
+}
+
+// JWKS is a JSON Web Key Set with the RSA public keys of the RS256 tokens.
+// The keys fetched from an URL are refreshed after the refresh interval, or when a token has an unknown key id.
+type JWKS struct {
+	mu              sync.RWMutex
+	keys            map[string]*rsa.PublicKey
+	url             string
+	refreshInterval time.Duration
+	fetchedAt       time.Time
+	client          *http.Client
+}
+
+// jsonWebKey is a key of the JWKS document.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// NewJWKSFromFile returns the key set of the local JWKS file.
+func NewJWKSFromFile(path string) (*JWKS, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JWKS{keys: keys}, nil
+}
+
+// NewJWKSFromURL returns the key set fetched from the URL.
+func NewJWKSFromURL(url string, refreshInterval time.Duration) (*JWKS, error) {
+	jwks := &JWKS{
+		url:             url,
+		refreshInterval: refreshInterval,
+		client:          &http.Client{Timeout: jwksFetchTimeout},
+	}
+
+	if err := jwks.refresh(); err != nil {
+		return nil, err
+	}
+
+	return jwks, nil
+}
+
+// KeyFunc returns the public key of the RS256 token, by its key id.
+// Tokens without a key id are accepted when the set has a single key.
+func (k *JWKS) KeyFunc(token *jwt.Token) (interface{}, error) {
+	if token.Method != jwt.SigningMethodRS256 {
+		return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+	}
+
+	kid, _ := token.Header["kid"].(string)
+
+	key, found, stale := k.key(kid)
+	if k.url != "" && (stale || !found) {
+		k.mu.RLock()
+		canRefresh := time.Since(k.fetchedAt) >= minJWKSRefreshInterval
+		k.mu.RUnlock()
+
+		if canRefresh {
+			// The current keys are kept when the refresh fails.
+			if err := k.refresh(); err == nil {
+				key, found, _ = k.key(kid)
+			}
+		}
+	}
+
+	if !found {
+		return nil, ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+// key returns the key with the id, and if the keys should be refreshed.
+func (k *JWKS) key(kid string) (*rsa.PublicKey, bool, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	stale := k.refreshInterval > 0 && time.Since(k.fetchedAt) > k.refreshInterval
+
+	if kid == "" && len(k.keys) == 1 {
+		for _, key := range k.keys {
+			return key, true, stale
+		}
+	}
+
+	key, found := k.keys[kid]
+
+	return key, found, stale
+}
+
+// refresh fetches the keys from the JWKS URL.
+func (k *JWKS) refresh() error {
+	resp, err := k.client.Get(k.url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected JWKS response status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.fetchedAt = time.Now()
+	k.mu.Unlock()
+
+	return nil
+}
+
+// ParseJWKS returns the RSA signing keys of the JWKS document, by key id.
+func ParseJWKS(data []byte) (map[string]*rsa.PublicKey, error) {
+	var document struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, jwk := range document.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q modulus", jwk.Kid)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid key %q exponent", jwk.Kid)
+		}
+
+		keys[jwk.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("the JWKS doesn't have RSA signing keys")
+	}
+
+	return keys, nil
+}
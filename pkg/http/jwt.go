@@ -2,11 +2,21 @@ package http_utils
 
 import (
 	"errors"
-	"html"
 	"net/http"
 	"strings"
 
 	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+)
+
+// The gin context key of the request principal.
+const principalKey = "principal"
+
+var (
+	// ErrMissingToken is returned when the request doesn't have a bearer token.
+	ErrMissingToken = errors.New("missing bearer token")
+	// ErrInvalidToken is returned when the token signature or claims are invalid.
+	ErrInvalidToken = errors.New("invalid token")
 )
 
 // Claims defines the JWT struct
@@ -16,39 +26,85 @@ type Claims struct {
 	jwt.StandardClaims
 }
 
-// ExtractJWTFromRequest gets the JWT from the request
-func ExtractJWTFromRequest(r *http.Request) (map[string]interface{}, error) {
-	// Get the JWT string
+// Principal is the authenticated caller of the request.
+type Principal struct {
+	// Subject is the user identifier, from the sub claim.
+	Subject string
+	// Admin is set when the caller can access the files of any user.
+	Admin bool
+	// ApiKeyId is the managed API key of the request. The API key principals are limited to their Scopes.
+	ApiKeyId string
+	Scopes   []string
+	Claims   jwt.MapClaims
+}
+
+// ExtractJWTFromRequest gets the JWT claims from the request, verified with the key returned by keyFunc.
+func ExtractJWTFromRequest(r *http.Request, keyFunc jwt.Keyfunc) (jwt.MapClaims, error) {
 	tokenString := ExtractBearerToken(r)
+	if tokenString == "" {
+		return nil, ErrMissingToken
+	}
 
-	// Initialize a new instance of `Claims` (here using Claims map)
+	// The token expiration and not before claims are checked while it's parsed.
 	claims := jwt.MapClaims{}
-
-	// Parse the JWT string and repositories the result in `claims`.
-	// Note that we are passing the key in this method as well. This method will return an error
-	// if the token is invalid (if it has expired according to the expiry time we set on sign in),
-	// or if the signature does not match
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (jwtKey interface{}, err error) {
-		return jwtKey, err
-	})
-
+	token, err := jwt.ParseWithClaims(tokenString, claims, keyFunc)
 	if err != nil {
-		if errors.Is(err, jwt.ErrSignatureInvalid) {
-			return nil, errors.New("invalid token signature")
+		var validationErr *jwt.ValidationError
+		if errors.As(err, &validationErr) && validationErr.Inner != nil {
+			return nil, validationErr.Inner
 		}
 		return nil, err
 	}
 
 	if !token.Valid {
-		return nil, errors.New("invalid token ")
+		return nil, ErrInvalidToken
 	}
 
 	return claims, nil
 }
 
-// ExtractBearerToken extracts bearer token from request Authorization header
+// ExtractBearerToken extracts bearer token from request Authorization header.
+// It returns an empty token when the header doesn't use the bearer scheme.
 func ExtractBearerToken(r *http.Request) string {
-	headerAuthorization := r.Header.Get("Authorization")
-	bearerToken := strings.Split(headerAuthorization, " ")
-	return html.EscapeString(bearerToken[1])
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+
+	return strings.TrimSpace(token)
+}
+
+// ClaimScopes returns the token scopes, from the space separated scope claim or the scp list.
+func ClaimScopes(claims jwt.MapClaims) []string {
+	if scope, ok := claims["scope"].(string); ok {
+		return strings.Fields(scope)
+	}
+
+	var scopes []string
+	if scp, ok := claims["scp"].([]interface{}); ok {
+		for _, value := range scp {
+			if scope, ok := value.(string); ok {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	return scopes
+}
+
+// SetPrincipal sets the request principal.
+func SetPrincipal(ctx *gin.Context, principal *Principal) {
+	ctx.Set(principalKey, principal)
+}
+
+// GetPrincipal returns the request principal. It's false when the request isn't authenticated.
+func GetPrincipal(ctx *gin.Context) (*Principal, bool) {
+	value, ok := ctx.Get(principalKey)
+	if !ok {
+		return nil, false
+	}
+
+	principal, ok := value.(*Principal)
+
+	return principal, ok
 }
@@ -0,0 +1,124 @@
+package http_utils
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTokenRequest returns a request with the Authorization header.
+func newTokenRequest(authorization string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", authorization)
+
+	return req
+}
+
+// newJWKSDocument returns the JWKS document of the public key.
+func newJWKSDocument(kid string, key *rsa.PublicKey) string {
+	return fmt.Sprintf(`{"keys":[{"kty":"RSA","use":"sig","kid":%q,"n":%q,"e":%q}]}`,
+		kid,
+		base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	)
+}
+
+func TestExtractJWTFromRequestHS256(t *testing.T) {
+	keyFunc := NewHS256KeyFunc([]byte("secret"))
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   "user",
+		"scope": "read admin",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+	assert.Nil(t, err)
+
+	claims, err := ExtractJWTFromRequest(newTokenRequest("Bearer "+token), keyFunc)
+	assert.Nil(t, err)
+	assert.Equal(t, "user", claims["sub"])
+	assert.Equal(t, []string{"read", "admin"}, ClaimScopes(claims))
+
+	_, err = ExtractJWTFromRequest(newTokenRequest("bearer "+token), keyFunc)
+	assert.Nil(t, err)
+
+	_, err = ExtractJWTFromRequest(newTokenRequest("Bearer"+token), keyFunc)
+	assert.Equal(t, ErrMissingToken, err)
+
+	_, err = ExtractJWTFromRequest(newTokenRequest("ApiKey key"), keyFunc)
+	assert.Equal(t, ErrMissingToken, err)
+
+	_, err = ExtractJWTFromRequest(newTokenRequest("Bearer "+token), NewHS256KeyFunc([]byte("other")))
+	assert.NotNil(t, err)
+
+	expired, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "user",
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+	assert.Nil(t, err)
+
+	_, err = ExtractJWTFromRequest(newTokenRequest("Bearer "+expired), keyFunc)
+	assert.NotNil(t, err)
+}
+
+func TestExtractJWTFromRequestRS256(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.Nil(t, os.WriteFile(path, []byte(newJWKSDocument("key-1", &privateKey.PublicKey)), 0o600))
+
+	fileJWKS, err := NewJWKSFromFile(path)
+	assert.Nil(t, err)
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(newJWKSDocument("key-1", &privateKey.PublicKey)))
+	}))
+	defer server.Close()
+
+	urlJWKS, err := NewJWKSFromURL(server.URL, time.Hour)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, requests)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"sub": "user",
+		"scp": []string{"read"},
+	})
+	token.Header["kid"] = "key-1"
+	signed, err := token.SignedString(privateKey)
+	assert.Nil(t, err)
+
+	for _, jwks := range []*JWKS{fileJWKS, urlJWKS} {
+		claims, err := ExtractJWTFromRequest(newTokenRequest("Bearer "+signed), jwks.KeyFunc)
+		assert.Nil(t, err)
+		assert.Equal(t, "user", claims["sub"])
+		assert.Equal(t, []string{"read"}, ClaimScopes(claims))
+	}
+
+	token.Header["kid"] = "key-2"
+	signed, err = token.SignedString(privateKey)
+	assert.Nil(t, err)
+
+	// The refresh after an unknown key id is rate limited.
+	_, err = ExtractJWTFromRequest(newTokenRequest("Bearer "+signed), urlJWKS.KeyFunc)
+	assert.Equal(t, ErrUnknownKey, err)
+	assert.Equal(t, 1, requests)
+
+	hs256, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user"}).SignedString([]byte("secret"))
+	assert.Nil(t, err)
+
+	_, err = ExtractJWTFromRequest(newTokenRequest("Bearer "+hs256), fileJWKS.KeyFunc)
+	assert.NotNil(t, err)
+}
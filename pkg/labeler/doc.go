@@ -0,0 +1,2 @@
+// Package labeler contains the file labelling interface and its local implementations.
+package labeler
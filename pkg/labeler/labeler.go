@@ -0,0 +1,80 @@
+package labeler
+
+import (
+	"io"
+)
+
+// Backend defines the available labeler implementations.
+type Backend string
+
+const (
+	// Rekognition uses the AWS Rekognition API.
+	Rekognition Backend = "rekognition"
+	// Local uses the bundled heuristic labeler. It doesn't need any external service.
+	Local Backend = "local"
+)
+
+// Labeler defines the file labelling methods.
+// The prefix is the storage object key of the file to be labelled.
+type Labeler interface {
+	DetectLabels(prefix string) ([]Label, error)
+	DetectModerationLabels(prefix string) ([]ModerationLabel, error)
+	DetectText(prefix string) ([]TextDetection, error)
+}
+
+// DownloadFunc returns the object content from the given prefix.
+type DownloadFunc func(prefix string) (io.ReadCloser, error)
+
+// Label is a detected image label.
+type Label struct {
+	Name       string  `dynamodbav:"name" json:"name"`
+	Confidence float32 `dynamodbav:"confidence" json:"confidence"`
+}
+
+// ModerationLabel is a detected moderation label.
+type ModerationLabel struct {
+	Name       string  `dynamodbav:"name" json:"name"`
+	ParentName string  `dynamodbav:"parentName" json:"parentName"`
+	Confidence float32 `dynamodbav:"confidence" json:"confidence"`
+}
+
+// BoundingBox is the position of a detection, in ratios of the image size.
+type BoundingBox struct {
+	Left   float32 `dynamodbav:"left" json:"left"`
+	Top    float32 `dynamodbav:"top" json:"top"`
+	Width  float32 `dynamodbav:"width" json:"width"`
+	Height float32 `dynamodbav:"height" json:"height"`
+}
+
+// TextDetection is a detected text line.
+type TextDetection struct {
+	Text        string      `dynamodbav:"text" json:"text"`
+	Confidence  float32     `dynamodbav:"confidence" json:"confidence"`
+	BoundingBox BoundingBox `dynamodbav:"boundingBox" json:"boundingBox"`
+}
+
+// FileLabels contains the label and moderation detections of a file.
+// The text detections are stored apart, as they can be large.
+type FileLabels struct {
+	Labels     []Label           `dynamodbav:"labels" json:"labels"`
+	Moderation []ModerationLabel `dynamodbav:"moderation" json:"moderation"`
+}
+
+// DetectAll runs the labels and moderation detections in the given prefix.
+// Both detections are required, so the files aren't stored without moderation.
+func DetectAll(l Labeler, prefix string) (*FileLabels, error) {
+	labels, err := l.DetectLabels(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	moderation, err := l.DetectModerationLabels(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileLabels{
+		Labels:     labels,
+		Moderation: moderation,
+	}, nil
+}
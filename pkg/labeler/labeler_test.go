@@ -0,0 +1,106 @@
+package labeler
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newPNGDownload(t *testing.T, width, height int, c color.Color) DownloadFunc {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, png.Encode(buf, img))
+
+	return func(prefix string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	}
+}
+
+func labelNames(labels []Label) []string {
+	var names []string
+	for _, label := range labels {
+		names = append(names, label.Name)
+	}
+	return names
+}
+
+func TestLocalLabeler(t *testing.T) {
+	l := NewLocalLabeler(newPNGDownload(t, 200, 100, color.RGBA{R: 220, A: 255}), 90, 10)
+
+	labels, err := l.DetectLabels("user/prefix/high-def.png")
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"Landscape", "Red"}, labelNames(labels))
+
+	l = NewLocalLabeler(newPNGDownload(t, 100, 200, color.Gray{Y: 10}), 90, 10)
+
+	labels, err = l.DetectLabels("user/prefix/high-def.png")
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"Portrait", "Dark", "Monochrome"}, labelNames(labels))
+
+	l = NewLocalLabeler(newPNGDownload(t, 100, 100, color.White), 0, 1)
+
+	labels, err = l.DetectLabels("user/prefix/high-def.png")
+	assert.Nil(t, err)
+	assert.Len(t, labels, 1)
+}
+
+func TestStaticLabeler(t *testing.T) {
+	l := NewStaticLabeler(StaticRule{
+		Pattern: "user/*/high-def.png",
+		Labels: FileLabels{
+			Labels:     []Label{{Name: "Cat", Confidence: 99}},
+			Moderation: []ModerationLabel{{Name: "Violence", Confidence: 98}},
+		},
+		Text: []TextDetection{{Text: "Invoice 42", Confidence: 99}},
+	})
+
+	result, err := DetectAll(l, "user/prefix/high-def.png")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Cat"}, labelNames(result.Labels))
+	assert.Len(t, result.Moderation, 1)
+
+	text, err := l.DetectText("user/prefix/high-def.png")
+	assert.Nil(t, err)
+	assert.Len(t, text, 1)
+
+	result, err = DetectAll(l, "other/prefix/high-def.png")
+	assert.Nil(t, err)
+	assert.Empty(t, result.Labels)
+}
+
+func TestPrefixedLabeler(t *testing.T) {
+	l := NewPrefixedLabeler(NewStaticLabeler(StaticRule{
+		Pattern: "acme/user/*/high-def.png",
+		Labels:  FileLabels{Labels: []Label{{Name: "Cat", Confidence: 99}}},
+	}), "acme")
+
+	result, err := DetectAll(l, "user/prefix/high-def.png")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Cat"}, labelNames(result.Labels))
+}
+
+// moderationErrorLabeler fails the moderation detection.
+type moderationErrorLabeler struct {
+	*StaticLabeler
+}
+
+func (l moderationErrorLabeler) DetectModerationLabels(prefix string) ([]ModerationLabel, error) {
+	return nil, errors.New("moderation unavailable")
+}
+
+func TestDetectAllModerationError(t *testing.T) {
+	_, err := DetectAll(moderationErrorLabeler{NewStaticLabeler()}, "user/prefix/high-def.png")
+	assert.NotNil(t, err)
+}
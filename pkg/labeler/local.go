@@ -0,0 +1,213 @@
+package labeler
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// The max number of sampled pixels per image axis.
+	maxSamplesPerAxis = 64
+
+	// The saturation under which a pixel is considered gray.
+	graySaturation = 0.15
+)
+
+// hueNames maps the upper hue boundary (in degrees) to the color name.
+var hueNames = []struct {
+	limit float64
+	name  string
+}{
+	{15, "Red"},
+	{45, "Orange"},
+	{70, "Yellow"},
+	{165, "Green"},
+	{195, "Cyan"},
+	{255, "Blue"},
+	{290, "Purple"},
+	{335, "Pink"},
+	{360, "Red"},
+}
+
+// LocalLabeler is a heuristic labeler that runs without any external service.
+// It detects the image orientation, brightness and dominant colors.
+// Moderation and text detections are not supported and always return empty.
+type LocalLabeler struct {
+	download      DownloadFunc
+	minConfidence float32
+	maxLabels     int32
+}
+
+// NewLocalLabeler returns a LocalLabeler instance.
+func NewLocalLabeler(download DownloadFunc, minConfidence float32, maxLabels int32) *LocalLabeler {
+	return &LocalLabeler{
+		download:      download,
+		minConfidence: minConfidence,
+		maxLabels:     maxLabels,
+	}
+}
+
+// DetectLabels returns the image heuristic labels.
+func (l *LocalLabeler) DetectLabels(prefix string) ([]Label, error) {
+	reader, err := l.download(prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	img, _, err := image.Decode(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := append(orientationLabels(img.Bounds()), colorLabels(img)...)
+
+	sort.SliceStable(labels, func(i, j int) bool {
+		return labels[i].Confidence > labels[j].Confidence
+	})
+
+	var filtered []Label
+	for _, label := range labels {
+		if label.Confidence < l.minConfidence {
+			continue
+		}
+		if l.maxLabels > 0 && int32(len(filtered)) >= l.maxLabels {
+			break
+		}
+		filtered = append(filtered, label)
+	}
+
+	return filtered, nil
+}
+
+// DetectModerationLabels is not supported by the local labeler.
+func (l *LocalLabeler) DetectModerationLabels(prefix string) ([]ModerationLabel, error) {
+	return nil, nil
+}
+
+// DetectText is not supported by the local labeler.
+func (l *LocalLabeler) DetectText(prefix string) ([]TextDetection, error) {
+	return nil, nil
+}
+
+// orientationLabels returns the image orientation label.
+func orientationLabels(bounds image.Rectangle) []Label {
+	if bounds.Dy() == 0 {
+		return nil
+	}
+
+	ratio := float64(bounds.Dx()) / float64(bounds.Dy())
+
+	name := "Square"
+	switch {
+	case ratio > 1.1:
+		name = "Landscape"
+	case ratio < 0.9:
+		name = "Portrait"
+	}
+
+	return []Label{{Name: name, Confidence: 99}}
+}
+
+// colorLabels samples the image pixels and returns the brightness and dominant color labels.
+func colorLabels(img image.Image) []Label {
+	bounds := img.Bounds()
+	stepX := max(bounds.Dx()/maxSamplesPerAxis, 1)
+	stepY := max(bounds.Dy()/maxSamplesPerAxis, 1)
+
+	var total, grays, luminance float64
+	hues := map[string]float64{}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			hue, saturation, value := toHSV(img.At(x, y))
+
+			total++
+			luminance += value
+			if saturation < graySaturation {
+				grays++
+				continue
+			}
+			hues[hueName(hue)]++
+		}
+	}
+
+	if total == 0 {
+		return nil
+	}
+
+	var labels []Label
+
+	avgLuminance := luminance / total
+	switch {
+	case avgLuminance < 0.25:
+		labels = append(labels, Label{Name: "Dark", Confidence: confidence(1 - avgLuminance)})
+	case avgLuminance > 0.75:
+		labels = append(labels, Label{Name: "Bright", Confidence: confidence(avgLuminance)})
+	}
+
+	if grays/total > 0.9 {
+		labels = append(labels, Label{Name: "Monochrome", Confidence: confidence(grays / total)})
+	}
+
+	for name, count := range hues {
+		labels = append(labels, Label{Name: name, Confidence: confidence(count / total)})
+	}
+
+	return labels
+}
+
+// toHSV converts the color to hue (degrees), saturation and value (0 to 1).
+func toHSV(c color.Color) (float64, float64, float64) {
+	r16, g16, b16, _ := c.RGBA()
+	r, g, b := float64(r16)/0xffff, float64(g16)/0xffff, float64(b16)/0xffff
+
+	maxC := math.Max(r, math.Max(g, b))
+	minC := math.Min(r, math.Min(g, b))
+	delta := maxC - minC
+
+	if maxC == 0 || delta == 0 {
+		return 0, 0, maxC
+	}
+
+	var hue float64
+	switch maxC {
+	case r:
+		hue = math.Mod((g-b)/delta, 6)
+	case g:
+		hue = (b-r)/delta + 2
+	default:
+		hue = (r-g)/delta + 4
+	}
+
+	hue *= 60
+	if hue < 0 {
+		hue += 360
+	}
+
+	return hue, delta / maxC, maxC
+}
+
+// hueName returns the color name of the hue.
+func hueName(hue float64) string {
+	for _, h := range hueNames {
+		if hue < h.limit {
+			return h.name
+		}
+	}
+
+	return "Red"
+}
+
+// confidence converts a ratio to a confidence percentage.
+func confidence(ratio float64) float32 {
+	return float32(math.Round(ratio*10000) / 100)
+}
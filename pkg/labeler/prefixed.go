@@ -0,0 +1,34 @@
+package labeler
+
+// PrefixedLabeler labels the objects of a prefixed blob store, adding the key prefix to the prefixes.
+type PrefixedLabeler struct {
+	labeler   Labeler
+	keyPrefix string
+}
+
+// NewPrefixedLabeler returns a PrefixedLabeler instance.
+func NewPrefixedLabeler(labeler Labeler, keyPrefix string) *PrefixedLabeler {
+	return &PrefixedLabeler{
+		labeler:   labeler,
+		keyPrefix: keyPrefix,
+	}
+}
+
+// DetectLabels detects the labels of the prefixed object.
+func (l *PrefixedLabeler) DetectLabels(prefix string) ([]Label, error) {
+	return l.labeler.DetectLabels(l.key(prefix))
+}
+
+// DetectModerationLabels detects the moderation labels of the prefixed object.
+func (l *PrefixedLabeler) DetectModerationLabels(prefix string) ([]ModerationLabel, error) {
+	return l.labeler.DetectModerationLabels(l.key(prefix))
+}
+
+// DetectText detects the text of the prefixed object.
+func (l *PrefixedLabeler) DetectText(prefix string) ([]TextDetection, error) {
+	return l.labeler.DetectText(l.key(prefix))
+}
+
+func (l *PrefixedLabeler) key(prefix string) string {
+	return l.keyPrefix + "/" + prefix
+}
@@ -0,0 +1,52 @@
+package labeler
+
+import (
+	"path"
+)
+
+// StaticRule defines the labels returned for the prefixes matching the pattern.
+// The pattern uses the path.Match syntax.
+type StaticRule struct {
+	Pattern string
+	Labels  FileLabels
+	Text    []TextDetection
+}
+
+// StaticLabeler is a rule-based labeler, useful for tests.
+// It returns the labels of the first rule matching the prefix.
+type StaticLabeler struct {
+	Rules []StaticRule
+}
+
+// NewStaticLabeler returns a StaticLabeler instance.
+func NewStaticLabeler(rules ...StaticRule) *StaticLabeler {
+	return &StaticLabeler{
+		Rules: rules,
+	}
+}
+
+// DetectLabels returns the matching rule labels.
+func (l *StaticLabeler) DetectLabels(prefix string) ([]Label, error) {
+	return l.match(prefix).Labels.Labels, nil
+}
+
+// DetectModerationLabels returns the matching rule moderation labels.
+func (l *StaticLabeler) DetectModerationLabels(prefix string) ([]ModerationLabel, error) {
+	return l.match(prefix).Labels.Moderation, nil
+}
+
+// DetectText returns the matching rule text detections.
+func (l *StaticLabeler) DetectText(prefix string) ([]TextDetection, error) {
+	return l.match(prefix).Text, nil
+}
+
+// match returns the first matching rule.
+func (l *StaticLabeler) match(prefix string) StaticRule {
+	for _, rule := range l.Rules {
+		if matched, _ := path.Match(rule.Pattern, prefix); matched {
+			return rule
+		}
+	}
+
+	return StaticRule{}
+}
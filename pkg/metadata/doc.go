@@ -0,0 +1,3 @@
+// Package metadata contains the file records storage abstraction, with the PostgreSQL and in-memory implementations.
+// The DynamoDB implementation is in the aws_repository package.
+package metadata
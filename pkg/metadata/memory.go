@@ -0,0 +1,444 @@
+package metadata
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gearpoint/filepoint/internal/views"
+)
+
+// MemoryStore stores the file records in memory.
+// The records are copied in and out, so callers can't change the stored ones.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	files   map[string]map[string]views.DynamoDBUploadSchema
+	index   map[string]map[string]views.DynamoDBLabelIndexSchema
+	folders map[string]map[string]views.DynamoDBFolderSchema
+	apiKeys map[string]views.DynamoDBApiKeySchema
+	shares  map[string]views.DynamoDBShareSchema
+	usage   map[string]map[string]views.DynamoDBUsageSchema
+}
+
+// NewMemoryStore returns an empty MemoryStore instance.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		files:   make(map[string]map[string]views.DynamoDBUploadSchema),
+		index:   make(map[string]map[string]views.DynamoDBLabelIndexSchema),
+		folders: make(map[string]map[string]views.DynamoDBFolderSchema),
+		apiKeys: make(map[string]views.DynamoDBApiKeySchema),
+		shares:  make(map[string]views.DynamoDBShareSchema),
+		usage:   make(map[string]map[string]views.DynamoDBUsageSchema),
+	}
+}
+
+// GetFile returns the file record.
+func (s *MemoryStore) GetFile(userId string, prefix string) (*views.DynamoDBUploadSchema, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	file, ok := s.files[userId][prefix]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &file, nil
+}
+
+// PutFile adds or replaces the file record.
+func (s *MemoryStore) PutFile(file *views.DynamoDBUploadSchema) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.files[file.UserId] == nil {
+		s.files[file.UserId] = make(map[string]views.DynamoDBUploadSchema)
+	}
+	s.files[file.UserId][file.Prefix] = *file
+
+	return nil
+}
+
+// UpdateFile updates the file record.
+func (s *MemoryStore) UpdateFile(file *views.DynamoDBUploadSchema) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.files[file.UserId][file.Prefix]; !ok {
+		return ErrNotFound
+	}
+	s.files[file.UserId][file.Prefix] = *file
+
+	return nil
+}
+
+// UpdateFileIfVersion updates the file record if the stored version matches.
+func (s *MemoryStore) UpdateFileIfVersion(file *views.DynamoDBUploadSchema, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.files[file.UserId][file.Prefix]
+	if !ok || stored.Version != version {
+		return ErrVersionConflict
+	}
+
+	file.Version = version + 1
+	s.files[file.UserId][file.Prefix] = *file
+
+	return nil
+}
+
+// DeleteFile deletes the file record.
+func (s *MemoryStore) DeleteFile(userId string, prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.files[userId], prefix)
+
+	return nil
+}
+
+// DeleteUserFiles deletes all the user files and index items.
+func (s *MemoryStore) DeleteUserFiles(userId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.files, userId)
+	delete(s.index, userId)
+
+	return nil
+}
+
+// ListFiles returns a page of the user files.
+func (s *MemoryStore) ListFiles(userId string, limit int32, cursor string, forward bool) ([]*views.DynamoDBUploadSchema, string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	prefixes, nextCursor, err := page(s.files[userId], "", limit, cursor, forward)
+	if err != nil {
+		return nil, "", err
+	}
+
+	files := []*views.DynamoDBUploadSchema{}
+	for _, prefix := range prefixes {
+		file := s.files[userId][prefix]
+		files = append(files, &file)
+	}
+
+	return files, nextCursor, nil
+}
+
+// ListFilesByStatus returns the files with the status.
+func (s *MemoryStore) ListFilesByStatus(userId string, status views.FileStatus) ([]*views.DynamoDBUploadSchema, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	files := []*views.DynamoDBUploadSchema{}
+	for partition, userFiles := range s.files {
+		if userId != "" && partition != userId {
+			continue
+		}
+
+		for _, file := range userFiles {
+			if file.Status == status {
+				file := file
+				files = append(files, &file)
+			}
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Prefix < files[j].Prefix
+	})
+
+	return files, nil
+}
+
+// ListFolderFiles returns the files in the folder.
+func (s *MemoryStore) ListFolderFiles(userId string, folderId string) ([]*views.DynamoDBUploadSchema, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	files := []*views.DynamoDBUploadSchema{}
+	for _, file := range s.files[userId] {
+		if file.FolderId == folderId {
+			file := file
+			files = append(files, &file)
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Prefix < files[j].Prefix
+	})
+
+	return files, nil
+}
+
+// QueryIndex returns a page of the user index items whose key begins with keyPrefix.
+func (s *MemoryStore) QueryIndex(userId string, keyPrefix string, limit int32, cursor string) ([]*views.DynamoDBLabelIndexSchema, string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys, nextCursor, err := page(s.index[userId], keyPrefix, limit, cursor, true)
+	if err != nil {
+		return nil, "", err
+	}
+
+	items := []*views.DynamoDBLabelIndexSchema{}
+	for _, key := range keys {
+		item := s.index[userId][key]
+		items = append(items, &item)
+	}
+
+	return items, nextCursor, nil
+}
+
+// PutIndex adds the index items.
+func (s *MemoryStore) PutIndex(items []*views.DynamoDBLabelIndexSchema) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, item := range items {
+		if s.index[item.UserId] == nil {
+			s.index[item.UserId] = make(map[string]views.DynamoDBLabelIndexSchema)
+		}
+		s.index[item.UserId][item.LabelKey] = *item
+	}
+
+	return nil
+}
+
+// DeleteIndex deletes the index items.
+func (s *MemoryStore) DeleteIndex(items []*views.DynamoDBLabelIndexSchema) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, item := range items {
+		delete(s.index[item.UserId], item.LabelKey)
+	}
+
+	return nil
+}
+
+// GetFolder returns the folder.
+func (s *MemoryStore) GetFolder(userId string, folderId string) (*views.DynamoDBFolderSchema, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	folder, ok := s.folders[userId][folderId]
+	if !ok {
+		return nil, ErrFolderNotFound
+	}
+
+	return &folder, nil
+}
+
+// PutFolder adds or replaces the folder.
+func (s *MemoryStore) PutFolder(folder *views.DynamoDBFolderSchema) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.folders[folder.UserId] == nil {
+		s.folders[folder.UserId] = make(map[string]views.DynamoDBFolderSchema)
+	}
+	s.folders[folder.UserId][folder.FolderId] = *folder
+
+	return nil
+}
+
+// DeleteFolder deletes the folder.
+func (s *MemoryStore) DeleteFolder(userId string, folderId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.folders[userId], folderId)
+
+	return nil
+}
+
+// ListFolders returns all the user folders.
+func (s *MemoryStore) ListFolders(userId string) ([]*views.DynamoDBFolderSchema, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	folders := []*views.DynamoDBFolderSchema{}
+	for _, folder := range s.folders[userId] {
+		folder := folder
+		folders = append(folders, &folder)
+	}
+
+	SortFolders(folders)
+
+	return folders, nil
+}
+
+// GetApiKey returns the API key.
+func (s *MemoryStore) GetApiKey(keyId string) (*views.DynamoDBApiKeySchema, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	apiKey, ok := s.apiKeys[keyId]
+	if !ok {
+		return nil, ErrApiKeyNotFound
+	}
+
+	return &apiKey, nil
+}
+
+// PutApiKey adds or replaces the API key.
+func (s *MemoryStore) PutApiKey(apiKey *views.DynamoDBApiKeySchema) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.apiKeys[apiKey.KeyId] = *apiKey
+
+	return nil
+}
+
+// ListApiKeys returns the tenant API keys.
+func (s *MemoryStore) ListApiKeys(tenantId string) ([]*views.DynamoDBApiKeySchema, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	apiKeys := []*views.DynamoDBApiKeySchema{}
+	for _, apiKey := range s.apiKeys {
+		if tenantId == "" || apiKey.TenantId == tenantId {
+			apiKey := apiKey
+			apiKeys = append(apiKeys, &apiKey)
+		}
+	}
+
+	SortApiKeys(apiKeys)
+
+	return apiKeys, nil
+}
+
+// SetApiKeyLastUsed updates the key last used time.
+func (s *MemoryStore) SetApiKeyLastUsed(keyId string, lastUsedOn time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	apiKey, ok := s.apiKeys[keyId]
+	if !ok {
+		return ErrApiKeyNotFound
+	}
+	apiKey.LastUsedOn = lastUsedOn
+	s.apiKeys[keyId] = apiKey
+
+	return nil
+}
+
+// GetShare returns the share.
+func (s *MemoryStore) GetShare(tokenHash string) (*views.DynamoDBShareSchema, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	share, ok := s.shares[tokenHash]
+	if !ok {
+		return nil, ErrShareNotFound
+	}
+
+	return &share, nil
+}
+
+// PutShare adds or replaces the share.
+func (s *MemoryStore) PutShare(share *views.DynamoDBShareSchema) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.shares[share.TokenHash] = *share
+
+	return nil
+}
+
+// IncrementShareDownloads counts a share download.
+func (s *MemoryStore) IncrementShareDownloads(tokenHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	share, ok := s.shares[tokenHash]
+	if !ok {
+		return ErrShareNotFound
+	}
+	if share.MaxDownloads > 0 && share.Downloads >= share.MaxDownloads {
+		return ErrShareLimitReached
+	}
+	share.Downloads++
+	s.shares[tokenHash] = share
+
+	return nil
+}
+
+// AddUsage adds the bytes and files to the user strategy counters.
+func (s *MemoryStore) AddUsage(usage *views.DynamoDBUsageSchema) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.usage[usage.UserId] == nil {
+		s.usage[usage.UserId] = make(map[string]views.DynamoDBUsageSchema)
+	}
+
+	counters := s.usage[usage.UserId][usage.Strategy]
+	counters.UserId = usage.UserId
+	counters.Strategy = usage.Strategy
+	counters.Bytes += usage.Bytes
+	counters.Files += usage.Files
+	s.usage[usage.UserId][usage.Strategy] = counters
+
+	return nil
+}
+
+// ListUsage returns the user counters of each strategy.
+func (s *MemoryStore) ListUsage(userId string) ([]*views.DynamoDBUsageSchema, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	usage := []*views.DynamoDBUsageSchema{}
+	for _, counters := range s.usage[userId] {
+		counters := counters
+		usage = append(usage, &counters)
+	}
+
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Strategy < usage[j].Strategy
+	})
+
+	return usage, nil
+}
+
+// page returns a page of the sorted partition keys that begin with keyPrefix, after the cursor.
+// The keys are sorted in descending order when forward is false.
+func page[T any](partition map[string]T, keyPrefix string, limit int32, cursor string, forward bool) ([]string, string, error) {
+	var lastKey string
+	if cursor != "" {
+		var err error
+		lastKey, err = decodeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	var keys []string
+	for key := range partition {
+		if !strings.HasPrefix(key, keyPrefix) {
+			continue
+		}
+		if cursor == "" || (forward && key > lastKey) || (!forward && key < lastKey) {
+			keys = append(keys, key)
+		}
+	}
+
+	if forward {
+		sort.Strings(keys)
+	} else {
+		sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	}
+
+	if limit <= 0 || int(limit) >= len(keys) {
+		return keys, "", nil
+	}
+
+	keys = keys[:limit]
+
+	return keys, encodeCursor(keys[len(keys)-1]), nil
+}
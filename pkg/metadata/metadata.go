@@ -0,0 +1,170 @@
+package metadata
+
+import (
+	"encoding/base64"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/gearpoint/filepoint/internal/views"
+)
+
+// Backend defines the metadata store implementation.
+type Backend string
+
+const (
+	// DynamoDB stores the file records in the route DynamoDB tables.
+	DynamoDB Backend = "dynamodb"
+	// Postgres stores the file records in a PostgreSQL database.
+	Postgres Backend = "postgres"
+	// Memory stores the file records in the process memory. It's meant for tests.
+	Memory Backend = "memory"
+)
+
+var (
+	// ErrNotFound is returned when the file record doesn't exist.
+	ErrNotFound = errors.New("file record not found")
+	// ErrInvalidCursor is returned when the page cursor can't be decoded.
+	ErrInvalidCursor = errors.New("invalid page cursor")
+	// ErrIndexNotConfigured is returned when the store has no labels index.
+	ErrIndexNotConfigured = errors.New("the labels index is not configured")
+	// ErrVersionConflict is returned when the file record was changed since it was read.
+	ErrVersionConflict = errors.New("file record version conflict")
+	// ErrFolderNotFound is returned when the folder record doesn't exist.
+	ErrFolderNotFound = errors.New("folder record not found")
+	// ErrFoldersNotConfigured is returned when the store has no folders table.
+	ErrFoldersNotConfigured = errors.New("the folders table is not configured")
+	// ErrApiKeyNotFound is returned when the API key record doesn't exist.
+	ErrApiKeyNotFound = errors.New("API key record not found")
+	// ErrApiKeysNotConfigured is returned when the store has no API keys table.
+	ErrApiKeysNotConfigured = errors.New("the API keys table is not configured")
+	// ErrShareNotFound is returned when the share record doesn't exist.
+	ErrShareNotFound = errors.New("share record not found")
+	// ErrSharesNotConfigured is returned when the store has no shares table.
+	ErrSharesNotConfigured = errors.New("the shares table is not configured")
+	// ErrShareLimitReached is returned when the share has no downloads left.
+	ErrShareLimitReached = errors.New("the share downloads limit was reached")
+	// ErrUsageNotConfigured is returned when the store has no usage table.
+	ErrUsageNotConfigured = errors.New("the usage table is not configured")
+)
+
+// MetadataStore defines the file records storage methods.
+// The files are partitioned by user and sorted by prefix.
+type MetadataStore interface {
+	GetFile(userId string, prefix string) (*views.DynamoDBUploadSchema, error)
+	PutFile(file *views.DynamoDBUploadSchema) error
+	// UpdateFile updates all the file fields. It returns ErrNotFound if the file doesn't exist.
+	// It overwrites the concurrent changes and the file version, so the changes of a read file use UpdateFileFunc.
+	UpdateFile(file *views.DynamoDBUploadSchema) error
+	// UpdateFileIfVersion updates the file if its stored version is the given one, and increments the file version.
+	// It returns ErrVersionConflict if the version doesn't match or the file doesn't exist.
+	UpdateFileIfVersion(file *views.DynamoDBUploadSchema, version int64) error
+	DeleteFile(userId string, prefix string) error
+	// DeleteUserFiles deletes all the user files and index items.
+	DeleteUserFiles(userId string) error
+	// ListFiles returns a page of the user files and the next page cursor, empty in the last page.
+	// The files are sorted by prefix, in descending order when forward is false.
+	ListFiles(userId string, limit int32, cursor string, forward bool) ([]*views.DynamoDBUploadSchema, string, error)
+	// ListFilesByStatus returns the files with the status. The userId is optional.
+	ListFilesByStatus(userId string, status views.FileStatus) ([]*views.DynamoDBUploadSchema, error)
+	// ListFolderFiles returns the files in the folder, or in the user root when the folderId is empty.
+	ListFolderFiles(userId string, folderId string) ([]*views.DynamoDBUploadSchema, error)
+	// QueryIndex returns a page of the user index items whose key begins with keyPrefix.
+	QueryIndex(userId string, keyPrefix string, limit int32, cursor string) ([]*views.DynamoDBLabelIndexSchema, string, error)
+	PutIndex(items []*views.DynamoDBLabelIndexSchema) error
+	DeleteIndex(items []*views.DynamoDBLabelIndexSchema) error
+	// GetFolder returns the folder. It returns ErrFolderNotFound if the folder doesn't exist.
+	GetFolder(userId string, folderId string) (*views.DynamoDBFolderSchema, error)
+	// PutFolder adds or replaces the folder.
+	PutFolder(folder *views.DynamoDBFolderSchema) error
+	DeleteFolder(userId string, folderId string) error
+	// ListFolders returns all the user folders, sorted by name.
+	ListFolders(userId string) ([]*views.DynamoDBFolderSchema, error)
+	// GetApiKey returns the API key. It returns ErrApiKeyNotFound if the key doesn't exist.
+	GetApiKey(keyId string) (*views.DynamoDBApiKeySchema, error)
+	// PutApiKey adds or replaces the API key.
+	PutApiKey(apiKey *views.DynamoDBApiKeySchema) error
+	// ListApiKeys returns the tenant API keys, sorted by creation. The tenantId is optional.
+	ListApiKeys(tenantId string) ([]*views.DynamoDBApiKeySchema, error)
+	// SetApiKeyLastUsed only updates the key last used time. It returns ErrApiKeyNotFound if the key doesn't exist.
+	SetApiKeyLastUsed(keyId string, lastUsedOn time.Time) error
+	// GetShare returns the share. It returns ErrShareNotFound if the share doesn't exist.
+	GetShare(tokenHash string) (*views.DynamoDBShareSchema, error)
+	// PutShare adds or replaces the share.
+	PutShare(share *views.DynamoDBShareSchema) error
+	// IncrementShareDownloads atomically counts a share download.
+	// It returns ErrShareLimitReached if the share has no downloads left, and ErrShareNotFound if the share doesn't exist.
+	IncrementShareDownloads(tokenHash string) error
+	// AddUsage atomically adds the bytes and files to the user counters of the strategy. They're negative when removed.
+	AddUsage(usage *views.DynamoDBUsageSchema) error
+	// ListUsage returns the user counters of each strategy, sorted by strategy.
+	ListUsage(userId string) ([]*views.DynamoDBUsageSchema, error)
+}
+
+// maxUpdateRetries is the max attempts of UpdateFileFunc when the file is changed concurrently.
+const maxUpdateRetries = 5
+
+// UpdateFileFunc reads the file, changes it with update and stores it if it wasn't changed meanwhile.
+// The conflicts are retried with the stored file, so the concurrent changes and the file version are kept.
+// It returns the updated file, or ErrVersionConflict when the retries are exhausted.
+func UpdateFileFunc(
+	store MetadataStore, userId string, prefix string, update func(file *views.DynamoDBUploadSchema) error,
+) (*views.DynamoDBUploadSchema, error) {
+	for attempt := 0; attempt < maxUpdateRetries; attempt++ {
+		file, err := store.GetFile(userId, prefix)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := update(file); err != nil {
+			return nil, err
+		}
+
+		err = store.UpdateFileIfVersion(file, file.Version)
+		if errors.Is(err, ErrVersionConflict) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return file, nil
+	}
+
+	return nil, ErrVersionConflict
+}
+
+// encodeCursor encodes the last returned sort key as an opaque cursor.
+func encodeCursor(lastKey string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(lastKey))
+}
+
+// decodeCursor decodes the opaque cursor to the last returned sort key.
+func decodeCursor(cursor string) (string, error) {
+	lastKey, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", ErrInvalidCursor
+	}
+
+	return string(lastKey), nil
+}
+
+// SortFolders sorts the folders by name, and by identifier when the names are equal.
+func SortFolders(folders []*views.DynamoDBFolderSchema) {
+	sort.Slice(folders, func(i, j int) bool {
+		if folders[i].Name != folders[j].Name {
+			return folders[i].Name < folders[j].Name
+		}
+		return folders[i].FolderId < folders[j].FolderId
+	})
+}
+
+// SortApiKeys sorts the API keys by creation, and by identifier when created together.
+func SortApiKeys(apiKeys []*views.DynamoDBApiKeySchema) {
+	sort.Slice(apiKeys, func(i, j int) bool {
+		if !apiKeys[i].CreatedOn.Equal(apiKeys[j].CreatedOn) {
+			return apiKeys[i].CreatedOn.Before(apiKeys[j].CreatedOn)
+		}
+		return apiKeys[i].KeyId < apiKeys[j].KeyId
+	})
+}
@@ -0,0 +1,78 @@
+package metadata
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+)
+
+// The migrations are applied in the file names order, once per database.
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrate applies the pending PostgreSQL migrations.
+func Migrate(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return err
+	}
+
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		err := applyMigration(db, name)
+		if err != nil {
+			return fmt.Errorf("migration %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// applyMigration applies the migration in a transaction, if it wasn't applied yet.
+func applyMigration(db *sql.DB, name string) error {
+	content, err := migrations.ReadFile(name)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The lock serializes concurrent migrations (i.e. API and webhooks sender starting together).
+	_, err = tx.Exec("LOCK TABLE schema_migrations IN EXCLUSIVE MODE")
+	if err != nil {
+		return err
+	}
+
+	var applied bool
+	err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)", name).Scan(&applied)
+	if err != nil || applied {
+		return err
+	}
+
+	_, err = tx.Exec(string(content))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("INSERT INTO schema_migrations (version) VALUES ($1)", name)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
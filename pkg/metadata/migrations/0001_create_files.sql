@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS files (
+    user_id     TEXT        NOT NULL,
+    prefix      TEXT        NOT NULL,
+    status      TEXT        NOT NULL DEFAULT '',
+    occurred_on TIMESTAMPTZ NOT NULL,
+    record      JSONB       NOT NULL,
+    PRIMARY KEY (user_id, prefix)
+);
+
+CREATE INDEX IF NOT EXISTS files_status_idx ON files (status);
+
+CREATE TABLE IF NOT EXISTS files_index (
+    user_id   TEXT NOT NULL,
+    label_key TEXT NOT NULL,
+    label     TEXT NOT NULL,
+    prefix    TEXT NOT NULL,
+    PRIMARY KEY (user_id, label_key)
+);
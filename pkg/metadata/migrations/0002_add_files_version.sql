@@ -0,0 +1 @@
+ALTER TABLE files ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 0;
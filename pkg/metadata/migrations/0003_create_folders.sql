@@ -0,0 +1,12 @@
+ALTER TABLE files ADD COLUMN IF NOT EXISTS folder_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS files_folder_idx ON files (user_id, folder_id);
+
+CREATE TABLE IF NOT EXISTS folders (
+    user_id   TEXT  NOT NULL,
+    folder_id TEXT  NOT NULL,
+    parent_id TEXT  NOT NULL DEFAULT '',
+    name      TEXT  NOT NULL,
+    record    JSONB NOT NULL,
+    PRIMARY KEY (user_id, folder_id)
+);
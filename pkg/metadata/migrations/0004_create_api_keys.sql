@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    key_id     TEXT        NOT NULL,
+    tenant_id  TEXT        NOT NULL,
+    created_on TIMESTAMPTZ NOT NULL,
+    record     JSONB       NOT NULL,
+    PRIMARY KEY (key_id)
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_tenant_idx ON api_keys (tenant_id);
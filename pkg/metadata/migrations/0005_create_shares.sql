@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS shares (
+    token_hash    TEXT   NOT NULL,
+    tenant_id     TEXT   NOT NULL,
+    max_downloads BIGINT NOT NULL DEFAULT 0,
+    downloads     BIGINT NOT NULL DEFAULT 0,
+    record        JSONB  NOT NULL,
+    PRIMARY KEY (token_hash)
+);
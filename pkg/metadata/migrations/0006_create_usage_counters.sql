@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS usage_counters (
+    user_id  TEXT   NOT NULL,
+    strategy TEXT   NOT NULL,
+    bytes    BIGINT NOT NULL DEFAULT 0,
+    files    BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, strategy)
+);
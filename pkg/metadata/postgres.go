@@ -0,0 +1,589 @@
+package metadata
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gearpoint/filepoint/internal/views"
+	_ "github.com/lib/pq"
+)
+
+// PostgresStore stores the file records in PostgreSQL.
+// The whole record is kept as JSON, with the key and filter fields in columns.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore connects to the database and applies the pending migrations.
+func NewPostgresStore(dsn string) (*PostgresStore, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Ping()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	err = Migrate(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &PostgresStore{db: db}, nil
+}
+
+// Close closes the database connections.
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
+
+// GetFile returns the file record.
+func (s *PostgresStore) GetFile(userId string, prefix string) (*views.DynamoDBUploadSchema, error) {
+	row := s.db.QueryRow("SELECT record FROM files WHERE user_id = $1 AND prefix = $2", userId, prefix)
+
+	file, err := scanFile(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+
+	return file, err
+}
+
+// PutFile adds or replaces the file record.
+func (s *PostgresStore) PutFile(file *views.DynamoDBUploadSchema) error {
+	record, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`INSERT INTO files (user_id, prefix, status, occurred_on, version, folder_id, record)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, prefix) DO UPDATE
+		SET status = EXCLUDED.status, occurred_on = EXCLUDED.occurred_on, version = EXCLUDED.version,
+			folder_id = EXCLUDED.folder_id, record = EXCLUDED.record`,
+		file.UserId, file.Prefix, file.Status, file.OccurredOn, file.Version, file.FolderId, string(record),
+	)
+
+	return err
+}
+
+// UpdateFile updates the file record.
+func (s *PostgresStore) UpdateFile(file *views.DynamoDBUploadSchema) error {
+	record, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.db.Exec(`UPDATE files SET status = $3, occurred_on = $4, version = $5, folder_id = $6, record = $7
+		WHERE user_id = $1 AND prefix = $2`,
+		file.UserId, file.Prefix, file.Status, file.OccurredOn, file.Version, file.FolderId, string(record),
+	)
+	if err != nil {
+		return err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// UpdateFileIfVersion updates the file record if the stored version matches.
+func (s *PostgresStore) UpdateFileIfVersion(file *views.DynamoDBUploadSchema, version int64) error {
+	updated := *file
+	updated.Version = version + 1
+
+	record, err := json.Marshal(updated)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.db.Exec(`UPDATE files SET status = $3, occurred_on = $4, version = $5, folder_id = $6, record = $7
+		WHERE user_id = $1 AND prefix = $2 AND version = $8`,
+		updated.UserId, updated.Prefix, updated.Status, updated.OccurredOn, updated.Version, updated.FolderId, string(record), version,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrVersionConflict
+	}
+
+	file.Version = updated.Version
+
+	return nil
+}
+
+// DeleteFile deletes the file record.
+func (s *PostgresStore) DeleteFile(userId string, prefix string) error {
+	_, err := s.db.Exec("DELETE FROM files WHERE user_id = $1 AND prefix = $2", userId, prefix)
+
+	return err
+}
+
+// DeleteUserFiles deletes all the user files and index items.
+func (s *PostgresStore) DeleteUserFiles(userId string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM files WHERE user_id = $1", userId)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM files_index WHERE user_id = $1", userId)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListFiles returns a page of the user files.
+func (s *PostgresStore) ListFiles(userId string, limit int32, cursor string, forward bool) ([]*views.DynamoDBUploadSchema, string, error) {
+	lastPrefix, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := `SELECT record FROM files
+		WHERE user_id = $1 AND prefix > $2
+		ORDER BY prefix LIMIT $3`
+	if !forward {
+		query = `SELECT record FROM files
+		WHERE user_id = $1 AND ($2 = '' OR prefix < $2)
+		ORDER BY prefix DESC LIMIT $3`
+	}
+
+	rows, err := s.db.Query(query, userId, lastPrefix, sqlLimit(limit))
+	if err != nil {
+		return nil, "", err
+	}
+
+	files, err := scanFiles(rows)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if limit <= 0 || int32(len(files)) <= limit {
+		return files, "", nil
+	}
+
+	files = files[:limit]
+
+	return files, encodeCursor(files[len(files)-1].Prefix), nil
+}
+
+// ListFilesByStatus returns the files with the status.
+func (s *PostgresStore) ListFilesByStatus(userId string, status views.FileStatus) ([]*views.DynamoDBUploadSchema, error) {
+	rows, err := s.db.Query(`SELECT record FROM files
+		WHERE status = $1 AND ($2 = '' OR user_id = $2)
+		ORDER BY prefix`,
+		status, userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanFiles(rows)
+}
+
+// ListFolderFiles returns the files in the folder.
+func (s *PostgresStore) ListFolderFiles(userId string, folderId string) ([]*views.DynamoDBUploadSchema, error) {
+	rows, err := s.db.Query(`SELECT record FROM files
+		WHERE user_id = $1 AND folder_id = $2
+		ORDER BY prefix`,
+		userId, folderId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanFiles(rows)
+}
+
+// QueryIndex returns a page of the user index items whose key begins with keyPrefix.
+func (s *PostgresStore) QueryIndex(userId string, keyPrefix string, limit int32, cursor string) ([]*views.DynamoDBLabelIndexSchema, string, error) {
+	lastKey, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := s.db.Query(`SELECT user_id, label_key, label, prefix FROM files_index
+		WHERE user_id = $1 AND starts_with(label_key, $2) AND label_key > $3
+		ORDER BY label_key LIMIT $4`,
+		userId, keyPrefix, lastKey, sqlLimit(limit),
+	)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	items := []*views.DynamoDBLabelIndexSchema{}
+	for rows.Next() {
+		item := &views.DynamoDBLabelIndexSchema{}
+		err := rows.Scan(&item.UserId, &item.LabelKey, &item.Label, &item.Prefix)
+		if err != nil {
+			return nil, "", err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	if limit <= 0 || int32(len(items)) <= limit {
+		return items, "", nil
+	}
+
+	items = items[:limit]
+
+	return items, encodeCursor(items[len(items)-1].LabelKey), nil
+}
+
+// PutIndex adds the index items.
+func (s *PostgresStore) PutIndex(items []*views.DynamoDBLabelIndexSchema) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		for _, item := range items {
+			_, err := tx.Exec(`INSERT INTO files_index (user_id, label_key, label, prefix)
+				VALUES ($1, $2, $3, $4)
+				ON CONFLICT (user_id, label_key) DO UPDATE
+				SET label = EXCLUDED.label, prefix = EXCLUDED.prefix`,
+				item.UserId, item.LabelKey, item.Label, item.Prefix,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteIndex deletes the index items.
+func (s *PostgresStore) DeleteIndex(items []*views.DynamoDBLabelIndexSchema) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		for _, item := range items {
+			_, err := tx.Exec("DELETE FROM files_index WHERE user_id = $1 AND label_key = $2", item.UserId, item.LabelKey)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetFolder returns the folder.
+func (s *PostgresStore) GetFolder(userId string, folderId string) (*views.DynamoDBFolderSchema, error) {
+	row := s.db.QueryRow("SELECT record FROM folders WHERE user_id = $1 AND folder_id = $2", userId, folderId)
+
+	var record []byte
+	err := row.Scan(&record)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrFolderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	folder := &views.DynamoDBFolderSchema{}
+	err = json.Unmarshal(record, folder)
+
+	return folder, err
+}
+
+// PutFolder adds or replaces the folder.
+func (s *PostgresStore) PutFolder(folder *views.DynamoDBFolderSchema) error {
+	record, err := json.Marshal(folder)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`INSERT INTO folders (user_id, folder_id, parent_id, name, record)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, folder_id) DO UPDATE
+		SET parent_id = EXCLUDED.parent_id, name = EXCLUDED.name, record = EXCLUDED.record`,
+		folder.UserId, folder.FolderId, folder.ParentId, folder.Name, string(record),
+	)
+
+	return err
+}
+
+// DeleteFolder deletes the folder.
+func (s *PostgresStore) DeleteFolder(userId string, folderId string) error {
+	_, err := s.db.Exec("DELETE FROM folders WHERE user_id = $1 AND folder_id = $2", userId, folderId)
+
+	return err
+}
+
+// ListFolders returns all the user folders.
+func (s *PostgresStore) ListFolders(userId string) ([]*views.DynamoDBFolderSchema, error) {
+	rows, err := s.db.Query("SELECT record FROM folders WHERE user_id = $1 ORDER BY name, folder_id", userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	folders := []*views.DynamoDBFolderSchema{}
+	for rows.Next() {
+		var record []byte
+		if err := rows.Scan(&record); err != nil {
+			return nil, err
+		}
+
+		folder := &views.DynamoDBFolderSchema{}
+		if err := json.Unmarshal(record, folder); err != nil {
+			return nil, err
+		}
+		folders = append(folders, folder)
+	}
+
+	return folders, rows.Err()
+}
+
+// GetApiKey returns the API key.
+func (s *PostgresStore) GetApiKey(keyId string) (*views.DynamoDBApiKeySchema, error) {
+	var record []byte
+	err := s.db.QueryRow("SELECT record FROM api_keys WHERE key_id = $1", keyId).Scan(&record)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrApiKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := &views.DynamoDBApiKeySchema{}
+	err = json.Unmarshal(record, apiKey)
+
+	return apiKey, err
+}
+
+// PutApiKey adds or replaces the API key.
+func (s *PostgresStore) PutApiKey(apiKey *views.DynamoDBApiKeySchema) error {
+	record, err := json.Marshal(apiKey)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`INSERT INTO api_keys (key_id, tenant_id, created_on, record)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (key_id) DO UPDATE
+		SET tenant_id = EXCLUDED.tenant_id, created_on = EXCLUDED.created_on, record = EXCLUDED.record`,
+		apiKey.KeyId, apiKey.TenantId, apiKey.CreatedOn, string(record),
+	)
+
+	return err
+}
+
+// ListApiKeys returns the tenant API keys.
+func (s *PostgresStore) ListApiKeys(tenantId string) ([]*views.DynamoDBApiKeySchema, error) {
+	rows, err := s.db.Query(`SELECT record FROM api_keys WHERE $1 = '' OR tenant_id = $1
+		ORDER BY created_on, key_id`, tenantId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	apiKeys := []*views.DynamoDBApiKeySchema{}
+	for rows.Next() {
+		var record []byte
+		if err := rows.Scan(&record); err != nil {
+			return nil, err
+		}
+
+		apiKey := &views.DynamoDBApiKeySchema{}
+		if err := json.Unmarshal(record, apiKey); err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	return apiKeys, rows.Err()
+}
+
+// SetApiKeyLastUsed updates the key last used time.
+func (s *PostgresStore) SetApiKeyLastUsed(keyId string, lastUsedOn time.Time) error {
+	result, err := s.db.Exec(`UPDATE api_keys SET record = jsonb_set(record, '{LastUsedOn}', to_jsonb($2::timestamptz))
+		WHERE key_id = $1`, keyId, lastUsedOn)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrApiKeyNotFound
+	}
+
+	return nil
+}
+
+// GetShare returns the share. The downloads are kept in their own column.
+func (s *PostgresStore) GetShare(tokenHash string) (*views.DynamoDBShareSchema, error) {
+	var record []byte
+	var downloads int64
+	err := s.db.QueryRow("SELECT record, downloads FROM shares WHERE token_hash = $1", tokenHash).Scan(&record, &downloads)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrShareNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	share := &views.DynamoDBShareSchema{}
+	if err := json.Unmarshal(record, share); err != nil {
+		return nil, err
+	}
+	share.Downloads = downloads
+
+	return share, nil
+}
+
+// PutShare adds or replaces the share.
+func (s *PostgresStore) PutShare(share *views.DynamoDBShareSchema) error {
+	record, err := json.Marshal(share)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`INSERT INTO shares (token_hash, tenant_id, max_downloads, downloads, record)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (token_hash) DO UPDATE
+		SET tenant_id = EXCLUDED.tenant_id, max_downloads = EXCLUDED.max_downloads,
+			downloads = EXCLUDED.downloads, record = EXCLUDED.record`,
+		share.TokenHash, share.TenantId, share.MaxDownloads, share.Downloads, string(record),
+	)
+
+	return err
+}
+
+// IncrementShareDownloads counts a share download, if the share has downloads left.
+func (s *PostgresStore) IncrementShareDownloads(tokenHash string) error {
+	result, err := s.db.Exec(`UPDATE shares SET downloads = downloads + 1
+		WHERE token_hash = $1 AND (max_downloads = 0 OR downloads < max_downloads)`, tokenHash)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		if _, err := s.GetShare(tokenHash); err != nil {
+			return err
+		}
+		return ErrShareLimitReached
+	}
+
+	return nil
+}
+
+// AddUsage adds the bytes and files to the user strategy counters.
+func (s *PostgresStore) AddUsage(usage *views.DynamoDBUsageSchema) error {
+	_, err := s.db.Exec(`INSERT INTO usage_counters (user_id, strategy, bytes, files)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, strategy) DO UPDATE
+		SET bytes = usage_counters.bytes + EXCLUDED.bytes, files = usage_counters.files + EXCLUDED.files`,
+		usage.UserId, usage.Strategy, usage.Bytes, usage.Files,
+	)
+
+	return err
+}
+
+// ListUsage returns the user counters of each strategy.
+func (s *PostgresStore) ListUsage(userId string) ([]*views.DynamoDBUsageSchema, error) {
+	rows, err := s.db.Query(`SELECT strategy, bytes, files FROM usage_counters WHERE user_id = $1
+		ORDER BY strategy`, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := []*views.DynamoDBUsageSchema{}
+	for rows.Next() {
+		counters := &views.DynamoDBUsageSchema{UserId: userId}
+		if err := rows.Scan(&counters.Strategy, &counters.Bytes, &counters.Files); err != nil {
+			return nil, err
+		}
+		usage = append(usage, counters)
+	}
+
+	return usage, rows.Err()
+}
+
+// inTx runs the function in a transaction, committed if it doesn't fail.
+func (s *PostgresStore) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// sqlLimit returns the query limit, with one more row to detect the next page.
+// Non positive limits return all the rows.
+func sqlLimit(limit int32) any {
+	if limit <= 0 {
+		return nil
+	}
+
+	return limit + 1
+}
+
+// scanFile returns the file record from the row.
+func scanFile(row interface{ Scan(dest ...any) error }) (*views.DynamoDBUploadSchema, error) {
+	var record []byte
+	err := row.Scan(&record)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &views.DynamoDBUploadSchema{}
+	err = json.Unmarshal(record, file)
+
+	return file, err
+}
+
+// scanFiles returns the file records from the rows, closing them.
+func scanFiles(rows *sql.Rows) ([]*views.DynamoDBUploadSchema, error) {
+	defer rows.Close()
+
+	files := []*views.DynamoDBUploadSchema{}
+	for rows.Next() {
+		file, err := scanFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	return files, rows.Err()
+}
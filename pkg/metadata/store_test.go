@@ -0,0 +1,319 @@
+package metadata
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gearpoint/filepoint/internal/views"
+	"github.com/gearpoint/filepoint/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// testMetadataStore runs the MetadataStore behavior tests against the store.
+func testMetadataStore(t *testing.T, store MetadataStore) {
+	userId := uuid.NewString()
+	prefixes := []string{userId + "/a", userId + "/b", userId + "/c"}
+
+	for _, prefix := range prefixes {
+		err := store.PutFile(&views.DynamoDBUploadSchema{
+			UserId:     userId,
+			Prefix:     prefix,
+			Title:      "File",
+			Status:     views.StatusActive,
+			OccurredOn: time.Now().UTC().Truncate(time.Second),
+		})
+		assert.Nil(t, err)
+	}
+
+	t.Run("get and update", func(t *testing.T) {
+		file, err := store.GetFile(userId, prefixes[0])
+		assert.Nil(t, err)
+		assert.Equal(t, "File", file.Title)
+
+		file.Status = views.StatusQuarantined
+		assert.Nil(t, store.UpdateFile(file))
+
+		file, err = store.GetFile(userId, prefixes[0])
+		assert.Nil(t, err)
+		assert.Equal(t, views.StatusQuarantined, file.Status)
+
+		_, err = store.GetFile(userId, userId+"/missing")
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		err = store.UpdateFile(&views.DynamoDBUploadSchema{UserId: userId, Prefix: userId + "/missing"})
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("update if version", func(t *testing.T) {
+		file, err := store.GetFile(userId, prefixes[1])
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), file.Version)
+
+		file.Title = "Renamed"
+		assert.Nil(t, store.UpdateFileIfVersion(file, 0))
+		assert.Equal(t, int64(1), file.Version)
+
+		stale := *file
+		stale.Title = "Stale"
+		err = store.UpdateFileIfVersion(&stale, 0)
+		assert.ErrorIs(t, err, ErrVersionConflict)
+
+		file, err = store.GetFile(userId, prefixes[1])
+		assert.Nil(t, err)
+		assert.Equal(t, "Renamed", file.Title)
+		assert.Equal(t, int64(1), file.Version)
+
+		err = store.UpdateFileIfVersion(&views.DynamoDBUploadSchema{UserId: userId, Prefix: userId + "/missing"}, 0)
+		assert.ErrorIs(t, err, ErrVersionConflict)
+	})
+
+	t.Run("update func", func(t *testing.T) {
+		attempts := 0
+		file, err := UpdateFileFunc(store, userId, prefixes[1], func(file *views.DynamoDBUploadSchema) error {
+			attempts++
+			if attempts == 1 {
+				// A concurrent change, made after the file was read.
+				concurrent := *file
+				concurrent.Title = "Concurrent"
+				assert.Nil(t, store.UpdateFileIfVersion(&concurrent, concurrent.Version))
+			}
+
+			file.Status = views.StatusTrashed
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, attempts)
+		assert.Equal(t, int64(3), file.Version)
+
+		file, err = store.GetFile(userId, prefixes[1])
+		assert.Nil(t, err)
+		assert.Equal(t, "Concurrent", file.Title)
+		assert.Equal(t, views.StatusTrashed, file.Status)
+		assert.Equal(t, int64(3), file.Version)
+
+		_, err = UpdateFileFunc(store, userId, userId+"/missing", func(file *views.DynamoDBUploadSchema) error {
+			return nil
+		})
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("list pages", func(t *testing.T) {
+		files, cursor, err := store.ListFiles(userId, 2, "", true)
+		assert.Nil(t, err)
+		assert.Len(t, files, 2)
+		assert.NotEmpty(t, cursor)
+
+		files, cursor, err = store.ListFiles(userId, 2, cursor, true)
+		assert.Nil(t, err)
+		assert.Len(t, files, 1)
+		assert.Equal(t, prefixes[2], files[0].Prefix)
+		assert.Empty(t, cursor)
+
+		_, _, err = store.ListFiles(userId, 2, "%", true)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("list pages backward", func(t *testing.T) {
+		files, cursor, err := store.ListFiles(userId, 2, "", false)
+		assert.Nil(t, err)
+		assert.Len(t, files, 2)
+		assert.Equal(t, prefixes[2], files[0].Prefix)
+		assert.Equal(t, prefixes[1], files[1].Prefix)
+
+		files, cursor, err = store.ListFiles(userId, 2, cursor, false)
+		assert.Nil(t, err)
+		assert.Len(t, files, 1)
+		assert.Equal(t, prefixes[0], files[0].Prefix)
+		assert.Empty(t, cursor)
+	})
+
+	t.Run("list by status", func(t *testing.T) {
+		files, err := store.ListFilesByStatus(userId, views.StatusQuarantined)
+		assert.Nil(t, err)
+		assert.Len(t, files, 1)
+		assert.Equal(t, prefixes[0], files[0].Prefix)
+	})
+
+	t.Run("query index", func(t *testing.T) {
+		items := []*views.DynamoDBLabelIndexSchema{
+			{UserId: userId, LabelKey: views.LabelIndexKey("Dog", prefixes[0]), Label: "Dog", Prefix: prefixes[0]},
+			{UserId: userId, LabelKey: views.LabelIndexKey("Dog", prefixes[1]), Label: "Dog", Prefix: prefixes[1]},
+			{UserId: userId, LabelKey: views.LabelIndexKey("Cat", prefixes[2]), Label: "Cat", Prefix: prefixes[2]},
+		}
+		assert.Nil(t, store.PutIndex(items))
+
+		found, cursor, err := store.QueryIndex(userId, views.LabelIndexKey("dog", ""), 1, "")
+		assert.Nil(t, err)
+		assert.Len(t, found, 1)
+		assert.Equal(t, prefixes[0], found[0].Prefix)
+
+		found, cursor, err = store.QueryIndex(userId, views.LabelIndexKey("dog", ""), 1, cursor)
+		assert.Nil(t, err)
+		assert.Len(t, found, 1)
+		assert.Equal(t, prefixes[1], found[0].Prefix)
+		assert.Empty(t, cursor)
+
+		assert.Nil(t, store.DeleteIndex(items[:1]))
+
+		found, _, err = store.QueryIndex(userId, views.LabelIndexKey("dog", ""), 10, "")
+		assert.Nil(t, err)
+		assert.Len(t, found, 1)
+	})
+
+	t.Run("folders", func(t *testing.T) {
+		folders := []*views.DynamoDBFolderSchema{
+			{UserId: userId, FolderId: uuid.NewString(), Name: "Trips"},
+			{UserId: userId, FolderId: uuid.NewString(), Name: "Docs"},
+		}
+		for _, folder := range folders {
+			assert.Nil(t, store.PutFolder(folder))
+		}
+
+		found, err := store.ListFolders(userId)
+		assert.Nil(t, err)
+		assert.Len(t, found, 2)
+		assert.Equal(t, "Docs", found[0].Name)
+
+		folder, err := store.GetFolder(userId, folders[0].FolderId)
+		assert.Nil(t, err)
+		assert.Equal(t, "Trips", folder.Name)
+
+		file, err := store.GetFile(userId, prefixes[2])
+		assert.Nil(t, err)
+		file.FolderId = folder.FolderId
+		assert.Nil(t, store.UpdateFile(file))
+
+		files, err := store.ListFolderFiles(userId, folder.FolderId)
+		assert.Nil(t, err)
+		assert.Len(t, files, 1)
+		assert.Equal(t, prefixes[2], files[0].Prefix)
+
+		files, err = store.ListFolderFiles(userId, "")
+		assert.Nil(t, err)
+		assert.Len(t, files, 2)
+
+		assert.Nil(t, store.DeleteFolder(userId, folder.FolderId))
+
+		_, err = store.GetFolder(userId, folder.FolderId)
+		assert.ErrorIs(t, err, ErrFolderNotFound)
+	})
+
+	t.Run("api keys", func(t *testing.T) {
+		tenantId := uuid.NewString()
+		createdOn := time.Now().UTC().Truncate(time.Second)
+
+		for i, keyId := range []string{uuid.NewString(), uuid.NewString()} {
+			err := store.PutApiKey(&views.DynamoDBApiKeySchema{
+				KeyId:     keyId,
+				KeyHash:   "hash",
+				Name:      "service",
+				TenantId:  tenantId,
+				Scopes:    []string{"read"},
+				CreatedOn: createdOn.Add(time.Duration(i) * time.Second),
+			})
+			assert.Nil(t, err)
+		}
+
+		apiKeys, err := store.ListApiKeys(tenantId)
+		assert.Nil(t, err)
+		assert.Len(t, apiKeys, 2)
+		assert.True(t, apiKeys[0].CreatedOn.Before(apiKeys[1].CreatedOn))
+
+		lastUsedOn := createdOn.Add(time.Minute)
+		assert.Nil(t, store.SetApiKeyLastUsed(apiKeys[0].KeyId, lastUsedOn))
+
+		apiKey, err := store.GetApiKey(apiKeys[0].KeyId)
+		assert.Nil(t, err)
+		assert.True(t, lastUsedOn.Equal(apiKey.LastUsedOn))
+		assert.Equal(t, []string{"read"}, apiKey.Scopes)
+
+		_, err = store.GetApiKey(uuid.NewString())
+		assert.ErrorIs(t, err, ErrApiKeyNotFound)
+
+		err = store.SetApiKeyLastUsed(uuid.NewString(), lastUsedOn)
+		assert.ErrorIs(t, err, ErrApiKeyNotFound)
+	})
+
+	t.Run("shares", func(t *testing.T) {
+		tokenHash := uuid.NewString()
+		err := store.PutShare(&views.DynamoDBShareSchema{
+			TokenHash:    tokenHash,
+			TenantId:     "default",
+			UserId:       userId,
+			Prefix:       prefixes[0],
+			Definitions:  []utils.FileDefinitions{utils.MediumDef},
+			MaxDownloads: 2,
+			CreatedOn:    time.Now().UTC().Truncate(time.Second),
+		})
+		assert.Nil(t, err)
+
+		assert.Nil(t, store.IncrementShareDownloads(tokenHash))
+		assert.Nil(t, store.IncrementShareDownloads(tokenHash))
+		assert.ErrorIs(t, store.IncrementShareDownloads(tokenHash), ErrShareLimitReached)
+
+		share, err := store.GetShare(tokenHash)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), share.Downloads)
+		assert.Equal(t, []utils.FileDefinitions{utils.MediumDef}, share.Definitions)
+
+		_, err = store.GetShare(uuid.NewString())
+		assert.ErrorIs(t, err, ErrShareNotFound)
+
+		err = store.IncrementShareDownloads(uuid.NewString())
+		assert.ErrorIs(t, err, ErrShareNotFound)
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		assert.Nil(t, store.AddUsage(&views.DynamoDBUsageSchema{UserId: userId, Strategy: "image", Bytes: 100, Files: 1}))
+		assert.Nil(t, store.AddUsage(&views.DynamoDBUsageSchema{UserId: userId, Strategy: "image", Bytes: -40, Files: 1}))
+		assert.Nil(t, store.AddUsage(&views.DynamoDBUsageSchema{UserId: userId, Strategy: "file", Bytes: 10, Files: 1}))
+
+		usage, err := store.ListUsage(userId)
+		assert.Nil(t, err)
+		assert.Equal(t, []*views.DynamoDBUsageSchema{
+			{UserId: userId, Strategy: "file", Bytes: 10, Files: 1},
+			{UserId: userId, Strategy: "image", Bytes: 60, Files: 2},
+		}, usage)
+
+		usage, err = store.ListUsage(uuid.NewString())
+		assert.Nil(t, err)
+		assert.Empty(t, usage)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		assert.Nil(t, store.DeleteFile(userId, prefixes[0]))
+
+		_, err := store.GetFile(userId, prefixes[0])
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		assert.Nil(t, store.DeleteUserFiles(userId))
+
+		files, _, err := store.ListFiles(userId, 10, "", true)
+		assert.Nil(t, err)
+		assert.Empty(t, files)
+
+		found, _, err := store.QueryIndex(userId, "", 10, "")
+		assert.Nil(t, err)
+		assert.Empty(t, found)
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	testMetadataStore(t, NewMemoryStore())
+}
+
+// The PostgreSQL tests run only when POSTGRES_TEST_DSN is set.
+func TestPostgresStore(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN is not set")
+	}
+
+	store, err := NewPostgresStore(dsn)
+	assert.Nil(t, err)
+	defer store.Close()
+
+	testMetadataStore(t, store)
+}
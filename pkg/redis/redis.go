@@ -34,9 +34,20 @@ func NewRedisRepository(redisConfig *config.RedisConfig) *RedisRepository {
 	}
 }
 
+// WithNamespace returns a repository sharing the client, with the keys prefixed by the namespace.
+func (r *RedisRepository) WithNamespace(namespace string) *RedisRepository {
+	if r == nil {
+		return nil
+	}
+
+	return &RedisRepository{
+		Client:     r.Client,
+		prefix_key: fmt.Sprintf("%s:%s", r.prefix_key, namespace),
+	}
+}
+
 func (r *RedisRepository) getKey(key *string) {
-	fmt_key := fmt.Sprintf("%s:%s", r.prefix_key, *key)
-	key = &fmt_key
+	*key = fmt.Sprintf("%s:%s", r.prefix_key, *key)
 }
 
 func (r *RedisRepository) GetAny(ctx context.Context, key string) ([]byte, error) {
@@ -53,10 +64,12 @@ func (r *RedisRepository) SetAny(ctx context.Context, key string, value []byte,
 }
 
 func (r *RedisRepository) Del(ctx context.Context, key ...string) {
-	for _, k := range key {
+	keys := make([]string, len(key))
+	for i, k := range key {
 		r.getKey(&k)
+		keys[i] = k
 	}
-	r.Client.Del(ctx, key...)
+	r.Client.Del(ctx, keys...)
 }
 
 func (r *RedisRepository) Exists(ctx context.Context, key string) bool {
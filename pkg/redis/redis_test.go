@@ -0,0 +1,21 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gearpoint/filepoint/config"
+	"github.com/gearpoint/filepoint/pkg/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelKeepsKeys(t *testing.T) {
+	// The deletion fails without a server, but the keys must be prefixed in a copy.
+	redisRepository := redis.NewRedisRepository(&config.RedisConfig{Addr: "127.0.0.1:1"})
+	defer redisRepository.Client.Close()
+
+	keys := []string{"first", "second"}
+	redisRepository.Del(context.Background(), keys...)
+
+	assert.Equal(t, []string{"first", "second"}, keys)
+}
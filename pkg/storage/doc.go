@@ -0,0 +1,3 @@
+// Package storage contains the blob storage abstraction and the local filesystem implementation.
+// The S3 implementation is in the aws_repository package.
+package storage
@@ -0,0 +1,456 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gearpoint/filepoint/internal/views"
+)
+
+const (
+	// The sidecars folder, inside the root dir. It's not listed as objects.
+	sidecarDir = ".sidecars"
+
+	// The temp files pattern, used in atomic writes.
+	tempFilePattern = ".tmp-*"
+)
+
+// sidecar contains the object attributes and tags, stored next to the object.
+type sidecar struct {
+	ContentType string            `json:"contentType"`
+	Metadata    map[string]string `json:"metadata"`
+	Tags        map[string]string `json:"tags"`
+}
+
+// FileSystemStore stores the objects in a local directory.
+// The object prefixes are the relative file paths.
+type FileSystemStore struct {
+	rootDir string
+	signer  URLSigner
+	mu      sync.Mutex
+}
+
+// NewFileSystemStore returns a FileSystemStore instance, creating the root dir if needed.
+// The objects are only served by the filepoint download handler, so the signer is usually a HMACSigner.
+func NewFileSystemStore(rootDir string, signer URLSigner) (*FileSystemStore, error) {
+	err := os.MkdirAll(filepath.Join(rootDir, sidecarDir), 0750)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSystemStore{
+		rootDir: rootDir,
+		signer:  signer,
+	}, nil
+}
+
+// Signer returns the store URL signer.
+func (s *FileSystemStore) Signer() URLSigner {
+	return s.signer
+}
+
+// PutObject puts a new object in the given prefix.
+func (s *FileSystemStore) PutObject(prefix string, file io.Reader, contentType string, metadata map[string]string, tagging *string) error {
+	objectPath, sidecarPath, err := s.paths(prefix)
+	if err != nil {
+		return err
+	}
+
+	tags, err := ParseTagging(tagging)
+	if err != nil {
+		return err
+	}
+
+	err = writeFile(objectPath, file)
+	if err != nil {
+		return err
+	}
+
+	return s.writeSidecar(sidecarPath, &sidecar{
+		ContentType: contentType,
+		Metadata:    metadata,
+		Tags:        tags,
+	})
+}
+
+// UploadChunks puts a new object in the given prefix.
+// The file is streamed to disk, so it's the same as PutObject.
+func (s *FileSystemStore) UploadChunks(prefix string, file io.Reader, contentType string, metadata map[string]string, tagging *string) error {
+	return s.PutObject(prefix, file, contentType, metadata, tagging)
+}
+
+// DownloadFile returns the object content.
+func (s *FileSystemStore) DownloadFile(prefix string) (io.ReadCloser, error) {
+	file, _, err := s.Open(prefix)
+
+	return file, err
+}
+
+// DownloadRange returns the object bytes from the offset. A negative length reads until the end.
+func (s *FileSystemStore) DownloadRange(prefix string, offset int64, length int64) (io.ReadCloser, error) {
+	file, _, err := s.Open(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if length < 0 {
+		return file, nil
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(file, length), file}, nil
+}
+
+// Open returns the object file and attributes.
+func (s *FileSystemStore) Open(prefix string) (*os.File, *ObjectInfo, error) {
+	objectPath, _, err := s.paths(prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(objectPath)
+	if err != nil {
+		return nil, nil, notFoundError(prefix, err)
+	}
+
+	info, err := s.HeadObject(prefix)
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	return file, info, nil
+}
+
+// HeadObject returns the object attributes.
+func (s *FileSystemStore) HeadObject(prefix string) (*ObjectInfo, error) {
+	objectPath, sidecarPath, err := s.paths(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(objectPath)
+	if err != nil {
+		return nil, notFoundError(prefix, err)
+	}
+	if stat.IsDir() {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, prefix)
+	}
+
+	attributes, err := s.readSidecar(sidecarPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ObjectInfo{
+		ContentType:   attributes.ContentType,
+		ContentLength: stat.Size(),
+		LastModified:  stat.ModTime(),
+		ETag:          fmt.Sprintf(`"%x-%x"`, stat.ModTime().UnixNano(), stat.Size()),
+		Metadata:      attributes.Metadata,
+	}, nil
+}
+
+// CopyObject copies the object and its tags, replacing the metadata when it isn't nil.
+func (s *FileSystemStore) CopyObject(srcPrefix string, dstPrefix string, metadata map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.HeadObject(srcPrefix); err != nil {
+		return err
+	}
+
+	srcPath, srcSidecarPath, err := s.paths(srcPrefix)
+	if err != nil {
+		return err
+	}
+
+	dstPath, dstSidecarPath, err := s.paths(dstPrefix)
+	if err != nil {
+		return err
+	}
+
+	attributes, err := s.readSidecar(srcSidecarPath)
+	if err != nil {
+		return err
+	}
+
+	if metadata != nil {
+		attributes.Metadata = metadata
+	}
+
+	if srcPath != dstPath {
+		file, err := os.Open(srcPath)
+		if err != nil {
+			return notFoundError(srcPrefix, err)
+		}
+		defer file.Close()
+
+		err = writeFile(dstPath, file)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.writeSidecar(dstSidecarPath, attributes)
+}
+
+// GetSignedObject returns a signed object from the given prefix.
+func (s *FileSystemStore) GetSignedObject(prefix string, policy SignPolicy) (*views.GetSignedURLResponse, error) {
+	return SignObject(s, prefix, policy)
+}
+
+// ListObjects lists all objects in the given prefix.
+func (s *FileSystemStore) ListObjects(prefix string) ([]string, error) {
+	response := []string{}
+
+	dir := prefix
+	if !strings.HasSuffix(prefix, "/") {
+		dir = path.Dir(prefix)
+	}
+
+	dirPath, _, err := s.paths(dir)
+	if err != nil {
+		return response, nil
+	}
+
+	err = filepath.WalkDir(dirPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		if entry.IsDir() {
+			if entry.Name() == sidecarDir && filepath.Dir(filePath) == filepath.Clean(s.rootDir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if isTemp, _ := filepath.Match(tempFilePattern, entry.Name()); isTemp {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(s.rootDir, filePath)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(relPath)
+		if strings.HasPrefix(key, prefix) {
+			response = append(response, key)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// DeleteObject deletes an object from the given prefix.
+// Deleting a missing object is not an error.
+func (s *FileSystemStore) DeleteObject(prefix string) error {
+	objectPath, sidecarPath, err := s.paths(prefix)
+	if err != nil {
+		return err
+	}
+
+	for _, filePath := range []string{objectPath, sidecarPath} {
+		err := os.Remove(filePath)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteMany deletes many objects.
+func (s *FileSystemStore) DeleteMany(prefixes []string) error {
+	var errs []error
+	for _, prefix := range prefixes {
+		errs = append(errs, s.DeleteObject(prefix))
+	}
+
+	return errors.Join(errs...)
+}
+
+// PutObjectTagging replaces the object tags.
+func (s *FileSystemStore) PutObjectTagging(prefix string, tagging map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.updateTags(prefix, func(map[string]string) map[string]string {
+		return tagging
+	})
+}
+
+// GetObjectTagging gets the object tags.
+func (s *FileSystemStore) GetObjectTagging(prefix string) (map[string]string, bool, error) {
+	_, sidecarPath, err := s.paths(prefix)
+	if err != nil {
+		return nil, false, err
+	}
+
+	attributes, err := s.readSidecar(sidecarPath)
+	if err != nil {
+		return nil, false, errors.New("error getting object tags")
+	}
+
+	tags := make(map[string]string)
+	for tagKey, tagValue := range attributes.Tags {
+		tags[tagKey] = tagValue
+	}
+	_, temporary := tags[TempFileRule]
+
+	return tags, temporary, nil
+}
+
+// AddObjectTags adds the tags to the object, keeping the existing ones.
+func (s *FileSystemStore) AddObjectTags(prefix string, tagging map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.updateTags(prefix, func(tags map[string]string) map[string]string {
+		for tagKey, tagValue := range tagging {
+			tags[tagKey] = tagValue
+		}
+		return tags
+	})
+}
+
+// RemoveObjectTags removes the tags from the object, keeping the other ones.
+func (s *FileSystemStore) RemoveObjectTags(prefix string, tagKeys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.updateTags(prefix, func(tags map[string]string) map[string]string {
+		for _, tagKey := range tagKeys {
+			delete(tags, tagKey)
+		}
+		return tags
+	})
+}
+
+// updateTags rewrites the object sidecar with the updated tags.
+func (s *FileSystemStore) updateTags(prefix string, update func(tags map[string]string) map[string]string) error {
+	if _, err := s.HeadObject(prefix); err != nil {
+		return err
+	}
+
+	_, sidecarPath, err := s.paths(prefix)
+	if err != nil {
+		return err
+	}
+
+	attributes, err := s.readSidecar(sidecarPath)
+	if err != nil {
+		return err
+	}
+
+	if attributes.Tags == nil {
+		attributes.Tags = make(map[string]string)
+	}
+	attributes.Tags = update(attributes.Tags)
+
+	return s.writeSidecar(sidecarPath, attributes)
+}
+
+// paths returns the object and sidecar file paths.
+// The prefix is cleaned, so it can't point outside the root dir.
+func (s *FileSystemStore) paths(prefix string) (string, string, error) {
+	cleanPrefix := strings.TrimPrefix(path.Clean("/"+prefix), "/")
+	if cleanPrefix == "" {
+		return filepath.Clean(s.rootDir), "", nil
+	}
+
+	if strings.SplitN(cleanPrefix, "/", 2)[0] == sidecarDir {
+		return "", "", fmt.Errorf("invalid prefix: %s", prefix)
+	}
+
+	objectPath := filepath.Join(s.rootDir, filepath.FromSlash(cleanPrefix))
+	sidecarPath := filepath.Join(s.rootDir, sidecarDir, filepath.FromSlash(cleanPrefix)+".json")
+
+	return objectPath, sidecarPath, nil
+}
+
+// readSidecar returns the object sidecar. Missing sidecars are empty.
+func (s *FileSystemStore) readSidecar(sidecarPath string) (*sidecar, error) {
+	attributes := &sidecar{}
+
+	content, err := os.ReadFile(sidecarPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return attributes, nil
+		}
+		return nil, err
+	}
+
+	err = json.Unmarshal(content, attributes)
+
+	return attributes, err
+}
+
+// writeSidecar writes the object sidecar.
+func (s *FileSystemStore) writeSidecar(sidecarPath string, attributes *sidecar) error {
+	content, err := json.Marshal(attributes)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(sidecarPath, strings.NewReader(string(content)))
+}
+
+// writeFile writes the file atomically, creating the parent dirs.
+func writeFile(filePath string, reader io.Reader) error {
+	err := os.MkdirAll(filepath.Dir(filePath), 0750)
+	if err != nil {
+		return err
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(filePath), tempFilePattern)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+
+	_, err = io.Copy(tempFile, reader)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile.Name(), filePath)
+}
+
+// notFoundError wraps the not exist errors with ErrNotFound.
+func notFoundError(prefix string, err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, prefix)
+	}
+
+	return err
+}
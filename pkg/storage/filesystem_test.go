@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStore(t *testing.T) *FileSystemStore {
+	store, err := NewFileSystemStore(t.TempDir(), NewHMACSigner("http://localhost/v1/files", "secret"))
+	assert.Nil(t, err)
+
+	return store
+}
+
+func TestFileSystemStorePutAndDownload(t *testing.T) {
+	store := newTestStore(t)
+
+	tagging := TempFileRule
+	err := store.PutObject("user/file/original.txt", strings.NewReader("content"), "text/plain", map[string]string{"title": "File"}, &tagging)
+	assert.Nil(t, err)
+
+	reader, err := store.DownloadFile("user/file/original.txt")
+	assert.Nil(t, err)
+	content, err := io.ReadAll(reader)
+	reader.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, "content", string(content))
+
+	info, err := store.HeadObject("user/file/original.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "text/plain", info.ContentType)
+	assert.Equal(t, int64(7), info.ContentLength)
+	assert.Equal(t, "File", info.Metadata["title"])
+
+	_, temporary, err := store.GetObjectTagging("user/file/original.txt")
+	assert.Nil(t, err)
+	assert.True(t, temporary)
+
+	_, err = store.DownloadFile("user/missing.txt")
+	assert.True(t, CheckIsNotFoundError(err))
+}
+
+func TestFileSystemStoreTags(t *testing.T) {
+	store := newTestStore(t)
+
+	err := store.PutObject("user/file/original.png", strings.NewReader("png"), "image/png", nil, nil)
+	assert.Nil(t, err)
+
+	err = store.AddObjectTags("user/file/original.png", map[string]string{QuarantineTag: "true"})
+	assert.Nil(t, err)
+
+	response, err := store.GetSignedObject("user/file/original.png", SignPolicy{})
+	assert.Nil(t, err)
+	assert.True(t, response.Quarantined)
+	assert.Empty(t, response.Url)
+
+	err = store.RemoveObjectTags("user/file/original.png", QuarantineTag)
+	assert.Nil(t, err)
+
+	response, err = store.GetSignedObject("user/file/original.png", SignPolicy{})
+	assert.Nil(t, err)
+	assert.False(t, response.Quarantined)
+	assert.True(t, strings.HasPrefix(response.Url, "http://localhost/v1/files/user/file/original.png?"))
+
+	err = store.AddObjectTags("user/file/original.png", map[string]string{TrashTag: "true"})
+	assert.Nil(t, err)
+
+	response, err = store.GetSignedObject("user/file/original.png", SignPolicy{})
+	assert.Nil(t, err)
+	assert.True(t, response.Trashed)
+	assert.Empty(t, response.Url)
+
+	err = store.AddObjectTags("user/missing.png", map[string]string{QuarantineTag: "true"})
+	assert.True(t, CheckIsNotFoundError(err))
+}
+
+func TestFileSystemStoreListAndDelete(t *testing.T) {
+	store := newTestStore(t)
+
+	for _, prefix := range []string{"user/a/original.txt", "user/b/original.txt", "other/c/original.txt"} {
+		err := store.PutObject(prefix, strings.NewReader(prefix), "text/plain", nil, nil)
+		assert.Nil(t, err)
+	}
+
+	prefixes, err := store.ListObjects("user/")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"user/a/original.txt", "user/b/original.txt"}, prefixes)
+
+	prefixes, err = store.ListObjects("missing/")
+	assert.Nil(t, err)
+	assert.Empty(t, prefixes)
+
+	err = store.DeleteMany([]string{"user/a/original.txt", "user/missing.txt"})
+	assert.Nil(t, err)
+
+	prefixes, err = store.ListObjects("")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"other/c/original.txt", "user/b/original.txt"}, prefixes)
+}
+
+func TestFileSystemStoreInvalidPrefix(t *testing.T) {
+	store := newTestStore(t)
+
+	err := store.PutObject("../outside.txt", strings.NewReader("content"), "text/plain", nil, nil)
+	assert.Nil(t, err)
+
+	prefixes, err := store.ListObjects("")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"outside.txt"}, prefixes)
+
+	err = store.PutObject(".sidecars/file.txt", strings.NewReader("content"), "text/plain", nil, nil)
+	assert.Error(t, err)
+}
+
+func TestFileSystemStoreCopy(t *testing.T) {
+	store := newTestStore(t)
+
+	tagging := QuarantineTag
+	err := store.PutObject("user/file/original.txt", strings.NewReader("content"), "text/plain", map[string]string{"title": "File"}, &tagging)
+	assert.Nil(t, err)
+
+	err = store.CopyObject("user/file/original.txt", "user/file/original.txt", map[string]string{"title": "Renamed"})
+	assert.Nil(t, err)
+
+	info, err := store.HeadObject("user/file/original.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "text/plain", info.ContentType)
+	assert.Equal(t, "Renamed", info.Metadata["title"])
+
+	err = store.CopyObject("user/file/original.txt", "user/copy/original.txt", nil)
+	assert.Nil(t, err)
+
+	reader, err := store.DownloadFile("user/copy/original.txt")
+	assert.Nil(t, err)
+	content, err := io.ReadAll(reader)
+	reader.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, "content", string(content))
+
+	info, err = store.HeadObject("user/copy/original.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "Renamed", info.Metadata["title"])
+
+	tags, _, err := store.GetObjectTagging("user/copy/original.txt")
+	assert.Nil(t, err)
+	assert.Contains(t, tags, QuarantineTag)
+
+	err = store.CopyObject("user/missing/original.txt", "user/copy/missing.txt", nil)
+	assert.True(t, CheckIsNotFoundError(err))
+}
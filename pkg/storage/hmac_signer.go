@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// The signed URL query params.
+	expiresParam   = "expires"
+	notBeforeParam = "notBefore"
+	ipRangeParam   = "ip"
+	filenameParam  = "filename"
+	signatureParam = "signature"
+
+	// The signed cookies names.
+	CookiePrefixName    = "Filepoint-Prefix"
+	CookieExpiresName   = "Filepoint-Expires"
+	CookieSignatureName = "Filepoint-Signature"
+)
+
+var (
+	// ErrInvalidSignature is returned when the URL signature doesn't match.
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrExpiredSignature is returned when the URL signature is expired.
+	ErrExpiredSignature = errors.New("expired signature")
+	// ErrNotYetValidSignature is returned before the URL signature start time.
+	ErrNotYetValidSignature = errors.New("signature not yet valid")
+	// ErrForbiddenIP is returned when the client IP is out of the URL signature IP range.
+	ErrForbiddenIP = errors.New("forbidden client IP")
+)
+
+// HMACSigner signs and verifies the filepoint download handler URLs.
+type HMACSigner struct {
+	baseURL string
+	key     []byte
+}
+
+var _ URLSigner = (*HMACSigner)(nil)
+
+// NewHMACSigner returns a HMACSigner instance.
+// The baseURL is the download handler public URL.
+func NewHMACSigner(baseURL string, key string) *HMACSigner {
+	return &HMACSigner{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		key:     []byte(key),
+	}
+}
+
+// Sign returns the signed URL of the prefix, valid until expires.
+func (s *HMACSigner) Sign(prefix string, expires time.Time) string {
+	return s.SignWithPolicy(prefix, expires, SignPolicy{})
+}
+
+// SignURL returns the signed URL of the prefix, valid until expires and restricted by the policy.
+func (s *HMACSigner) SignURL(prefix string, expires time.Time, policy SignPolicy) (string, error) {
+	return s.SignWithPolicy(prefix, expires, policy), nil
+}
+
+// SignWithPolicy returns the signed URL of the prefix, valid until expires
+// and restricted by the policy conditions.
+func (s *HMACSigner) SignWithPolicy(prefix string, expires time.Time, policy SignPolicy) string {
+	query := url.Values{}
+	query.Set(expiresParam, strconv.FormatInt(expires.Unix(), 10))
+	if !policy.NotBefore.IsZero() {
+		query.Set(notBeforeParam, strconv.FormatInt(policy.NotBefore.Unix(), 10))
+	}
+	if policy.IPRange != "" {
+		query.Set(ipRangeParam, policy.IPRange)
+	}
+	if policy.Filename != "" {
+		query.Set(filenameParam, policy.Filename)
+	}
+	query.Set(signatureParam, s.signature(prefix, query))
+
+	return fmt.Sprintf("%s/%s?%s", s.baseURL, prefix, query.Encode())
+}
+
+// Verify checks the prefix signed URL query params and the client IP.
+func (s *HMACSigner) Verify(prefix string, query url.Values, clientIP string) error {
+	expected := s.signature(prefix, query)
+	if !hmac.Equal([]byte(expected), []byte(query.Get(signatureParam))) {
+		return ErrInvalidSignature
+	}
+
+	expires, err := strconv.ParseInt(query.Get(expiresParam), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	now := time.Now().Unix()
+	if now > expires {
+		return ErrExpiredSignature
+	}
+
+	if query.Has(notBeforeParam) {
+		notBefore, err := strconv.ParseInt(query.Get(notBeforeParam), 10, 64)
+		if err != nil {
+			return ErrInvalidSignature
+		}
+
+		if now < notBefore {
+			return ErrNotYetValidSignature
+		}
+	}
+
+	if query.Has(ipRangeParam) {
+		_, ipNet, err := net.ParseCIDR(query.Get(ipRangeParam))
+		if err != nil {
+			return ErrInvalidSignature
+		}
+
+		ip := net.ParseIP(clientIP)
+		if ip == nil || !ipNet.Contains(ip) {
+			return ErrForbiddenIP
+		}
+	}
+
+	return nil
+}
+
+// Filename returns the signed URL forced attachment filename, if any.
+func (s *HMACSigner) Filename(query url.Values) string {
+	return query.Get(filenameParam)
+}
+
+// ChecksTags is true, as the download handler checks the objects tags.
+func (s *HMACSigner) ChecksTags() bool {
+	return true
+}
+
+// SignCookies returns the signed cookies of the folder prefix objects, valid until expires.
+func (s *HMACSigner) SignCookies(prefix string, expires time.Time, options CookieOptions) ([]*http.Cookie, error) {
+	prefix = strings.Trim(prefix, "/")
+	expiresAt := strconv.FormatInt(expires.Unix(), 10)
+
+	values := map[string]string{
+		CookiePrefixName:    prefix,
+		CookieExpiresName:   expiresAt,
+		CookieSignatureName: s.cookieSignature(prefix, expiresAt),
+	}
+
+	cookies := []*http.Cookie{}
+	for _, name := range []string{CookiePrefixName, CookieExpiresName, CookieSignatureName} {
+		cookies = append(cookies, &http.Cookie{
+			Name:     name,
+			Value:    url.QueryEscape(values[name]),
+			Path:     options.Path,
+			Domain:   options.Domain,
+			Secure:   options.Secure,
+			HttpOnly: true,
+			Expires:  expires,
+		})
+	}
+
+	return cookies, nil
+}
+
+// VerifyCookies checks if the request signed cookies grant access to the prefix.
+// The prefixes with relative or empty segments are rejected, as the stores clean them out of the folder.
+func (s *HMACSigner) VerifyCookies(prefix string, r *http.Request) error {
+	if path.Clean(prefix) != prefix {
+		return ErrInvalidSignature
+	}
+
+	values := map[string]string{}
+	for _, name := range []string{CookiePrefixName, CookieExpiresName, CookieSignatureName} {
+		cookie, err := r.Cookie(name)
+		if err != nil {
+			return ErrInvalidSignature
+		}
+
+		value, err := url.QueryUnescape(cookie.Value)
+		if err != nil {
+			return ErrInvalidSignature
+		}
+		values[name] = value
+	}
+
+	cookiePrefix := values[CookiePrefixName]
+	expected := s.cookieSignature(cookiePrefix, values[CookieExpiresName])
+	if !hmac.Equal([]byte(expected), []byte(values[CookieSignatureName])) {
+		return ErrInvalidSignature
+	}
+
+	if !strings.HasPrefix(prefix, cookiePrefix+"/") {
+		return ErrInvalidSignature
+	}
+
+	expires, err := strconv.ParseInt(values[CookieExpiresName], 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if time.Now().Unix() > expires {
+		return ErrExpiredSignature
+	}
+
+	return nil
+}
+
+// cookieSignature returns the HMAC-SHA256 of the folder prefix wildcard and expiration.
+func (s *HMACSigner) cookieSignature(prefix string, expiresAt string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(prefix + "/*\n" + expiresAt))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// signature returns the HMAC-SHA256 of the prefix and the signed query params.
+// The policy params are only signed when present, so the plain URLs signature is the prefix and expiration.
+func (s *HMACSigner) signature(prefix string, query url.Values) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(prefix + "\n" + query.Get(expiresParam)))
+
+	if query.Has(notBeforeParam) || query.Has(ipRangeParam) || query.Has(filenameParam) {
+		mac.Write([]byte("\n" + query.Get(notBeforeParam) + "\n" + query.Get(ipRangeParam) + "\n" + query.Get(filenameParam)))
+	}
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHMACSigner(t *testing.T) {
+	signer := NewHMACSigner("http://localhost/v1/files/", "secret")
+
+	signedURL, err := url.Parse(signer.Sign("user/file/original.png", time.Now().Add(time.Hour)))
+	assert.Nil(t, err)
+	assert.Equal(t, "/v1/files/user/file/original.png", signedURL.Path)
+
+	query := signedURL.Query()
+	assert.Nil(t, signer.Verify("user/file/original.png", query, ""))
+	assert.ErrorIs(t, signer.Verify("user/file/other.png", query, ""), ErrInvalidSignature)
+	assert.ErrorIs(t, NewHMACSigner("", "other").Verify("user/file/original.png", query, ""), ErrInvalidSignature)
+
+	query.Set(expiresParam, "1")
+	assert.ErrorIs(t, signer.Verify("user/file/original.png", query, ""), ErrInvalidSignature)
+
+	expiredURL, err := url.Parse(signer.Sign("user/file/original.png", time.Now().Add(-time.Minute)))
+	assert.Nil(t, err)
+	assert.ErrorIs(t, signer.Verify("user/file/original.png", expiredURL.Query(), ""), ErrExpiredSignature)
+}
+
+func TestHMACSignerPolicy(t *testing.T) {
+	signer := NewHMACSigner("http://localhost/v1/files/", "secret")
+
+	policy := SignPolicy{IPRange: "10.0.0.0/8", Filename: "report.pdf"}
+	signedURL, err := url.Parse(signer.SignWithPolicy("user/file/original.pdf", time.Now().Add(time.Hour), policy))
+	assert.Nil(t, err)
+
+	query := signedURL.Query()
+	assert.Nil(t, signer.Verify("user/file/original.pdf", query, "10.1.2.3"))
+	assert.ErrorIs(t, signer.Verify("user/file/original.pdf", query, "192.168.0.1"), ErrForbiddenIP)
+	assert.Equal(t, "report.pdf", signer.Filename(query))
+
+	query.Set(filenameParam, "other.pdf")
+	assert.ErrorIs(t, signer.Verify("user/file/original.pdf", query, "10.1.2.3"), ErrInvalidSignature)
+
+	query.Del(filenameParam)
+	query.Del(ipRangeParam)
+	assert.ErrorIs(t, signer.Verify("user/file/original.pdf", query, "192.168.0.1"), ErrInvalidSignature)
+
+	policy = SignPolicy{NotBefore: time.Now().Add(time.Minute)}
+	signedURL, err = url.Parse(signer.SignWithPolicy("user/file/original.pdf", time.Now().Add(time.Hour), policy))
+	assert.Nil(t, err)
+	assert.ErrorIs(t, signer.Verify("user/file/original.pdf", signedURL.Query(), ""), ErrNotYetValidSignature)
+}
+
+func TestHMACSignerCookies(t *testing.T) {
+	signer := NewHMACSigner("http://localhost/v1/files/", "secret")
+
+	cookies, err := signer.SignCookies("user/file", time.Now().Add(time.Hour), CookieOptions{Path: "/v1/files"})
+	assert.Nil(t, err)
+	assert.Len(t, cookies, 3)
+
+	req := httptest.NewRequest("GET", "/v1/files/user/file/original.png", nil)
+	for _, cookie := range cookies {
+		assert.Equal(t, "/v1/files", cookie.Path)
+		req.AddCookie(cookie)
+	}
+
+	assert.Nil(t, signer.VerifyCookies("user/file/original.png", req))
+	assert.Nil(t, signer.VerifyCookies("user/file/hls/segment0.ts", req))
+	assert.ErrorIs(t, signer.VerifyCookies("user/files/original.png", req), ErrInvalidSignature)
+	assert.ErrorIs(t, signer.VerifyCookies("user/other/original.png", req), ErrInvalidSignature)
+	assert.ErrorIs(t, signer.VerifyCookies("user/file/../other/original.png", req), ErrInvalidSignature)
+	assert.ErrorIs(t, signer.VerifyCookies("user/file/./original.png", req), ErrInvalidSignature)
+	assert.ErrorIs(t, signer.VerifyCookies("user/file//original.png", req), ErrInvalidSignature)
+	assert.ErrorIs(t, NewHMACSigner("", "other").VerifyCookies("user/file/original.png", req), ErrInvalidSignature)
+	assert.ErrorIs(t, signer.VerifyCookies("user/file/original.png", httptest.NewRequest("GET", "/", nil)), ErrInvalidSignature)
+
+	forged := httptest.NewRequest("GET", "/v1/files/user/other/original.png", nil)
+	for _, cookie := range cookies {
+		if cookie.Name == CookiePrefixName {
+			cookie.Value = "user"
+		}
+		forged.AddCookie(cookie)
+	}
+	assert.ErrorIs(t, signer.VerifyCookies("user/other/original.png", forged), ErrInvalidSignature)
+
+	expired := httptest.NewRequest("GET", "/v1/files/user/file/original.png", nil)
+	cookies, err = signer.SignCookies("user/file", time.Now().Add(-time.Minute), CookieOptions{})
+	assert.Nil(t, err)
+	for _, cookie := range cookies {
+		expired.AddCookie(cookie)
+	}
+	assert.ErrorIs(t, signer.VerifyCookies("user/file/original.png", expired), ErrExpiredSignature)
+}
+
+func TestSignPolicy(t *testing.T) {
+	assert.Equal(t, "", SignPolicy{}.Key())
+	assert.Equal(t, "", SignPolicy{ExpiresIn: SignExpiration}.Key())
+	assert.Equal(t, SignExpiration, SignPolicy{}.Expiration())
+	assert.NotEqual(t, SignPolicy{IPRange: "10.0.0.0/8"}.Key(), SignPolicy{IPRange: "10.0.0.0/16"}.Key())
+	assert.NotEqual(t, SignPolicy{ExpiresIn: time.Hour}.Key(), SignPolicy{ExpiresIn: time.Hour, Filename: "a.txt"}.Key())
+	assert.True(t, SignPolicy{IPRange: "10.0.0.0/8"}.IsCustom())
+	assert.False(t, SignPolicy{Filename: "a.txt"}.IsCustom())
+	assert.Equal(t, `attachment; filename="a b.txt"`, SignPolicy{Filename: "a b.txt"}.Disposition())
+
+	ipRange, err := ParseIPRange("10.1.2.3")
+	assert.Nil(t, err)
+	assert.Equal(t, "10.1.2.3/32", ipRange)
+
+	ipRange, err = ParseIPRange("10.1.2.3/8")
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.0/8", ipRange)
+
+	_, err = ParseIPRange("localhost")
+	assert.ErrorIs(t, err, ErrInvalidIPRange)
+}
+
+func TestParseTagging(t *testing.T) {
+	tagging := "temporary-file&quarantined=true"
+
+	tags, err := ParseTagging(&tagging)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"temporary-file": "", "quarantined": "true"}, tags)
+
+	tags, err = ParseTagging(nil)
+	assert.Nil(t, err)
+	assert.Empty(t, tags)
+}
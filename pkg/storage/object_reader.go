@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+// ObjectReader reads an object as an io.ReadSeeker, so it can be served with HTTP ranges.
+// The object is downloaded from the current offset on the first read after a seek.
+type ObjectReader struct {
+	blobStore BlobStore
+	prefix    string
+	size      int64
+	offset    int64
+	body      io.ReadCloser
+}
+
+// NewObjectReader returns a new ObjectReader instance. The size is the object content length.
+func NewObjectReader(blobStore BlobStore, prefix string, size int64) *ObjectReader {
+	return &ObjectReader{
+		blobStore: blobStore,
+		prefix:    prefix,
+		size:      size,
+	}
+}
+
+// Read reads the object from the current offset.
+func (r *ObjectReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+
+	if r.body == nil {
+		body, err := r.blobStore.DownloadRange(r.prefix, r.offset, -1)
+		if err != nil {
+			return 0, err
+		}
+		r.body = body
+	}
+
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+
+	return n, err
+}
+
+// Seek sets the offset of the next read. The current download is closed when the offset changes.
+func (r *ObjectReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	}
+
+	if offset < 0 {
+		return 0, errors.New("negative object offset")
+	}
+
+	if offset != r.offset {
+		r.Close()
+		r.offset = offset
+	}
+
+	return offset, nil
+}
+
+// Close closes the current download.
+func (r *ObjectReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+
+	err := r.body.Close()
+	r.body = nil
+
+	return err
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectReader(t *testing.T) {
+	store := newTestStore(t)
+
+	err := store.PutObject("user/file/original.txt", strings.NewReader("0123456789"), "text/plain", nil, nil)
+	assert.Nil(t, err)
+
+	reader := NewObjectReader(store, "user/file/original.txt", 10)
+	defer reader.Close()
+
+	size, err := reader.Seek(0, io.SeekEnd)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), size)
+
+	_, err = reader.Seek(3, io.SeekStart)
+	assert.Nil(t, err)
+	content := make([]byte, 4)
+	_, err = io.ReadFull(reader, content)
+	assert.Nil(t, err)
+	assert.Equal(t, "3456", string(content))
+
+	_, err = reader.Seek(-2, io.SeekCurrent)
+	assert.Nil(t, err)
+	rest, err := io.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "56789", string(rest))
+
+	_, err = reader.Seek(-1, io.SeekStart)
+	assert.Error(t, err)
+
+	body, err := store.DownloadRange("user/file/original.txt", 2, 3)
+	assert.Nil(t, err)
+	content, err = io.ReadAll(body)
+	body.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, "234", string(content))
+}
@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gearpoint/filepoint/internal/views"
+)
+
+// PrefixedStore is a blob store that keeps all the objects under a key prefix of the base store.
+// It's used to isolate the tenants sharing a bucket.
+type PrefixedStore struct {
+	base      BlobStore
+	keyPrefix string
+	signer    URLSigner
+}
+
+// NewPrefixedStore returns a blob store with the keys prefixed by keyPrefix.
+func NewPrefixedStore(base BlobStore, keyPrefix string) *PrefixedStore {
+	keyPrefix = strings.Trim(keyPrefix, "/")
+
+	return &PrefixedStore{
+		base:      base,
+		keyPrefix: keyPrefix,
+		signer:    &prefixedSigner{base: base.Signer(), keyPrefix: keyPrefix},
+	}
+}
+
+// KeyPrefix returns the store key prefix.
+func (s *PrefixedStore) KeyPrefix() string {
+	return s.keyPrefix
+}
+
+func (s *PrefixedStore) key(prefix string) string {
+	return joinKeyPrefix(s.keyPrefix, prefix)
+}
+
+// PutObject puts a new object in the given prefix.
+func (s *PrefixedStore) PutObject(prefix string, file io.Reader, contentType string, metadata map[string]string, tagging *string) error {
+	return s.base.PutObject(s.key(prefix), file, contentType, metadata, tagging)
+}
+
+// UploadChunks puts a new object in the given prefix.
+func (s *PrefixedStore) UploadChunks(prefix string, file io.Reader, contentType string, metadata map[string]string, tagging *string) error {
+	return s.base.UploadChunks(s.key(prefix), file, contentType, metadata, tagging)
+}
+
+// DownloadFile returns the object content.
+func (s *PrefixedStore) DownloadFile(prefix string) (io.ReadCloser, error) {
+	return s.base.DownloadFile(s.key(prefix))
+}
+
+// DownloadRange returns the object bytes from the offset.
+func (s *PrefixedStore) DownloadRange(prefix string, offset int64, length int64) (io.ReadCloser, error) {
+	return s.base.DownloadRange(s.key(prefix), offset, length)
+}
+
+// HeadObject returns the object attributes.
+func (s *PrefixedStore) HeadObject(prefix string) (*ObjectInfo, error) {
+	return s.base.HeadObject(s.key(prefix))
+}
+
+// CopyObject copies the object and its tags.
+func (s *PrefixedStore) CopyObject(srcPrefix string, dstPrefix string, metadata map[string]string) error {
+	return s.base.CopyObject(s.key(srcPrefix), s.key(dstPrefix), metadata)
+}
+
+// GetSignedObject returns the object signed URL.
+func (s *PrefixedStore) GetSignedObject(prefix string, policy SignPolicy) (*views.GetSignedURLResponse, error) {
+	return SignObject(s, prefix, policy)
+}
+
+// Signer returns the base store signer, with the keys prefixed.
+func (s *PrefixedStore) Signer() URLSigner {
+	return s.signer
+}
+
+// ListObjects returns the objects in the prefix, without the key prefix.
+func (s *PrefixedStore) ListObjects(prefix string) ([]string, error) {
+	listPrefix := s.keyPrefix + "/"
+	if prefix != "" {
+		listPrefix = s.key(prefix)
+	}
+
+	prefixes, err := s.base.ListObjects(listPrefix)
+	if err != nil {
+		return prefixes, err
+	}
+
+	response := []string{}
+	for _, key := range prefixes {
+		if object, found := strings.CutPrefix(key, s.keyPrefix+"/"); found {
+			response = append(response, object)
+		}
+	}
+
+	return response, nil
+}
+
+// DeleteObject deletes the object.
+func (s *PrefixedStore) DeleteObject(prefix string) error {
+	return s.base.DeleteObject(s.key(prefix))
+}
+
+// DeleteMany deletes the objects.
+func (s *PrefixedStore) DeleteMany(prefixes []string) error {
+	keys := make([]string, len(prefixes))
+	for i, prefix := range prefixes {
+		keys[i] = s.key(prefix)
+	}
+
+	return s.base.DeleteMany(keys)
+}
+
+// PutObjectTagging replaces the object tags.
+func (s *PrefixedStore) PutObjectTagging(prefix string, tagging map[string]string) error {
+	return s.base.PutObjectTagging(s.key(prefix), tagging)
+}
+
+// GetObjectTagging returns the object tags.
+func (s *PrefixedStore) GetObjectTagging(prefix string) (map[string]string, bool, error) {
+	return s.base.GetObjectTagging(s.key(prefix))
+}
+
+// AddObjectTags adds the tags to the object.
+func (s *PrefixedStore) AddObjectTags(prefix string, tagging map[string]string) error {
+	return s.base.AddObjectTags(s.key(prefix), tagging)
+}
+
+// RemoveObjectTags removes the tags from the object.
+func (s *PrefixedStore) RemoveObjectTags(prefix string, tagKeys ...string) error {
+	return s.base.RemoveObjectTags(s.key(prefix), tagKeys...)
+}
+
+// prefixedSigner signs the prefixed store keys with the base store signer.
+type prefixedSigner struct {
+	base      URLSigner
+	keyPrefix string
+}
+
+// SignURL signs the prefixed object URL.
+func (s *prefixedSigner) SignURL(prefix string, expires time.Time, policy SignPolicy) (string, error) {
+	return s.base.SignURL(joinKeyPrefix(s.keyPrefix, prefix), expires, policy)
+}
+
+// SignCookies signs the prefixed folder cookies.
+func (s *prefixedSigner) SignCookies(prefix string, expires time.Time, options CookieOptions) ([]*http.Cookie, error) {
+	return s.base.SignCookies(joinKeyPrefix(s.keyPrefix, prefix), expires, options)
+}
+
+// ChecksTags returns whether the base signer checks the objects tags.
+func (s *prefixedSigner) ChecksTags() bool {
+	return s.base.ChecksTags()
+}
+
+func joinKeyPrefix(keyPrefix string, prefix string) string {
+	return keyPrefix + "/" + prefix
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixedStore(t *testing.T) {
+	base := newTestStore(t)
+	store := NewPrefixedStore(base, "/acme/")
+
+	err := store.PutObject("user/a/original.txt", strings.NewReader("content"), "text/plain", nil, nil)
+	assert.Nil(t, err)
+	err = base.PutObject("user/b/original.txt", strings.NewReader("other"), "text/plain", nil, nil)
+	assert.Nil(t, err)
+
+	reader, err := base.DownloadFile("acme/user/a/original.txt")
+	assert.Nil(t, err)
+	content, err := io.ReadAll(reader)
+	reader.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, "content", string(content))
+
+	prefixes, err := store.ListObjects("")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"user/a/original.txt"}, prefixes)
+
+	prefixes, err = store.ListObjects("user/")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"user/a/original.txt"}, prefixes)
+
+	_, err = store.HeadObject("user/b/original.txt")
+	assert.True(t, CheckIsNotFoundError(err))
+
+	response, err := store.GetSignedObject("user/a/original.txt", SignPolicy{})
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(response.Url, "http://localhost/v1/files/acme/user/a/original.txt?"))
+
+	err = store.DeleteMany([]string{"user/a/original.txt"})
+	assert.Nil(t, err)
+
+	prefixes, err = store.ListObjects("")
+	assert.Nil(t, err)
+	assert.Empty(t, prefixes)
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// Min signed url expiration time, used when the bounds aren't configured.
+	MinSignExpiration = time.Minute
+
+	// Max signed url expiration time, used when the bounds aren't configured.
+	MaxSignExpiration = 7 * 24 * time.Hour
+)
+
+// ErrInvalidIPRange is returned when the policy IP range isn't an IP or CIDR.
+var ErrInvalidIPRange = errors.New("invalid IP range")
+
+// SignPolicy contains the signed URL restrictions.
+// The zero value signs the URL for the SignExpiration time.
+type SignPolicy struct {
+	// ExpiresIn is the signed URL lifetime. Zero uses SignExpiration.
+	ExpiresIn time.Duration
+	// NotBefore is the time when the URL becomes valid (date greater than).
+	NotBefore time.Time
+	// IPRange is the CIDR of the clients allowed to use the URL.
+	IPRange string
+	// Filename forces the attachment download with the given filename.
+	Filename string
+}
+
+// Expiration returns the signed URL lifetime.
+func (p SignPolicy) Expiration() time.Duration {
+	if p.ExpiresIn <= 0 {
+		return SignExpiration
+	}
+
+	return p.ExpiresIn
+}
+
+// IsCustom checks if the policy has conditions other than the expiration,
+// which aren't supported by the canned Cloudfront policies.
+func (p SignPolicy) IsCustom() bool {
+	return !p.NotBefore.IsZero() || p.IPRange != ""
+}
+
+// Disposition returns the forced response content disposition, if any.
+func (p SignPolicy) Disposition() string {
+	if p.Filename == "" {
+		return ""
+	}
+
+	return mime.FormatMediaType("attachment", map[string]string{"filename": p.Filename})
+}
+
+// Key returns the policy cache key. The default policy key is empty.
+func (p SignPolicy) Key() string {
+	if p.Expiration() == SignExpiration && !p.IsCustom() && p.Filename == "" {
+		return ""
+	}
+
+	var notBefore int64
+	if !p.NotBefore.IsZero() {
+		notBefore = p.NotBefore.Unix()
+	}
+
+	return fmt.Sprintf("%d|%d|%s|%s", int64(p.Expiration().Seconds()), notBefore, p.IPRange, p.Filename)
+}
+
+// ParseIPRange returns the normalized CIDR of the IP or CIDR.
+func ParseIPRange(ipRange string) (string, error) {
+	if !strings.Contains(ipRange, "/") {
+		ip := net.ParseIP(ipRange)
+		if ip == nil {
+			return "", ErrInvalidIPRange
+		}
+
+		bits := 128
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 32
+		}
+
+		return (&net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}).String(), nil
+	}
+
+	_, ipNet, err := net.ParseCIDR(ipRange)
+	if err != nil {
+		return "", ErrInvalidIPRange
+	}
+
+	return ipNet.String(), nil
+}
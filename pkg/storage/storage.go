@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/gearpoint/filepoint/internal/views"
+)
+
+// Backend defines the blob store implementation.
+type Backend string
+
+const (
+	// S3 stores the objects in AWS S3, signed with Cloudfront.
+	S3 Backend = "s3"
+	// FileSystem stores the objects in the local filesystem, signed with HMAC.
+	FileSystem Backend = "filesystem"
+)
+
+const (
+	// Temporary file rule. This rule must be configured at the defined bucket.
+	// You can set, for example, a 1 day exclusion policy.
+	TempFileRule = "temporary-file"
+
+	// Quarantine tag. Objects with this tag were flagged by the moderation policy and can't be signed.
+	QuarantineTag = "quarantined"
+
+	// Trash tag. Objects with this tag were deleted by the user and can't be signed.
+	// A lifecycle rule can be configured at the bucket to expire them after the trash retention period.
+	TrashTag = "trashed"
+
+	// Encrypted tag. Objects with this tag are encrypted with the file data key and can't be signed.
+	// They are downloaded and decrypted through the API.
+	EncryptedTag = "encrypted"
+
+	// Default signed url expiration time. The cache time will be based in this value also.
+	SignExpiration = 12 * time.Hour
+)
+
+// ErrNotFound is returned when the object doesn't exist.
+var ErrNotFound = errors.New("object not found")
+
+// BlobStore defines the object storage methods.
+type BlobStore interface {
+	// PutObject puts a new object in the given prefix. Do not use it for large files.
+	PutObject(prefix string, file io.Reader, contentType string, metadata map[string]string, tagging *string) error
+	// UploadChunks puts a new object in the given prefix, in parts.
+	UploadChunks(prefix string, file io.Reader, contentType string, metadata map[string]string, tagging *string) error
+	DownloadFile(prefix string) (io.ReadCloser, error)
+	// DownloadRange returns the object bytes from the offset. A negative length reads until the end.
+	DownloadRange(prefix string, offset int64, length int64) (io.ReadCloser, error)
+	HeadObject(prefix string) (*ObjectInfo, error)
+	// CopyObject copies the object and its tags. When metadata isn't nil, it replaces the object metadata.
+	// Copying an object to itself (copy-in-place) is used to update the metadata.
+	CopyObject(srcPrefix string, dstPrefix string, metadata map[string]string) error
+	// GetSignedObject returns the object signed URL, restricted by the policy.
+	GetSignedObject(prefix string, policy SignPolicy) (*views.GetSignedURLResponse, error)
+	// Signer returns the store URL signer.
+	Signer() URLSigner
+	ListObjects(prefix string) ([]string, error)
+	DeleteObject(prefix string) error
+	DeleteMany(prefixes []string) error
+	PutObjectTagging(prefix string, tagging map[string]string) error
+	GetObjectTagging(prefix string) (map[string]string, bool, error)
+	AddObjectTags(prefix string, tagging map[string]string) error
+	RemoveObjectTags(prefix string, tagKeys ...string) error
+}
+
+// CookieOptions contains the signed cookies attributes.
+type CookieOptions struct {
+	Domain string
+	Path   string
+	Secure bool
+}
+
+// ObjectInfo contains the object attributes.
+type ObjectInfo struct {
+	ContentType   string
+	ContentLength int64
+	LastModified  time.Time
+	// ETag is the quoted object entity tag, changed when the object content changes.
+	ETag     string
+	Metadata map[string]string
+}
+
+// CheckIsNotFoundError checks if the storage error is not found.
+func CheckIsNotFoundError(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// EncodeTagging encodes the tags as an object tagging query. It returns nil without tags.
+func EncodeTagging(tags map[string]string) *string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	values := url.Values{}
+	for key, value := range tags {
+		values.Set(key, value)
+	}
+
+	tagging := values.Encode()
+
+	return &tagging
+}
+
+// ParseTagging returns the tags from the URL encoded tagging (i.e. "key1=value1&key2").
+func ParseTagging(tagging *string) (map[string]string, error) {
+	tags := make(map[string]string)
+	if tagging == nil || *tagging == "" {
+		return tags, nil
+	}
+
+	values, err := url.ParseQuery(*tagging)
+	if err != nil {
+		return nil, err
+	}
+
+	for key := range values {
+		tags[key] = values.Get(key)
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gearpoint/filepoint/internal/views"
+)
+
+// SignerBackend defines the URL signer implementation.
+type SignerBackend string
+
+const (
+	// Cloudfront signs the Cloudfront distribution URLs, with canned or custom policies.
+	Cloudfront SignerBackend = "cloudfront"
+	// S3Presign presigns the S3 GET requests, without CDN.
+	S3Presign SignerBackend = "s3"
+	// HMAC signs tokens verified by the filepoint download handler.
+	HMAC SignerBackend = "hmac"
+)
+
+// ErrUnsupportedPolicy is returned when the signer doesn't support the sign policy conditions.
+var ErrUnsupportedPolicy = errors.New("the signer doesn't support the policy")
+
+// URLSigner signs the objects URLs and the folders cookies.
+// The blob stores check the objects tags before signing.
+type URLSigner interface {
+	// SignURL returns the object signed URL, valid until expires and restricted by the policy.
+	SignURL(prefix string, expires time.Time, policy SignPolicy) (string, error)
+	// SignCookies returns the cookies that grant access to all the objects in the folder prefix until expires.
+	SignCookies(prefix string, expires time.Time, options CookieOptions) ([]*http.Cookie, error)
+	// ChecksTags returns whether the signed requests are served after checking the objects tags.
+	ChecksTags() bool
+}
+
+// SignObject returns the signed URL response of the object, with the blob store signer.
+// The temporary, quarantined, trashed and encrypted objects aren't signed.
+func SignObject(blobStore BlobStore, prefix string, policy SignPolicy) (*views.GetSignedURLResponse, error) {
+	info, err := blobStore.HeadObject(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	tagging, temp, err := blobStore.GetObjectTagging(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	_, quarantined := tagging[QuarantineTag]
+	_, trashed := tagging[TrashTag]
+	_, encrypted := tagging[EncryptedTag]
+	if quarantined || trashed || encrypted {
+		return &views.GetSignedURLResponse{
+			Metadata:    info.Metadata,
+			Tagging:     tagging,
+			Temporary:   temp,
+			Quarantined: quarantined,
+			Trashed:     trashed,
+			Encrypted:   encrypted,
+		}, nil
+	}
+
+	expires := time.Now().Add(policy.Expiration())
+
+	url, err := blobStore.Signer().SignURL(prefix, expires, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &views.GetSignedURLResponse{
+		Url:       url,
+		Metadata:  info.Metadata,
+		Tagging:   tagging,
+		Expires:   expires,
+		Temporary: temp,
+	}, nil
+}
@@ -16,6 +16,15 @@ const (
 
 	// The CloudfrontKeyId defines the key that contains the Cloudfront key ID.
 	CloudfrontKeyId string = "AWS_CLOUDFRONT_KEY_ID"
+
+	// The StorageSigningKey defines the key that contains the filesystem storage signing key.
+	StorageSigningKey string = "STORAGE_SIGNING_KEY"
+
+	// The PostgresDSN defines the key that contains the PostgreSQL connection string.
+	PostgresDSN string = "POSTGRES_DSN"
+
+	// The JWTSecret defines the key that contains the HS256 tokens shared secret.
+	JWTSecret string = "JWT_SECRET"
 )
 
 // The EnvironmentType defines the app environment.
@@ -1,11 +1,44 @@
 package utils
 
 import (
+	"strings"
+	"unicode"
+
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
 
+// The min length of a searchable text term.
+const minTermLength = 2
+
 // Title returns a formatted string with the first letter in uppercase.
 func Title(str string) string {
 	return cases.Title(language.English, cases.NoLower).String(str)
 }
+
+// TextTerms returns the unique lowercase terms of the text, in order of appearance.
+// The terms are split in any character that is not a letter or a number.
+// The max parameter limits the number of terms, use 0 for no limit.
+func TextTerms(text string, max int) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	seen := map[string]bool{}
+	terms := []string{}
+	for _, field := range fields {
+		term := strings.ToLower(field)
+		if len([]rune(term)) < minTermLength || seen[term] {
+			continue
+		}
+
+		if max > 0 && len(terms) >= max {
+			break
+		}
+
+		seen[term] = true
+		terms = append(terms, term)
+	}
+
+	return terms
+}
@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTitle(t *testing.T) {
+	assert.Equal(t, "Filepoint Service", Title("filepoint service"))
+}
+
+func TestTextTerms(t *testing.T) {
+	text := "Invoice INV-2024-001, invoice number: 42 (a)"
+
+	assert.Equal(t, []string{"invoice", "inv", "2024", "001", "number", "42"}, TextTerms(text, 0))
+	assert.Equal(t, []string{"invoice", "inv"}, TextTerms(text, 2))
+	assert.Empty(t, TextTerms("", 0))
+}
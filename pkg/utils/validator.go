@@ -3,6 +3,7 @@ package utils
 import (
 	"context"
 	"fmt"
+	"regexp"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -11,12 +12,32 @@ type MaxFileSizeType string
 
 const MaxFileSizeKey MaxFileSizeType = "uploadMaxSize"
 
+const (
+	// MaxMetadataKeys is the max number of custom metadata keys in a file.
+	MaxMetadataKeys = 20
+	// MaxMetadataValueLength is the max length of a custom metadata value.
+	MaxMetadataValueLength = 256
+	// MaxMetadataSize is the max size in bytes of all the custom metadata keys and values.
+	MaxMetadataSize = 2048
+)
+
+// metadataKeyRegex defines the custom metadata keys charset.
+// Keys are lowercase, start with a letter and have at most 64 characters.
+var metadataKeyRegex = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,63}$`)
+
 // Validate is the validator instance.
 var Validate *validator.Validate
 
 func init() {
 	Validate = validator.New()
 	Validate.RegisterValidationCtx("max-file-size", validateMaxFileSize, true)
+	Validate.RegisterValidation("metadata-key", validateMetadataKey)
+	Validate.RegisterValidation("metadata-size", validateMetadataSize)
+	Validate.RegisterAlias("custom-metadata", fmt.Sprintf(
+		"omitempty,max=%d,metadata-size,dive,keys,metadata-key,endkeys,max=%d",
+		MaxMetadataKeys,
+		MaxMetadataValueLength,
+	))
 }
 
 // validateMaxFileSize validates the upload max file size.
@@ -26,6 +47,26 @@ func validateMaxFileSize(ctx context.Context, fl validator.FieldLevel) bool {
 	return fl.Field().Int() <= uploadMaxSize
 }
 
+// validateMetadataKey validates the custom metadata key charset.
+func validateMetadataKey(fl validator.FieldLevel) bool {
+	return metadataKeyRegex.MatchString(fl.Field().String())
+}
+
+// validateMetadataSize validates the custom metadata total size.
+func validateMetadataSize(fl validator.FieldLevel) bool {
+	metadata, ok := fl.Field().Interface().(map[string]string)
+	if !ok {
+		return false
+	}
+
+	size := 0
+	for key, value := range metadata {
+		size += len(key) + len(value)
+	}
+
+	return size <= MaxMetadataSize
+}
+
 // FormatValidatorErrors formats the validator.ValidationErrors to a string.
 func FormatValidatorErrors(err error) []string {
 	if err == nil {
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -21,3 +23,29 @@ func TestFormatValidatorErrors(t *testing.T) {
 	fmtErr := FormatValidatorErrors(err)
 	assert.IsType(t, []string{""}, fmtErr)
 }
+
+func TestValidateCustomMetadata(t *testing.T) {
+	valid := map[string]string{"project": "filepoint", "page_count": "3"}
+	assert.Nil(t, Validate.Var(valid, "custom-metadata"))
+	assert.Nil(t, Validate.Var(map[string]string(nil), "custom-metadata"))
+
+	invalidKeys := []string{"", "Project", "1st", "with space", strings.Repeat("k", 65)}
+	for _, key := range invalidKeys {
+		assert.Error(t, Validate.Var(map[string]string{key: "value"}, "custom-metadata"), key)
+	}
+
+	longValue := map[string]string{"key": strings.Repeat("v", MaxMetadataValueLength+1)}
+	assert.Error(t, Validate.Var(longValue, "custom-metadata"))
+
+	tooMany := map[string]string{}
+	for i := 0; i <= MaxMetadataKeys; i++ {
+		tooMany[fmt.Sprintf("key%d", i)] = "value"
+	}
+	assert.Error(t, Validate.Var(tooMany, "custom-metadata"))
+
+	tooLarge := map[string]string{}
+	for i := 0; i < 10; i++ {
+		tooLarge[fmt.Sprintf("key%d", i)] = strings.Repeat("v", MaxMetadataValueLength)
+	}
+	assert.Error(t, Validate.Var(tooLarge, "custom-metadata"))
+}
@@ -14,3 +14,49 @@ awslocal dynamodb create-table \
           AttributeName=userId,AttributeType=S \
           AttributeName=prefix,AttributeType=S \
      --provisioned-throughput ReadCapacityUnits=5,WriteCapacityUnits=5
+
+awslocal dynamodb create-table \
+     --table-name filepoint_upload_index \
+     --key-schema \
+          AttributeName=userId,KeyType=HASH \
+          AttributeName=labelKey,KeyType=RANGE \
+     --attribute-definitions \
+          AttributeName=userId,AttributeType=S \
+          AttributeName=labelKey,AttributeType=S \
+     --provisioned-throughput ReadCapacityUnits=5,WriteCapacityUnits=5
+
+awslocal dynamodb create-table \
+     --table-name filepoint_upload_folders \
+     --key-schema \
+          AttributeName=userId,KeyType=HASH \
+          AttributeName=folderId,KeyType=RANGE \
+     --attribute-definitions \
+          AttributeName=userId,AttributeType=S \
+          AttributeName=folderId,AttributeType=S \
+     --provisioned-throughput ReadCapacityUnits=5,WriteCapacityUnits=5
+
+awslocal dynamodb create-table \
+     --table-name filepoint_api_keys \
+     --key-schema \
+          AttributeName=keyId,KeyType=HASH \
+     --attribute-definitions \
+          AttributeName=keyId,AttributeType=S \
+     --provisioned-throughput ReadCapacityUnits=5,WriteCapacityUnits=5
+
+awslocal dynamodb create-table \
+     --table-name filepoint_shares \
+     --key-schema \
+          AttributeName=tokenHash,KeyType=HASH \
+     --attribute-definitions \
+          AttributeName=tokenHash,AttributeType=S \
+     --provisioned-throughput ReadCapacityUnits=5,WriteCapacityUnits=5
+
+awslocal dynamodb create-table \
+     --table-name filepoint_usage \
+     --key-schema \
+          AttributeName=userId,KeyType=HASH \
+          AttributeName=strategy,KeyType=RANGE \
+     --attribute-definitions \
+          AttributeName=userId,AttributeType=S \
+          AttributeName=strategy,AttributeType=S \
+     --provisioned-throughput ReadCapacityUnits=5,WriteCapacityUnits=5